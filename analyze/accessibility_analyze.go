@@ -0,0 +1,257 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// cssIdentifierRegex matches the ids that can be used as a CSS id selector without escaping.
+var cssIdentifierRegex = regexp.MustCompile(`^[A-Za-z][\w-]*$`)
+
+// AccessibilityRule is a single static WCAG check. Check returns the violating elements of the document.
+type AccessibilityRule struct {
+	Id        string
+	Criterion string
+	Severity  string
+	Check     func(ctx *AccessibilityContext) []AccessibilityViolation
+}
+
+// AccessibilityViolation is an element failing a rule with the message describing the failure.
+type AccessibilityViolation struct {
+	Node    *html.Node
+	Message string
+}
+
+// AccessibilityContext gives the rules the parsed document and the lookups shared between them.
+type AccessibilityContext struct {
+	Doc      *html.Node
+	ids      map[string]*html.Node
+	idCounts map[string]int
+	labels   map[string]string
+}
+
+var (
+	accessibilityRulesMu sync.RWMutex
+	accessibilityRules   []AccessibilityRule
+)
+
+// RegisterAccessibilityRule adds a rule to the ruleset run by the AccessibilityAnalyzer. A rule with the id of
+// an already registered rule replaces it.
+func RegisterAccessibilityRule(rule AccessibilityRule) {
+	accessibilityRulesMu.Lock()
+	defer accessibilityRulesMu.Unlock()
+	for i, registered := range accessibilityRules {
+		if registered.Id == rule.Id {
+			accessibilityRules[i] = rule
+			return
+		}
+	}
+	accessibilityRules = append(accessibilityRules, rule)
+}
+
+// AccessibilityRules returns the registered rules in registration order.
+func AccessibilityRules() []AccessibilityRule {
+	accessibilityRulesMu.RLock()
+	defer accessibilityRulesMu.RUnlock()
+	return append([]AccessibilityRule(nil), accessibilityRules...)
+}
+
+// AccessibilityAnalyzer implements the Analyzer interface for the static WCAG checks.
+type AccessibilityAnalyzer struct{}
+
+// NewAccessibilityAnalyzer creates a new AccessibilityAnalyzer.
+func NewAccessibilityAnalyzer() *AccessibilityAnalyzer {
+	return &AccessibilityAnalyzer{}
+}
+
+// Analyze runs every registered accessibility rule against the page.
+func (a *AccessibilityAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing accessibility function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("AccessibilityAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing accessibility",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	res.Accessibility = CheckAccessibility(doc, mapSourceLines(wc.Content, doc), AccessibilityRules())
+	return nil
+}
+
+// CheckAccessibility runs the rules against the document. lines maps the elements to their source line.
+func CheckAccessibility(doc *html.Node, lines map[*html.Node]int, rules []AccessibilityRule) *response.AccessibilityReport {
+	report := &response.AccessibilityReport{
+		Counts:       map[string]int{constant.SEVERITY_ERROR: 0, constant.SEVERITY_WARNING: 0, constant.SEVERITY_INFO: 0},
+		Criteria:     make(map[string]int),
+		RulesChecked: len(rules),
+	}
+	ctx := newAccessibilityContext(doc)
+
+	for _, rule := range rules {
+		for _, violation := range rule.Check(ctx) {
+			report.Findings = append(report.Findings, response.AccessibilityFinding{
+				Finding:   newFinding(rule.Id, rule.Severity, violation.Message),
+				Criterion: rule.Criterion,
+				Selector:  CssPath(violation.Node, ctx.idCounts),
+				Line:      lines[violation.Node],
+			})
+			report.Counts[rule.Severity]++
+			report.Criteria[rule.Criterion]++
+		}
+	}
+	return report
+}
+
+func newAccessibilityContext(doc *html.Node) *AccessibilityContext {
+	ctx := &AccessibilityContext{
+		Doc:      doc,
+		ids:      make(map[string]*html.Node),
+		idCounts: make(map[string]int),
+		labels:   collectLabels(doc),
+	}
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id := getAttr(n, "id"); id != constant.EMPTY {
+				ctx.idCounts[id]++
+				if _, exists := ctx.ids[id]; !exists {
+					ctx.ids[id] = n
+				}
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return ctx
+}
+
+// AccessibleName approximates the accessible name computation: aria-labelledby, aria-label, the associated
+// label of form controls, the element content including image alt text and finally the title attribute.
+func (ctx *AccessibilityContext) AccessibleName(n *html.Node) string {
+	var parts []string
+	for _, id := range strings.Fields(getAttr(n, "aria-labelledby")) {
+		if labelled, ok := ctx.ids[id]; ok {
+			parts = append(parts, contentText(labelled))
+		}
+	}
+	if name := normalizeSpace(strings.Join(parts, " ")); name != constant.EMPTY {
+		return name
+	}
+	if name := normalizeSpace(getAttr(n, "aria-label")); name != constant.EMPTY {
+		return name
+	}
+
+	switch n.Data {
+	case "input", "select", "textarea":
+		if name := fieldLabel(ctx.Doc, n, ctx.labels); name != constant.EMPTY {
+			return name
+		}
+		switch strings.ToLower(getAttr(n, "type")) {
+		case "submit", "reset", "button":
+			if name := normalizeSpace(getAttr(n, "value")); name != constant.EMPTY {
+				return name
+			}
+		case "image":
+			if name := normalizeSpace(getAttr(n, "alt")); name != constant.EMPTY {
+				return name
+			}
+		}
+	case "iframe", "frame":
+		// the fallback content of frames is not exposed, only the title names them
+	case "img", "area":
+		if name := normalizeSpace(getAttr(n, "alt")); name != constant.EMPTY {
+			return name
+		}
+	default:
+		if name := contentText(n); name != constant.EMPTY {
+			return name
+		}
+	}
+	return normalizeSpace(getAttr(n, "title"))
+}
+
+// ElementById returns the first element with the id, or nil.
+func (ctx *AccessibilityContext) ElementById(id string) *html.Node {
+	return ctx.ids[id]
+}
+
+// contentText returns the text of n including the alt and aria-label of the descendants, skipping hidden content.
+func contentText(n *html.Node) string {
+	var text strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			text.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if node.Data == "script" || node.Data == "style" || getAttr(node, "aria-hidden") == "true" || hasAttr(node, "hidden") {
+				return
+			}
+			if label := getAttr(node, "aria-label"); label != constant.EMPTY && node != n {
+				text.WriteString(" " + label + " ")
+				return
+			}
+			if node.Data == "img" || (node.Data == "input" && strings.EqualFold(getAttr(node, "type"), "image")) {
+				text.WriteString(" " + getAttr(node, "alt") + " ")
+			}
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return normalizeSpace(text.String())
+}
+
+// CssPath builds a CSS selector locating the element, anchored at the closest ancestor with a unique id. idCounts
+// holds the number of elements of the document carrying each id.
+func CssPath(n *html.Node, idCounts map[string]int) string {
+	var segments []string
+	for node := n; node != nil && node.Type == html.ElementNode; node = node.Parent {
+		if id := getAttr(node, "id"); cssIdentifierRegex.MatchString(id) && idCounts[id] == 1 {
+			segments = append(segments, node.Data+"#"+id)
+			break
+		}
+		segment := node.Data
+		index, total := 0, 0
+		if node.Parent != nil {
+			for sibling := node.Parent.FirstChild; sibling != nil; sibling = sibling.NextSibling {
+				if sibling.Type == html.ElementNode && sibling.Data == node.Data {
+					total++
+					if sibling == node {
+						index = total
+					}
+				}
+			}
+		}
+		if total > 1 {
+			segment += fmt.Sprintf(":nth-of-type(%d)", index)
+		}
+		segments = append(segments, segment)
+	}
+
+	for i, j := 0, len(segments)-1; i < j; i, j = i+1, j-1 {
+		segments[i], segments[j] = segments[j], segments[i]
+	}
+	return strings.Join(segments, " > ")
+}
@@ -0,0 +1,299 @@
+package analyze
+
+import (
+	"api/constant"
+	"fmt"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// ariaRoles lists the non abstract WAI-ARIA 1.2 and DPUB-ARIA roles.
+var ariaRoles = toSet(
+	"alert", "alertdialog", "application", "article", "banner", "blockquote", "button", "caption", "cell",
+	"checkbox", "code", "columnheader", "combobox", "complementary", "contentinfo", "definition", "deletion",
+	"dialog", "directory", "document", "emphasis", "feed", "figure", "form", "generic", "grid", "gridcell",
+	"group", "heading", "img", "insertion", "link", "list", "listbox", "listitem", "log", "main", "marquee",
+	"math", "menu", "menubar", "menuitem", "menuitemcheckbox", "menuitemradio", "meter", "navigation", "none",
+	"note", "option", "paragraph", "presentation", "progressbar", "radio", "radiogroup", "region", "row",
+	"rowgroup", "rowheader", "scrollbar", "search", "searchbox", "separator", "slider", "spinbutton", "status",
+	"strong", "subscript", "superscript", "switch", "tab", "table", "tablist", "tabpanel", "term", "textbox",
+	"time", "timer", "toolbar", "tooltip", "tree", "treegrid", "treeitem",
+	"doc-abstract", "doc-acknowledgments", "doc-afterword", "doc-appendix", "doc-backlink", "doc-biblioentry",
+	"doc-bibliography", "doc-biblioref", "doc-chapter", "doc-colophon", "doc-conclusion", "doc-cover",
+	"doc-credit", "doc-credits", "doc-dedication", "doc-endnote", "doc-endnotes", "doc-epigraph", "doc-epilogue",
+	"doc-errata", "doc-example", "doc-footnote", "doc-foreword", "doc-glossary", "doc-glossref", "doc-index",
+	"doc-introduction", "doc-noteref", "doc-notice", "doc-pagebreak", "doc-pagelist", "doc-part", "doc-preface",
+	"doc-prologue", "doc-pullquote", "doc-qna", "doc-subtitle", "doc-tip", "doc-toc",
+)
+
+// ariaAttributes lists the WAI-ARIA 1.2 states and properties.
+var ariaAttributes = toSet(
+	"aria-activedescendant", "aria-atomic", "aria-autocomplete", "aria-braillelabel", "aria-brailleroledescription",
+	"aria-busy", "aria-checked", "aria-colcount", "aria-colindex", "aria-colindextext", "aria-colspan",
+	"aria-controls", "aria-current", "aria-describedby", "aria-description", "aria-details", "aria-disabled",
+	"aria-dropeffect", "aria-errormessage", "aria-expanded", "aria-flowto", "aria-grabbed", "aria-haspopup",
+	"aria-hidden", "aria-invalid", "aria-keyshortcuts", "aria-label", "aria-labelledby", "aria-level", "aria-live",
+	"aria-modal", "aria-multiline", "aria-multiselectable", "aria-orientation", "aria-owns", "aria-placeholder",
+	"aria-posinset", "aria-pressed", "aria-readonly", "aria-relevant", "aria-required", "aria-roledescription",
+	"aria-rowcount", "aria-rowindex", "aria-rowindextext", "aria-rowspan", "aria-selected", "aria-setsize",
+	"aria-sort", "aria-valuemax", "aria-valuemin", "aria-valuenow", "aria-valuetext",
+)
+
+func init() {
+	for _, rule := range []AccessibilityRule{
+		{Id: "image-alt", Criterion: "1.1.1", Severity: constant.SEVERITY_ERROR, Check: checkImageAlt},
+		{Id: "input-label", Criterion: "1.3.1", Severity: constant.SEVERITY_ERROR, Check: checkInputLabel},
+		{Id: "button-name", Criterion: "4.1.2", Severity: constant.SEVERITY_ERROR, Check: checkButtonName},
+		{Id: "link-name", Criterion: "2.4.4", Severity: constant.SEVERITY_ERROR, Check: checkLinkName},
+		{Id: "link-empty", Criterion: "2.4.4", Severity: constant.SEVERITY_ERROR, Check: checkEmptyLink},
+		{Id: "html-lang", Criterion: "3.1.1", Severity: constant.SEVERITY_ERROR, Check: checkHtmlLang},
+		{Id: "duplicate-id", Criterion: "4.1.1", Severity: constant.SEVERITY_WARNING, Check: checkDuplicateId},
+		{Id: "aria-role-invalid", Criterion: "4.1.2", Severity: constant.SEVERITY_ERROR, Check: checkAriaRole},
+		{Id: "aria-attribute-invalid", Criterion: "4.1.2", Severity: constant.SEVERITY_ERROR, Check: checkAriaAttributes},
+		{Id: "tabindex-positive", Criterion: "2.4.3", Severity: constant.SEVERITY_WARNING, Check: checkPositiveTabindex},
+		{Id: "frame-title", Criterion: "4.1.2", Severity: constant.SEVERITY_ERROR, Check: checkFrameTitle},
+		{Id: "table-headers", Criterion: "1.3.1", Severity: constant.SEVERITY_WARNING, Check: checkTableHeaders},
+	} {
+		RegisterAccessibilityRule(rule)
+	}
+}
+
+// checkImageAlt flags images without an alt attribute. An empty alt marks a decorative image and is allowed.
+func checkImageAlt(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, node := range findElements(ctx.Doc, "img", "area", "input") {
+		if node.Data == "input" && !strings.EqualFold(getAttr(node, "type"), "image") {
+			continue
+		}
+		if node.Data == "area" && !hasAttr(node, constant.H_REF) {
+			continue
+		}
+		if hasAttr(node, "alt") || isPresentational(node) || ctx.AccessibleName(node) != constant.EMPTY {
+			continue
+		}
+		violations = append(violations, AccessibilityViolation{Node: node,
+			Message: fmt.Sprintf("<%s> has no alt text: %s", node.Data, getAttr(node, constant.SRC))})
+	}
+	return violations
+}
+
+// checkInputLabel flags form controls without an associated label.
+func checkInputLabel(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, node := range findElements(ctx.Doc, formControlTags...) {
+		inputType := strings.ToLower(getAttr(node, "type"))
+		if node.Data == "input" {
+			switch inputType {
+			case "hidden", "submit", "reset", "button", "image":
+				continue
+			}
+		}
+		if ctx.AccessibleName(node) == constant.EMPTY {
+			violations = append(violations, AccessibilityViolation{Node: node,
+				Message: fmt.Sprintf("Form control <%s name=%q> has no label", node.Data, getAttr(node, "name"))})
+		}
+	}
+	return violations
+}
+
+// checkButtonName flags buttons without an accessible name. Submit and reset inputs have a default name.
+func checkButtonName(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, node := range findElements(ctx.Doc, "button", "input") {
+		if node.Data == "input" && !strings.EqualFold(getAttr(node, "type"), "button") {
+			continue
+		}
+		if ctx.AccessibleName(node) == constant.EMPTY {
+			violations = append(violations, AccessibilityViolation{Node: node, Message: "Button has no accessible name"})
+		}
+	}
+	return violations
+}
+
+// checkLinkName flags links whose content, such as an icon or an image without alt, gives them no accessible name.
+func checkLinkName(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, node := range findElements(ctx.Doc, "a") {
+		if !hasAttr(node, constant.H_REF) || isEmptyElement(node) {
+			continue
+		}
+		if ctx.AccessibleName(node) == constant.EMPTY {
+			violations = append(violations, AccessibilityViolation{Node: node,
+				Message: "Link has no accessible name: " + getAttr(node, constant.H_REF)})
+		}
+	}
+	return violations
+}
+
+// checkEmptyLink flags links with no content at all.
+func checkEmptyLink(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, node := range findElements(ctx.Doc, "a") {
+		if hasAttr(node, constant.H_REF) && isEmptyElement(node) && ctx.AccessibleName(node) == constant.EMPTY {
+			violations = append(violations, AccessibilityViolation{Node: node,
+				Message: "Link is empty: " + getAttr(node, constant.H_REF)})
+		}
+	}
+	return violations
+}
+
+// checkHtmlLang flags a missing or empty lang attribute on the root element.
+func checkHtmlLang(ctx *AccessibilityContext) []AccessibilityViolation {
+	roots := findElements(ctx.Doc, "html")
+	if len(roots) == 0 {
+		return nil
+	}
+	if strings.TrimSpace(getAttr(roots[0], "lang")) == constant.EMPTY && strings.TrimSpace(getAttr(roots[0], "xml:lang")) == constant.EMPTY {
+		return []AccessibilityViolation{{Node: roots[0], Message: "<html> element has no lang attribute"}}
+	}
+	return nil
+}
+
+// checkDuplicateId flags every element reusing an id of a previous element.
+func checkDuplicateId(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if id := getAttr(n, "id"); id != constant.EMPTY && ctx.ElementById(id) != n {
+				violations = append(violations, AccessibilityViolation{Node: n, Message: fmt.Sprintf("Duplicate id %q", id)})
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(ctx.Doc)
+	return violations
+}
+
+// checkAriaRole flags role attributes without any valid WAI-ARIA role. Fallback role lists are allowed.
+func checkAriaRole(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	walkElements(ctx.Doc, func(n *html.Node) {
+		if !hasAttr(n, "role") {
+			return
+		}
+		roles := strings.Fields(strings.ToLower(getAttr(n, "role")))
+		for _, role := range roles {
+			if ariaRoles[role] {
+				return
+			}
+		}
+		violations = append(violations, AccessibilityViolation{Node: n,
+			Message: fmt.Sprintf("Invalid ARIA role %q", getAttr(n, "role"))})
+	})
+	return violations
+}
+
+// checkAriaAttributes flags aria-* attributes that are not defined by WAI-ARIA and references to missing ids.
+func checkAriaAttributes(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	walkElements(ctx.Doc, func(n *html.Node) {
+		for _, attr := range n.Attr {
+			if !strings.HasPrefix(attr.Key, "aria-") {
+				continue
+			}
+			if !ariaAttributes[attr.Key] {
+				violations = append(violations, AccessibilityViolation{Node: n,
+					Message: "Unknown ARIA attribute " + attr.Key})
+				continue
+			}
+			switch attr.Key {
+			case "aria-labelledby", "aria-describedby", "aria-controls", "aria-owns", "aria-activedescendant":
+				for _, id := range strings.Fields(attr.Val) {
+					if ctx.ElementById(id) == nil {
+						violations = append(violations, AccessibilityViolation{Node: n,
+							Message: fmt.Sprintf("%s references missing id %q", attr.Key, id)})
+					}
+				}
+			}
+		}
+	})
+	return violations
+}
+
+// checkPositiveTabindex flags tabindex values above zero, which break the natural focus order.
+func checkPositiveTabindex(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	walkElements(ctx.Doc, func(n *html.Node) {
+		var tabindex int
+		if _, err := fmt.Sscanf(strings.TrimSpace(getAttr(n, "tabindex")), "%d", &tabindex); err == nil && tabindex > 0 {
+			violations = append(violations, AccessibilityViolation{Node: n,
+				Message: fmt.Sprintf("tabindex=%d changes the natural focus order", tabindex)})
+		}
+	})
+	return violations
+}
+
+// checkFrameTitle flags visible iframes and frames without a title.
+func checkFrameTitle(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, node := range findElements(ctx.Doc, "iframe", "frame") {
+		if getAttr(node, "aria-hidden") == "true" || isPresentational(node) {
+			continue
+		}
+		if ctx.AccessibleName(node) == constant.EMPTY {
+			violations = append(violations, AccessibilityViolation{Node: node,
+				Message: fmt.Sprintf("<%s> has no title: %s", node.Data, getAttr(node, constant.SRC))})
+		}
+	}
+	return violations
+}
+
+// checkTableHeaders flags data tables without any header cell. Layout tables marked presentational are skipped.
+func checkTableHeaders(ctx *AccessibilityContext) []AccessibilityViolation {
+	var violations []AccessibilityViolation
+	for _, table := range findElements(ctx.Doc, "table") {
+		if isPresentational(table) {
+			continue
+		}
+		hasHeader := false
+		for _, cell := range findElements(table, "th", "td") {
+			role := getAttr(cell, "role")
+			if cell.Data == "th" || role == "columnheader" || role == "rowheader" {
+				hasHeader = true
+				break
+			}
+		}
+		if !hasHeader {
+			violations = append(violations, AccessibilityViolation{Node: table, Message: "Table has no header cells"})
+		}
+	}
+	return violations
+}
+
+// isPresentational reports whether the element is removed from the accessibility tree by its role.
+func isPresentational(n *html.Node) bool {
+	role := strings.ToLower(strings.TrimSpace(getAttr(n, "role")))
+	return role == "presentation" || role == "none"
+}
+
+// isEmptyElement reports whether the element has neither child elements nor text.
+func isEmptyElement(n *html.Node) bool {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode || (child.Type == html.TextNode && strings.TrimSpace(child.Data) != constant.EMPTY) {
+			return false
+		}
+	}
+	return true
+}
+
+// walkElements calls visit for every element below n in document order.
+func walkElements(n *html.Node, visit func(*html.Node)) {
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		if child.Type == html.ElementNode {
+			visit(child)
+		}
+		walkElements(child, visit)
+	}
+}
+
+func toSet(values ...string) map[string]bool {
+	set := make(map[string]bool, len(values))
+	for _, value := range values {
+		set[value] = true
+	}
+	return set
+}
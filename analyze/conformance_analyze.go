@@ -0,0 +1,354 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"fmt"
+	"io"
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxConformanceIssues caps the issues listed in the report, the summary still counts every issue.
+const maxConformanceIssues = 200
+
+// ConformanceAnalyzer implements the Analyzer interface for HTML conformance.
+type ConformanceAnalyzer struct{}
+
+// NewConformanceAnalyzer creates a new ConformanceAnalyzer.
+func NewConformanceAnalyzer() *ConformanceAnalyzer {
+	return &ConformanceAnalyzer{}
+}
+
+// Analyze tokenizes the page and reports the markup errors the HTML parser silently repairs.
+func (a *ConformanceAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing HTML conformance function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("ConformanceAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	res.Conformance = CheckConformance(wc.Content)
+	return nil
+}
+
+// openElement is an element on the stack of open elements with the position of its start tag.
+type openElement struct {
+	name    string
+	line    int
+	column  int
+	foreign bool
+}
+
+// conformanceChecker follows the stack of open elements the way the HTML tree builder does, without building the
+// tree, and records where the parser has to repair the markup.
+type conformanceChecker struct {
+	stack    []openElement
+	ids      map[string]int
+	reopened map[string]int
+	issues   []response.ConformanceIssue
+	line     int
+	column   int
+}
+
+// CheckConformance reports unclosed, misnested and stray elements, invalid nesting, duplicate attributes and ids,
+// and obsolete elements and attributes, each with its line and column.
+func CheckConformance(content string) *response.ConformanceReport {
+	checker := &conformanceChecker{ids: make(map[string]int), reopened: make(map[string]int)}
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	position := newPositionTracker()
+
+	for {
+		tokenType := tokenizer.Next()
+		checker.line, checker.column = position.line, position.column
+		position.advance(tokenizer.Raw())
+
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				log.Printf("HTML tokenizer error: %v", tokenizer.Err())
+			}
+			for i := len(checker.stack) - 1; i >= 0; i-- {
+				checker.reportUnclosed(checker.stack[i])
+			}
+			return summarizeConformance(checker.issues)
+		case html.StartTagToken, html.SelfClosingTagToken:
+			checker.startTag(tokenizer.Token(), tokenType == html.SelfClosingTagToken)
+		case html.EndTagToken:
+			checker.endTag(tokenizer.Token().Data)
+		}
+	}
+}
+
+func (c *conformanceChecker) startTag(token html.Token, selfClosing bool) {
+	name := token.Data
+	c.checkAttributes(name, token.Attr)
+
+	if c.inForeignContent() || name == "svg" || name == "math" {
+		if !selfClosing {
+			c.stack = append(c.stack, openElement{name: name, line: c.line, column: c.column, foreign: true})
+		}
+		return
+	}
+
+	if replacement, ok := obsoleteElements[name]; ok {
+		c.add("obsolete-element", name, fmt.Sprintf("<%s> is obsolete, %s", name, replacement))
+	}
+	switch name {
+	case "html", "head", "body":
+		if c.find(name, elementScope) >= 0 {
+			return
+		}
+		if name == "body" {
+			if index := c.find("head", elementScope); index >= 0 {
+				c.closeTo(index)
+			}
+		}
+	}
+
+	for _, ancestor := range forbiddenAncestors[name] {
+		index := c.find(ancestor, elementScope)
+		if index < 0 {
+			continue
+		}
+		c.add("invalid-nesting", name, fmt.Sprintf("<%s> is nested in the <%s> opened at line %d", name, ancestor, c.stack[index].line))
+		switch name {
+		case "form":
+			// the parser ignores a form start tag inside a form
+			return
+		case "a", "button":
+			if ancestor == name {
+				c.closeTo(index)
+			}
+		}
+	}
+
+	c.closeImplied(name)
+	if selfClosing && !voidElements[name] {
+		c.add("self-closing-element", name, fmt.Sprintf("<%s/> is not a void element, the slash is ignored and the element stays open", name))
+	}
+	if !voidElements[name] {
+		c.stack = append(c.stack, openElement{name: name, line: c.line, column: c.column})
+	}
+}
+
+// closeImplied closes the elements the start tag of name ends implicitly and reports block elements inside
+// phrasing content.
+func (c *conformanceChecker) closeImplied(name string) {
+	switch {
+	case name == "li":
+		c.closeOpen(name, listItemScope)
+	case name == "dt" || name == "dd":
+		for _, item := range []string{"dt", "dd"} {
+			c.closeOpen(item, listScope)
+		}
+	case name == "option" || name == "optgroup":
+		if c.current() == "option" {
+			c.closeTo(len(c.stack) - 1)
+		}
+		if name == "optgroup" && c.current() == "optgroup" {
+			c.closeTo(len(c.stack) - 1)
+		}
+	case name == "tr":
+		c.closeOpen("tr", tableScope)
+	case name == "td" || name == "th":
+		for _, cell := range []string{"td", "th"} {
+			c.closeOpen(cell, tableScope)
+		}
+	case name == "thead" || name == "tbody" || name == "tfoot":
+		for _, section := range []string{"thead", "tbody", "tfoot"} {
+			c.closeOpen(section, tableScope)
+		}
+	}
+
+	if !blockElements[name] {
+		return
+	}
+	if current := c.current(); phrasingElements[current] {
+		c.add("block-in-inline", name, fmt.Sprintf("Block element <%s> is placed inside the inline <%s>", name, current))
+	}
+	if headingElements[name] && headingElements[c.current()] {
+		c.add("invalid-nesting", name, fmt.Sprintf("<%s> is nested in <%s>, the parser closes the outer heading", name, c.current()))
+		c.closeTo(len(c.stack) - 1)
+	}
+	c.closeOpen("p", buttonScope)
+}
+
+func (c *conformanceChecker) endTag(name string) {
+	if voidElements[name] {
+		c.add("stray-end-tag", name, fmt.Sprintf("</%s> is an end tag for a void element", name))
+		return
+	}
+
+	scope := elementScope
+	switch {
+	case name == "p":
+		scope = buttonScope
+	case name == "li":
+		scope = listItemScope
+	case name == "dt" || name == "dd":
+		scope = listScope
+	case tableElements[name]:
+		scope = tableScope
+	}
+	index := c.find(name, scope)
+	if index < 0 {
+		switch {
+		case name == "html" || name == "head" || name == "body":
+		case c.reopened[name] > 0:
+			c.reopened[name]--
+		default:
+			c.add("stray-end-tag", name, fmt.Sprintf("</%s> has no matching open element", name))
+		}
+		return
+	}
+
+	if !formattingElements[name] || c.stack[index].foreign {
+		c.closeTo(index)
+		return
+	}
+	// the adoption agency algorithm takes the formatting element out and keeps the elements opened inside it
+	for _, open := range c.stack[index+1:] {
+		if !optionalEndTags[open.name] {
+			c.add("misnested-element", name, fmt.Sprintf("</%s> closes <%s> while <%s> opened at line %d is still open", name, name, open.name, open.line))
+			break
+		}
+	}
+	c.stack = append(c.stack[:index], c.stack[index+1:]...)
+}
+
+// checkAttributes reports duplicate attributes and ids and obsolete attributes of a start tag.
+func (c *conformanceChecker) checkAttributes(name string, attrs []html.Attribute) {
+	seen := make(map[string]bool, len(attrs))
+	for _, attr := range attrs {
+		if seen[attr.Key] {
+			c.add("duplicate-attribute", name, fmt.Sprintf("<%s> repeats the %s attribute, only the first one is used", name, attr.Key))
+			continue
+		}
+		seen[attr.Key] = true
+
+		if attr.Key == "id" && attr.Val != constant.EMPTY {
+			if line, ok := c.ids[attr.Val]; ok {
+				c.add("duplicate-id", name, fmt.Sprintf("id %q is already used at line %d", attr.Val, line))
+			} else {
+				c.ids[attr.Val] = c.line
+			}
+		}
+		if elements, ok := obsoleteAttributes[attr.Key]; ok && !c.inForeignContent() {
+			if len(elements) == 0 || slices.Contains(elements, name) {
+				c.add("obsolete-attribute", name, fmt.Sprintf("The %s attribute of <%s> is obsolete, use CSS", attr.Key, name))
+			}
+		}
+	}
+}
+
+// closeOpen closes the open element name when it is in scope.
+func (c *conformanceChecker) closeOpen(name string, scope map[string]bool) {
+	if index := c.find(name, scope); index >= 0 {
+		c.closeTo(index)
+	}
+}
+
+// closeTo pops the stack down to and including index. The elements above index are closed implicitly, which is an
+// error for the elements whose end tag is required.
+func (c *conformanceChecker) closeTo(index int) {
+	for i := len(c.stack) - 1; i > index; i-- {
+		c.reportUnclosed(c.stack[i])
+		if formattingElements[c.stack[i].name] && !c.stack[i].foreign {
+			// the parser reopens the formatting element, so its end tag is not stray
+			c.reopened[c.stack[i].name]++
+		}
+	}
+	c.stack = c.stack[:index]
+}
+
+// find returns the index of the innermost open element name, or -1 when an element bounding the scope or a
+// foreign root is opened after it.
+func (c *conformanceChecker) find(name string, scope map[string]bool) int {
+	for i := len(c.stack) - 1; i >= 0; i-- {
+		open := c.stack[i].name
+		if open == name {
+			return i
+		}
+		if scope[open] || (c.stack[i].foreign && (open == "svg" || open == "math")) {
+			return -1
+		}
+	}
+	return -1
+}
+
+func (c *conformanceChecker) current() string {
+	if len(c.stack) == 0 {
+		return constant.EMPTY
+	}
+	return c.stack[len(c.stack)-1].name
+}
+
+func (c *conformanceChecker) inForeignContent() bool {
+	return len(c.stack) > 0 && c.stack[len(c.stack)-1].foreign && c.current() != "foreignobject"
+}
+
+// reportUnclosed reports an element closed without its end tag. Foreign elements are closed by their root, so
+// they are left out.
+func (c *conformanceChecker) reportUnclosed(open openElement) {
+	if optionalEndTags[open.name] || open.foreign {
+		return
+	}
+	c.issues = append(c.issues, response.ConformanceIssue{
+		Finding: newFinding("unclosed-element", conformanceSeverities["unclosed-element"], fmt.Sprintf("<%s> is never closed", open.name)),
+		Tag:     open.name,
+		Line:    open.line,
+		Column:  open.column,
+	})
+}
+
+func (c *conformanceChecker) add(rule, tag, message string) {
+	c.issues = append(c.issues, response.ConformanceIssue{
+		Finding: newFinding(rule, conformanceSeverities[rule], message),
+		Tag:     tag,
+		Line:    c.line,
+		Column:  c.column,
+	})
+}
+
+// summarizeConformance counts the issues per rule and caps the listed issues at maxConformanceIssues.
+func summarizeConformance(issues []response.ConformanceIssue) *response.ConformanceReport {
+	sort.SliceStable(issues, func(i, j int) bool {
+		if issues[i].Line != issues[j].Line {
+			return issues[i].Line < issues[j].Line
+		}
+		return issues[i].Column < issues[j].Column
+	})
+
+	report := &response.ConformanceReport{Total: len(issues)}
+	rules := make(map[string]*response.ConformanceSummary)
+	for _, issue := range issues {
+		summary, ok := rules[issue.Rule]
+		if !ok {
+			summary = &response.ConformanceSummary{Rule: issue.Rule, Severity: issue.Severity, FirstLine: issue.Line}
+			rules[issue.Rule] = summary
+		}
+		summary.Count++
+	}
+	for _, summary := range rules {
+		report.Summary = append(report.Summary, *summary)
+	}
+	sort.Slice(report.Summary, func(i, j int) bool {
+		if report.Summary[i].Count != report.Summary[j].Count {
+			return report.Summary[i].Count > report.Summary[j].Count
+		}
+		return report.Summary[i].Rule < report.Summary[j].Rule
+	})
+
+	if len(issues) > maxConformanceIssues {
+		issues, report.Truncated = issues[:maxConformanceIssues], true
+	}
+	report.Issues = issues
+	return report
+}
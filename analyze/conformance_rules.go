@@ -0,0 +1,139 @@
+package analyze
+
+import "api/constant"
+
+// voidElements lists the elements that have no end tag.
+var voidElements = toSet(
+	"area", "base", "br", "col", "embed", "hr", "img", "input", "keygen", "link", "meta", "param", "source",
+	"track", "wbr",
+)
+
+// optionalEndTags lists the elements whose end tag may be omitted, so closing them implicitly is not an error.
+var optionalEndTags = toSet(
+	"html", "head", "body", "p", "li", "dt", "dd", "rb", "rt", "rtc", "rp", "optgroup", "option", "colgroup",
+	"caption", "thead", "tbody", "tfoot", "tr", "td", "th",
+)
+
+// blockElements lists the flow elements that close an open <p> and do not belong inside phrasing content.
+var blockElements = toSet(
+	"address", "article", "aside", "blockquote", "center", "details", "dialog", "dir", "div", "dl", "fieldset",
+	"figcaption", "figure", "footer", "form", "h1", "h2", "h3", "h4", "h5", "h6", "header", "hgroup", "hr",
+	"listing", "main", "menu", "nav", "ol", "p", "pre", "search", "section", "summary", "table", "ul",
+)
+
+// phrasingElements lists the inline elements that may only contain phrasing content. The transparent <a>, <ins>
+// and <del> are left out.
+var phrasingElements = toSet(
+	"abbr", "b", "bdi", "bdo", "big", "cite", "code", "data", "dfn", "em", "font", "i", "kbd", "label", "mark",
+	"q", "s", "samp", "small", "span", "strike", "strong", "sub", "sup", "time", "tt", "u", "var",
+)
+
+// formattingElements lists the elements the parser reopens with the adoption agency algorithm when misnested.
+var formattingElements = toSet(
+	"a", "b", "big", "code", "em", "font", "i", "nobr", "s", "small", "strike", "strong", "tt", "u",
+)
+
+// headingElements lists the heading elements, a heading start tag closes an open heading.
+var headingElements = toSet("h1", "h2", "h3", "h4", "h5", "h6")
+
+// The scopes in which the parser looks up an open element, each lists the elements that bound the search.
+var (
+	elementScope  = toSet("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template")
+	buttonScope   = toSet("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template", "button")
+	listItemScope = toSet("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template", "ol", "ul", "menu")
+	listScope     = toSet("applet", "caption", "html", "table", "td", "th", "marquee", "object", "template", "dl")
+	tableScope    = toSet("html", "table", "template")
+)
+
+// tableElements lists the elements looked up in table scope.
+var tableElements = toSet("table", "caption", "colgroup", "thead", "tbody", "tfoot", "tr", "td", "th")
+
+// forbiddenAncestors maps an element to the open elements it must not be nested in.
+var forbiddenAncestors = map[string][]string{
+	"a":      {"a", "button"},
+	"button": {"a", "button"},
+	"form":   {"form"},
+	"label":  {"label"},
+}
+
+// obsoleteElements maps the obsolete elements of the HTML standard to their replacement.
+var obsoleteElements = map[string]string{
+	"acronym":   "use <abbr>",
+	"applet":    "use <object> or <embed>",
+	"basefont":  "use CSS",
+	"bgsound":   "use <audio>",
+	"big":       "use CSS",
+	"blink":     "use CSS animations",
+	"center":    "use CSS text-align or margin",
+	"dir":       "use <ul>",
+	"font":      "use CSS",
+	"frame":     "use <iframe> or CSS layout",
+	"frameset":  "use <iframe> or CSS layout",
+	"isindex":   "use a <form> with a text input",
+	"keygen":    "use the Web Crypto API",
+	"listing":   "use <pre> and <code>",
+	"marquee":   "use CSS animations",
+	"multicol":  "use CSS columns",
+	"nextid":    "use GUIDs",
+	"nobr":      "use CSS white-space",
+	"noembed":   "use <object> fallback content",
+	"noframes":  "use <iframe> or CSS layout",
+	"plaintext": "use text/plain",
+	"spacer":    "use CSS",
+	"strike":    "use <del> or <s>",
+	"tt":        "use <kbd>, <code>, <samp> or CSS",
+	"xmp":       "use <pre> and <code>",
+}
+
+// obsoleteAttributes maps the obsolete presentational attributes to the elements they are obsolete on. An empty
+// list means every element.
+var obsoleteAttributes = map[string][]string{
+	"align": {"caption", "col", "colgroup", "div", "embed", "h1", "h2", "h3", "h4", "h5", "h6", "hr", "iframe",
+		"img", "input", "legend", "object", "p", "table", "tbody", "td", "tfoot", "th", "thead", "tr"},
+	"alink":        {"body"},
+	"background":   {},
+	"bgcolor":      {},
+	"border":       {"img", "object"},
+	"cellpadding":  {"table"},
+	"cellspacing":  {"table"},
+	"char":         {"col", "colgroup", "tbody", "td", "tfoot", "th", "thead", "tr"},
+	"charoff":      {"col", "colgroup", "tbody", "td", "tfoot", "th", "thead", "tr"},
+	"charset":      {"a", "link", "script"},
+	"clear":        {"br"},
+	"color":        {"hr"},
+	"compact":      {"dl", "menu", "ol", "ul"},
+	"frameborder":  {"iframe"},
+	"frame":        {"table"},
+	"height":       {"table", "td", "th", "tr"},
+	"hspace":       {"embed", "iframe", "img", "input", "object"},
+	"language":     {"script"},
+	"link":         {"body"},
+	"longdesc":     {"iframe", "img"},
+	"marginheight": {"body", "iframe"},
+	"marginwidth":  {"body", "iframe"},
+	"noshade":      {"hr"},
+	"nowrap":       {"td", "th"},
+	"rev":          {"a", "link"},
+	"rules":        {"table"},
+	"scrolling":    {"iframe"},
+	"size":         {"hr"},
+	"text":         {"body"},
+	"valign":       {"col", "colgroup", "tbody", "td", "tfoot", "th", "thead", "tr"},
+	"vlink":        {"body"},
+	"vspace":       {"embed", "iframe", "img", "input", "object"},
+	"width":        {"col", "colgroup", "hr", "pre", "table", "td", "th"},
+}
+
+// conformanceSeverities maps every conformance rule to its severity.
+var conformanceSeverities = map[string]string{
+	"stray-end-tag":        constant.SEVERITY_ERROR,
+	"unclosed-element":     constant.SEVERITY_ERROR,
+	"misnested-element":    constant.SEVERITY_ERROR,
+	"invalid-nesting":      constant.SEVERITY_ERROR,
+	"duplicate-attribute":  constant.SEVERITY_ERROR,
+	"duplicate-id":         constant.SEVERITY_ERROR,
+	"block-in-inline":      constant.SEVERITY_WARNING,
+	"self-closing-element": constant.SEVERITY_WARNING,
+	"obsolete-element":     constant.SEVERITY_WARNING,
+	"obsolete-attribute":   constant.SEVERITY_WARNING,
+}
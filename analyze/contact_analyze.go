@@ -0,0 +1,396 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// emailRegex matches an email address in text.
+	emailRegex = regexp.MustCompile(`(?i)\b[a-z0-9._%+-]+@[a-z0-9](?:[a-z0-9-]*[a-z0-9])?` +
+		`(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.[a-z]{2,24}\b`)
+	// assetNameRegex matches file names such as logo@2x.png that look like email addresses.
+	assetNameRegex = regexp.MustCompile(`(?i)\.(?:png|jpe?g|gif|svg|webp|avif|ico|css|js)$`)
+)
+
+// socialPlatform recognizes the profile links of a social network. The first group of profile is the handle.
+type socialPlatform struct {
+	name    string
+	domains []string
+	profile *regexp.Regexp
+}
+
+var socialPlatforms = []socialPlatform{
+	{"LinkedIn", []string{"linkedin.com"}, regexp.MustCompile(`^/((?:in|company|school|showcase)/[^/?#]+)`)},
+	{"X", []string{"twitter.com", "x.com"}, regexp.MustCompile(`^/([A-Za-z0-9_]{1,15})/?$`)},
+	{"Facebook", []string{"facebook.com", "fb.com"}, regexp.MustCompile(`^/([A-Za-z0-9.\-]{3,})/?$`)},
+	{"Instagram", []string{"instagram.com"}, regexp.MustCompile(`^/([A-Za-z0-9._]{2,30})/?$`)},
+	{"GitHub", []string{"github.com"}, regexp.MustCompile(`^/([A-Za-z0-9-]{1,39})(?:/[^/]*)?/?$`)},
+	{"YouTube", []string{"youtube.com"}, regexp.MustCompile(`^/(@[^/]+|(?:channel|c|user)/[^/]+)`)},
+	{"TikTok", []string{"tiktok.com"}, regexp.MustCompile(`^/(@[^/]+)/?$`)},
+	{"Pinterest", []string{"pinterest.com"}, regexp.MustCompile(`^/([A-Za-z0-9_]{3,30})/?$`)},
+	{"Threads", []string{"threads.net"}, regexp.MustCompile(`^/(@[^/]+)/?$`)},
+	{"Medium", []string{"medium.com"}, regexp.MustCompile(`^/(@[^/]+)/?$`)},
+	{"Reddit", []string{"reddit.com"}, regexp.MustCompile(`^/((?:r|u|user)/[^/]+)/?$`)},
+	{"Telegram", []string{"t.me"}, regexp.MustCompile(`^/([A-Za-z0-9_]{5,32})/?$`)},
+	{"Discord", []string{"discord.gg", "discord.com"}, regexp.MustCompile(`^/((?:invite/)?[A-Za-z0-9-]{2,})/?$`)},
+}
+
+// reservedSocialPaths lists the paths of the social networks that are pages or share intents rather than profiles.
+var reservedSocialPaths = toSet(
+	"share", "sharer", "sharer.php", "share.php", "intent", "dialog", "plugins", "login", "login.php", "signup",
+	"home", "home.php", "search", "hashtag", "explore", "settings", "privacy", "tos", "legal", "about", "help",
+	"watch", "p", "reel", "i", "features", "pricing", "policies", "terms", "pin", "results",
+)
+
+// landmarkRoles maps the landmark roles to the element they stand for.
+var landmarkRoles = map[string]string{
+	"banner": "header", "contentinfo": "footer", "navigation": "nav", "main": "main", "complementary": "aside",
+}
+
+// ContactAnalyzer implements the Analyzer interface for contact details.
+type ContactAnalyzer struct{}
+
+// NewContactAnalyzer creates a new ContactAnalyzer.
+func NewContactAnalyzer() *ContactAnalyzer {
+	return &ContactAnalyzer{}
+}
+
+// Analyze extracts the email addresses, phone numbers, postal addresses and social profiles of the page.
+func (a *ContactAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing contacts function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("ContactAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing contacts",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	res.Contacts = ExtractContacts(doc, pageURL, configs.GetConfig().ContactEmails)
+	return nil
+}
+
+// contactCollector deduplicates the contact details and merges the places they were found.
+type contactCollector struct {
+	report        *response.ContactReport
+	includeEmails bool
+	region        string
+	emails        map[string]int
+	phones        map[string]int
+	addresses     map[string]int
+	profiles      map[string]int
+}
+
+// ExtractContacts collects the contact details from links, text, <address> elements and structured data, each
+// with the places it appeared. Email addresses are left out unless includeEmails is set.
+func ExtractContacts(doc *html.Node, pageURL string, includeEmails bool) *response.ContactReport {
+	collector := &contactCollector{
+		report:        &response.ContactReport{EmailsDisabled: !includeEmails},
+		includeEmails: includeEmails,
+		emails:        make(map[string]int),
+		phones:        make(map[string]int),
+		addresses:     make(map[string]int),
+		profiles:      make(map[string]int),
+	}
+	if roots := findElements(doc, "html"); len(roots) > 0 {
+		collector.region = phoneRegion(pageURL, getAttr(roots[0], "lang"))
+	} else {
+		collector.region = phoneRegion(pageURL, constant.EMPTY)
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			collector.scanText(n.Data, response.ContactSource{Type: constant.CONTACT_SOURCE_TEXT, Section: contactSection(n)})
+		case html.ElementNode:
+			if hiddenTags[n.Data] {
+				return
+			}
+			switch n.Data {
+			case "a", "area":
+				if collector.addLink(n, pageURL) {
+					// the text of a mailto or tel link repeats the link
+					return
+				}
+			case "address":
+				collector.addAddressElement(n)
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+
+	entities := ExtractJsonLdEntities(doc)
+	entities = append(entities, ExtractMicrodata(doc)...)
+	entities = append(entities, ExtractRdfa(doc)...)
+	for _, entity := range entities {
+		collector.addStructured(entity.Properties, response.ContactSource{Type: entity.Source})
+	}
+	return collector.report
+}
+
+// addLink records the email, phone or social profile of a link. It reports whether the link was a mailto or tel
+// link.
+func (c *contactCollector) addLink(n *html.Node, pageURL string) bool {
+	href := strings.TrimSpace(getAttr(n, constant.H_REF))
+	scheme, rest, _ := strings.Cut(href, ":")
+	section := contactSection(n)
+	switch strings.ToLower(scheme) {
+	case "mailto":
+		addresses, _, _ := strings.Cut(rest, "?")
+		if unescaped, err := url.PathUnescape(addresses); err == nil {
+			addresses = unescaped
+		}
+		for _, address := range strings.Split(addresses, ",") {
+			c.addEmail(address, response.ContactSource{Type: constant.CONTACT_SOURCE_MAILTO, Section: section})
+		}
+		return true
+	case "tel":
+		number := rest
+		if unescaped, err := url.PathUnescape(rest); err == nil {
+			number = unescaped
+		}
+		c.addPhone(number, false, response.ContactSource{Type: constant.CONTACT_SOURCE_TEL, Section: section})
+		return true
+	}
+	if link := resolveURL(href, pageURL); link != constant.EMPTY {
+		c.addProfile(link, response.ContactSource{Type: constant.CONTACT_SOURCE_LINK, Section: section})
+	}
+	return false
+}
+
+// scanText records the email addresses and the phone numbers that can be normalized found in text.
+func (c *contactCollector) scanText(text string, source response.ContactSource) {
+	for _, match := range emailRegex.FindAllString(text, -1) {
+		c.addEmail(match, source)
+	}
+	for _, match := range phoneTextRegex.FindAllString(text, -1) {
+		if !isNumericFigure(match) {
+			c.addPhone(match, true, source)
+		}
+	}
+}
+
+func (c *contactCollector) addEmail(address string, source response.ContactSource) {
+	address = strings.ToLower(strings.TrimSpace(address))
+	if !c.includeEmails || !emailRegex.MatchString(address) || emailRegex.FindString(address) != address ||
+		assetNameRegex.MatchString(address) {
+		return
+	}
+	if index, ok := c.emails[address]; ok {
+		c.report.Emails[index].Sources = appendSource(c.report.Emails[index].Sources, source)
+		return
+	}
+	c.emails[address] = len(c.report.Emails)
+	c.report.Emails = append(c.report.Emails, response.ContactEmail{Address: address, Sources: []response.ContactSource{source}})
+}
+
+// addPhone records a phone number by its E.164 form. A number that cannot be normalized is kept only when it
+// comes from a tel link or structured data, as text matches are not reliable enough on their own.
+func (c *contactCollector) addPhone(raw string, requireValid bool, source response.ContactSource) {
+	raw = normalizeSpace(raw)
+	number, ok := NormalizePhoneNumber(raw, c.region)
+	if !ok && requireValid {
+		return
+	}
+	key := number
+	if key == constant.EMPTY {
+		key = strings.Map(func(r rune) rune {
+			if (r >= '0' && r <= '9') || r == '+' {
+				return r
+			}
+			return -1
+		}, raw)
+	}
+	if key == constant.EMPTY {
+		return
+	}
+	if index, exists := c.phones[key]; exists {
+		c.report.Phones[index].Sources = appendSource(c.report.Phones[index].Sources, source)
+		return
+	}
+	c.phones[key] = len(c.report.Phones)
+	c.report.Phones = append(c.report.Phones, response.ContactPhone{Number: number, Raw: raw, Sources: []response.ContactSource{source}})
+}
+
+// addAddressElement records the postal address of an <address> element. Lines holding only an email address or a
+// phone number are dropped and the remaining text must contain a number, such as a house number or a postal code.
+func (c *contactCollector) addAddressElement(n *html.Node) {
+	var lines []string
+	for _, line := range textBlocks(n, func(node *html.Node) bool { return hiddenTags[node.Data] }) {
+		if emailRegex.FindString(line) == line || phoneTextRegex.FindString(line) == line {
+			continue
+		}
+		lines = append(lines, strings.Trim(line, " ,"))
+	}
+	text := strings.Join(lines, ", ")
+	if !strings.ContainsAny(text, "0123456789") {
+		return
+	}
+	c.addAddress(response.PostalAddress{Text: text}, response.ContactSource{Type: constant.CONTACT_SOURCE_ADDRESS, Section: contactSection(n.Parent)})
+}
+
+func (c *contactCollector) addAddress(address response.PostalAddress, source response.ContactSource) {
+	if address.Text == constant.EMPTY {
+		var parts []string
+		for _, part := range []string{address.StreetAddress, address.Locality,
+			strings.TrimSpace(address.Region + " " + address.PostalCode), address.Country} {
+			if part != constant.EMPTY {
+				parts = append(parts, part)
+			}
+		}
+		address.Text = strings.Join(parts, ", ")
+	}
+	if address.Text == constant.EMPTY {
+		return
+	}
+	key := strings.ToLower(strings.Join(strings.FieldsFunc(address.Text, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n'
+	}), " "))
+	if index, ok := c.addresses[key]; ok {
+		c.report.Addresses[index].Sources = appendSource(c.report.Addresses[index].Sources, source)
+		return
+	}
+	c.addresses[key] = len(c.report.Addresses)
+	address.Sources = []response.ContactSource{source}
+	c.report.Addresses = append(c.report.Addresses, address)
+}
+
+// addProfile records a link to a social network profile. Share intents and other pages of the network are skipped.
+func (c *contactCollector) addProfile(link string, source response.ContactSource) {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return
+	}
+	for _, platform := range socialPlatforms {
+		if !matchesAnyDomain(link, platform.domains) {
+			continue
+		}
+		groups := platform.profile.FindStringSubmatch(parsedURL.EscapedPath())
+		if groups == nil || reservedSocialPaths[strings.ToLower(strings.TrimPrefix(strings.Split(groups[1], "/")[0], "@"))] {
+			return
+		}
+		handle := groups[1]
+		key := platform.name + " " + strings.ToLower(handle)
+		if index, ok := c.profiles[key]; ok {
+			c.report.SocialProfiles[index].Sources = appendSource(c.report.SocialProfiles[index].Sources, source)
+			return
+		}
+		c.profiles[key] = len(c.report.SocialProfiles)
+		c.report.SocialProfiles = append(c.report.SocialProfiles, response.SocialProfile{
+			Platform: platform.name,
+			Handle:   handle,
+			Url:      fmt.Sprintf("https://%s/%s", strings.TrimPrefix(parsedURL.Hostname(), "www."), handle),
+			Sources:  []response.ContactSource{source},
+		})
+		return
+	}
+}
+
+// addStructured records the email, telephone, address and sameAs properties of a structured data item and of the
+// items nested in it.
+func (c *contactCollector) addStructured(item map[string]any, source response.ContactSource) {
+	if schemaTypeName(item["@type"]) == "PostalAddress" || hasAnyProperty(item, "streetAddress", "postalCode") {
+		c.addAddress(response.PostalAddress{
+			StreetAddress: structuredText(item["streetAddress"]),
+			Locality:      structuredText(item["addressLocality"]),
+			Region:        structuredText(item["addressRegion"]),
+			PostalCode:    structuredText(item["postalCode"]),
+			Country:       structuredText(item["addressCountry"]),
+		}, source)
+	}
+	keys := make([]string, 0, len(item))
+	for key := range item {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, entry := range asList(item[key]) {
+			if nested, ok := entry.(map[string]any); ok {
+				c.addStructured(nested, source)
+				continue
+			}
+			text := structuredText(entry)
+			switch key {
+			case "email":
+				c.addEmail(strings.TrimPrefix(text, "mailto:"), source)
+			case "telephone":
+				c.addPhone(strings.TrimPrefix(text, "tel:"), false, source)
+			case "address":
+				c.addAddress(response.PostalAddress{Text: text}, source)
+			case "sameAs":
+				c.addProfile(text, source)
+			}
+		}
+	}
+}
+
+// structuredText returns the text of a structured data value, using the name of a nested item such as a Country.
+func structuredText(value any) string {
+	switch typed := value.(type) {
+	case string:
+		return strings.TrimSpace(typed)
+	case float64:
+		return fmt.Sprint(typed)
+	case map[string]any:
+		return structuredText(typed["name"])
+	case []any:
+		if len(typed) > 0 {
+			return structuredText(typed[0])
+		}
+	}
+	return constant.EMPTY
+}
+
+// contactSection returns the landmark of the page the node is in: header, nav, main, article, aside, footer or
+// address, or body.
+func contactSection(n *html.Node) string {
+	for node := n; node != nil; node = node.Parent {
+		if node.Type != html.ElementNode {
+			continue
+		}
+		switch node.Data {
+		case "header", "nav", "main", "article", "aside", "footer", "address":
+			return node.Data
+		}
+		if landmark, ok := landmarkRoles[getAttr(node, "role")]; ok {
+			return landmark
+		}
+	}
+	return "body"
+}
+
+func appendSource(sources []response.ContactSource, source response.ContactSource) []response.ContactSource {
+	for _, existing := range sources {
+		if existing == source {
+			return sources
+		}
+	}
+	return append(sources, source)
+}
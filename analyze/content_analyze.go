@@ -0,0 +1,445 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	readingWordsPerMinute  = 230
+	thinContentWords       = 300
+	minTextHtmlRatio       = 10.0
+	keywordStuffingDensity = 5.0
+	difficultReadingEase   = 30.0
+	maxKeywords            = 10
+	maxExcerptLength       = 300
+	minParagraphLength     = 25
+)
+
+var (
+	// wordRegex matches a word, keeping apostrophes inside it.
+	wordRegex = regexp.MustCompile(`[\p{L}\p{N}]+(?:['’][\p{L}]+)*`)
+	// sentenceEndRegex matches the punctuation that ends a sentence.
+	sentenceEndRegex = regexp.MustCompile(`[.!?…。！？]+(?:\s|$)`)
+	// boilerplateRegex matches the class and id names of navigation, sharing, advertising and other page chrome.
+	boilerplateRegex = regexp.MustCompile(`(?i)(?:^|[^a-z])(?:ads?|advert\w*|banner|breadcrumbs?|comments?|cookie\w*|` +
+		`footer|menu|modal|nav\w*|newsletter|popup|promo\w*|related|share|sharing|sidebar|social|sponsor\w*|subscribe|` +
+		`widget)(?:$|[^a-z])`)
+)
+
+// hiddenTags lists the elements whose text is never rendered.
+var hiddenTags = toSet("script", "style", "noscript", "template", "head")
+
+// boilerplateTags lists the elements left out of the main content.
+var boilerplateTags = toSet(
+	"script", "style", "noscript", "template", "svg", "nav", "footer", "aside", "form", "iframe", "button",
+	"select", "textarea", "object", "embed", "canvas", "dialog",
+)
+
+// boilerplateRoles lists the landmark and widget roles left out of the main content.
+var boilerplateRoles = toSet("navigation", "banner", "contentinfo", "complementary", "search", "menu", "menubar", "dialog", "alert")
+
+// paragraphTags lists the elements scored as paragraphs when looking for the main content.
+var paragraphTags = []string{"p", "pre", "blockquote", "td", "dd", "li"}
+
+// ContentAnalyzer implements the Analyzer interface for content and readability.
+type ContentAnalyzer struct{}
+
+// NewContentAnalyzer creates a new ContentAnalyzer.
+func NewContentAnalyzer() *ContentAnalyzer {
+	return &ContentAnalyzer{}
+}
+
+// Analyze extracts the main text of the page and reports its length, readability, keywords and language.
+func (a *ContentAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing content function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("ContentAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing content",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	res.Content = AnalyzeContent(doc, len(wc.Content))
+	res.Content.Charset = wc.Charset()
+	if res.Content.DeclaredLanguage == constant.EMPTY && wc.Headers != nil {
+		res.Content.DeclaredLanguage = strings.TrimSpace(strings.Split(wc.Headers.Get("Content-Language"), ",")[0])
+	}
+	res.Content.LanguageMatch = sameLanguage(res.Content.Language, res.Content.DeclaredLanguage)
+	res.Content.Findings = auditContent(res.Content)
+	return nil
+}
+
+// AnalyzeContent measures the main content of the document. htmlSize is the size of the page source in bytes.
+func AnalyzeContent(doc *html.Node, htmlSize int) *response.ContentReport {
+	report := &response.ContentReport{}
+	body := doc
+	if bodies := findElements(doc, "body"); len(bodies) > 0 {
+		body = bodies[0]
+	}
+	if roots := findElements(doc, "html"); len(roots) > 0 {
+		report.DeclaredLanguage = strings.TrimSpace(getAttr(roots[0], "lang"))
+	}
+
+	pageBlocks := textBlocks(body, func(n *html.Node) bool { return hiddenTags[n.Data] })
+	pageText := strings.Join(pageBlocks, " ")
+	report.PageWordCount = len(wordRegex.FindAllString(pageText, -1))
+	if htmlSize > 0 {
+		report.TextHtmlRatio = roundTo(float64(len(pageText))/float64(htmlSize)*100, 2)
+	}
+
+	blocks := textBlocks(findMainContent(body), isBoilerplate)
+	mainText := strings.Join(blocks, " ")
+	var words []string
+	for _, word := range wordRegex.FindAllString(mainText, -1) {
+		words = append(words, strings.ToLower(word))
+	}
+	report.WordCount = len(words)
+	report.ParagraphCount = len(blocks)
+	report.Excerpt = excerpt(mainText)
+	if report.WordCount > 0 {
+		report.ReadingTimeMinutes = int(math.Ceil(float64(report.WordCount) / readingWordsPerMinute))
+	}
+	for _, block := range blocks {
+		report.SentenceCount += countSentences(block)
+	}
+
+	report.Language = DetectLanguage(mainText, words)
+	language := report.Language
+	if language == constant.EMPTY {
+		language = primaryLanguage(report.DeclaredLanguage)
+	}
+	if language == "en" && report.WordCount > 0 && report.SentenceCount > 0 {
+		report.Readability = fleschReadability(words, report.SentenceCount)
+	}
+	report.Keywords, report.Phrases = extractKeywords(blocks, report.WordCount, language)
+	return report
+}
+
+// findMainContent returns the element holding the main content: the largest <main>, <article> or role=main
+// element, or else the element whose paragraphs score best, readability style. It falls back to root.
+func findMainContent(root *html.Node) *html.Node {
+	var best *html.Node
+	bestLength := 0
+	walkElements(root, func(n *html.Node) {
+		if n.Data != "main" && n.Data != "article" && getAttr(n, "role") != "main" {
+			return
+		}
+		if length := len(strings.Join(textBlocks(n, isBoilerplate), " ")); length > bestLength {
+			best, bestLength = n, length
+		}
+	})
+	if best != nil {
+		return best
+	}
+
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node
+	addScore := func(n *html.Node, score float64) {
+		if _, ok := scores[n]; !ok {
+			candidates = append(candidates, n)
+		}
+		scores[n] += score
+	}
+	for _, paragraph := range findElements(root, paragraphTags...) {
+		if excludedByAncestor(paragraph) {
+			continue
+		}
+		text := normalizeSpace(nodeText(paragraph))
+		if len(text) < minParagraphLength {
+			continue
+		}
+		score := 1 + float64(strings.Count(text, ",")) + math.Min(float64(len(text)/100), 3)
+		if parent := paragraph.Parent; parent != nil {
+			addScore(parent, score)
+			if grandparent := parent.Parent; grandparent != nil {
+				addScore(grandparent, score/2)
+			}
+		}
+	}
+
+	bestScore := 0.0
+	for _, candidate := range candidates {
+		if score := scores[candidate] * (1 - linkDensity(candidate)); score > bestScore {
+			best, bestScore = candidate, score
+		}
+	}
+	if best == nil || best.Type != html.ElementNode {
+		return root
+	}
+	return best
+}
+
+// isBoilerplate reports whether the element is page chrome rather than content.
+func isBoilerplate(n *html.Node) bool {
+	if boilerplateTags[n.Data] || boilerplateRoles[getAttr(n, "role")] || hasAttr(n, "hidden") ||
+		getAttr(n, "aria-hidden") == "true" {
+		return true
+	}
+	switch n.Data {
+	case "html", "body", "main", "article":
+		return false
+	case "header":
+		return !hasAncestor(n, "article") && !hasAncestor(n, "main")
+	}
+	return boilerplateRegex.MatchString(getAttr(n, "class") + " " + getAttr(n, "id"))
+}
+
+func excludedByAncestor(n *html.Node) bool {
+	for node := n; node != nil; node = node.Parent {
+		if node.Type == html.ElementNode && isBoilerplate(node) {
+			return true
+		}
+	}
+	return false
+}
+
+// linkDensity returns the share of the text of n that is link text.
+func linkDensity(n *html.Node) float64 {
+	length := len(normalizeSpace(nodeText(n)))
+	if length == 0 {
+		return 0
+	}
+	linkLength := 0
+	for _, link := range findElements(n, "a") {
+		linkLength += len(normalizeSpace(nodeText(link)))
+	}
+	return math.Min(float64(linkLength)/float64(length), 1)
+}
+
+// textBlocks returns the rendered text of n split at block element boundaries, skipping the elements for which skip
+// returns true.
+func textBlocks(n *html.Node, skip func(*html.Node) bool) []string {
+	var blocks []string
+	var current strings.Builder
+	flush := func() {
+		if text := normalizeSpace(current.String()); text != constant.EMPTY {
+			blocks = append(blocks, text)
+		}
+		current.Reset()
+	}
+
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		switch node.Type {
+		case html.TextNode:
+			current.WriteString(node.Data)
+			return
+		case html.ElementNode:
+			if node != n && skip(node) {
+				return
+			}
+		}
+		block := node.Type == html.ElementNode && !phrasingElements[node.Data] && !inlineContainers[node.Data]
+		if block {
+			flush()
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+		if block {
+			flush()
+		}
+	}
+	walk(n)
+	flush()
+	return blocks
+}
+
+// inlineContainers lists the inline elements that are not phrasing-only, so they do not split text blocks.
+var inlineContainers = toSet("a", "ins", "del", "img", "wbr", "ruby", "rt", "rp", "output", "meter", "progress")
+
+func countSentences(block string) int {
+	count := 0
+	for _, sentence := range sentenceEndRegex.Split(block, -1) {
+		if wordRegex.MatchString(sentence) {
+			count++
+		}
+	}
+	return count
+}
+
+// fleschReadability computes the Flesch reading ease and the Flesch-Kincaid grade of English text.
+func fleschReadability(words []string, sentences int) *response.Readability {
+	syllables := 0
+	for _, word := range words {
+		syllables += countSyllables(word)
+	}
+	wordsPerSentence := float64(len(words)) / float64(sentences)
+	syllablesPerWord := float64(syllables) / float64(len(words))
+
+	readability := &response.Readability{
+		FleschReadingEase:  roundTo(206.835-1.015*wordsPerSentence-84.6*syllablesPerWord, 1),
+		FleschKincaidGrade: roundTo(0.39*wordsPerSentence+11.8*syllablesPerWord-15.59, 1),
+	}
+	switch ease := readability.FleschReadingEase; {
+	case ease >= 90:
+		readability.Level = "Very easy"
+	case ease >= 80:
+		readability.Level = "Easy"
+	case ease >= 70:
+		readability.Level = "Fairly easy"
+	case ease >= 60:
+		readability.Level = "Standard"
+	case ease >= 50:
+		readability.Level = "Fairly difficult"
+	case ease >= difficultReadingEase:
+		readability.Level = "Difficult"
+	default:
+		readability.Level = "Very difficult"
+	}
+	return readability
+}
+
+// countSyllables estimates the syllables of an English word from its vowel groups.
+func countSyllables(word string) int {
+	word = strings.Trim(strings.ToLower(word), "'’")
+	if utf8.RuneCountInString(word) <= 3 {
+		return 1
+	}
+	if strings.HasSuffix(word, "es") || strings.HasSuffix(word, "ed") {
+		word = word[:len(word)-2]
+	} else if strings.HasSuffix(word, "e") && !strings.HasSuffix(word, "le") {
+		word = word[:len(word)-1]
+	}
+
+	count, previousVowel := 0, false
+	for _, r := range word {
+		vowel := strings.ContainsRune("aeiouy", r)
+		if vowel && !previousVowel {
+			count++
+		}
+		previousVowel = vowel
+	}
+	return max(count, 1)
+}
+
+// extractKeywords returns the most frequent words and the most frequent two and three word phrases of the text
+// blocks, leaving out the stopwords of the language. Phrases do not cross block boundaries.
+func extractKeywords(blocks []string, wordCount int, language string) ([]response.Keyword, []response.Keyword) {
+	stopwords := languageStopwords[language]
+	if stopwords == nil {
+		stopwords = languageStopwords["en"]
+	}
+	isTerm := func(word string) bool {
+		return utf8.RuneCountInString(word) >= 3 && !stopwords[word] && strings.Trim(word, "0123456789") != constant.EMPTY
+	}
+
+	words := make(map[string]int)
+	phrases := make(map[string]int)
+	for _, block := range blocks {
+		tokens := wordRegex.FindAllString(strings.ToLower(block), -1)
+		for i, token := range tokens {
+			if isTerm(token) {
+				words[token]++
+			}
+			for n := 2; n <= 3 && i+n <= len(tokens); n++ {
+				gram := tokens[i : i+n]
+				if isTerm(gram[0]) && isTerm(gram[n-1]) {
+					phrases[strings.Join(gram, " ")]++
+				}
+			}
+		}
+	}
+	return topKeywords(words, wordCount), topKeywords(phrases, wordCount)
+}
+
+// topKeywords returns the terms found at least twice, most frequent first, with their density in percent of the
+// words. The density of a phrase counts each of its words.
+func topKeywords(counts map[string]int, wordCount int) []response.Keyword {
+	var keywords []response.Keyword
+	for term, count := range counts {
+		if count < 2 {
+			continue
+		}
+		density := float64(count*len(strings.Fields(term))) / float64(wordCount) * 100
+		keywords = append(keywords, response.Keyword{Term: term, Count: count, Density: roundTo(density, 2)})
+	}
+	sort.Slice(keywords, func(i, j int) bool {
+		if keywords[i].Count != keywords[j].Count {
+			return keywords[i].Count > keywords[j].Count
+		}
+		return keywords[i].Term < keywords[j].Term
+	})
+	if len(keywords) > maxKeywords {
+		keywords = keywords[:maxKeywords]
+	}
+	return keywords
+}
+
+func auditContent(report *response.ContentReport) []response.Finding {
+	var findings []response.Finding
+	if report.WordCount < thinContentWords {
+		findings = append(findings, newFinding("content-thin", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Main content has %d words, pages with less than %d words are considered thin", report.WordCount, thinContentWords)))
+	}
+	if report.TextHtmlRatio < minTextHtmlRatio {
+		findings = append(findings, newFinding("text-html-ratio-low", constant.SEVERITY_INFO,
+			fmt.Sprintf("Visible text is %.2f%% of the HTML, below %.0f%%", report.TextHtmlRatio, minTextHtmlRatio)))
+	}
+	if report.Language != constant.EMPTY && report.DeclaredLanguage != constant.EMPTY && !report.LanguageMatch {
+		findings = append(findings, newFinding("language-mismatch", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Content appears to be in %q but the page declares %q", report.Language, report.DeclaredLanguage)))
+	}
+	if report.Readability != nil && report.Readability.FleschReadingEase < difficultReadingEase {
+		findings = append(findings, newFinding("readability-difficult", constant.SEVERITY_INFO,
+			fmt.Sprintf("Flesch reading ease of %.1f is very difficult to read", report.Readability.FleschReadingEase)))
+	}
+	if report.WordCount >= 100 {
+		for _, keyword := range report.Keywords {
+			if keyword.Density > keywordStuffingDensity {
+				findings = append(findings, newFinding("keyword-stuffing", constant.SEVERITY_WARNING,
+					fmt.Sprintf("%q makes up %.2f%% of the words", keyword.Term, keyword.Density)))
+			}
+		}
+	}
+	return findings
+}
+
+// excerpt returns the start of the text, cut at a word boundary.
+func excerpt(text string) string {
+	if utf8.RuneCountInString(text) <= maxExcerptLength {
+		return text
+	}
+	runes := []rune(text)[:maxExcerptLength]
+	cut := string(runes)
+	if index := strings.LastIndex(cut, " "); index > 0 {
+		cut = cut[:index]
+	}
+	return cut + "…"
+}
+
+// primaryLanguage returns the primary subtag of a language tag such as en-US.
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(strings.TrimSpace(tag)), "-")
+	primary, _, _ = strings.Cut(primary, "_")
+	return primary
+}
+
+func sameLanguage(detected, declared string) bool {
+	return detected != constant.EMPTY && detected == primaryLanguage(declared)
+}
+
+func roundTo(value float64, decimals int) float64 {
+	scale := math.Pow(10, float64(decimals))
+	return math.Round(value*scale) / scale
+}
@@ -0,0 +1,129 @@
+package analyze
+
+import (
+	"api/constant"
+	"unicode"
+)
+
+// minLanguageWords is the number of words needed before the content language is guessed.
+const minLanguageWords = 20
+
+// languageStopwords lists the most frequent function words of the languages written in the Latin script. They
+// identify the language of the content and are left out of the keywords.
+var languageStopwords = map[string]map[string]bool{
+	"en": toSet(
+		"a", "about", "after", "all", "also", "an", "and", "any", "are", "as", "at", "be", "been", "but", "by",
+		"can", "could", "do", "does", "for", "from", "had", "has", "have", "he", "her", "his", "how", "i", "if",
+		"in", "into", "is", "it", "its", "just", "more", "most", "my", "no", "not", "of", "on", "one", "or",
+		"our", "out", "she", "so", "some", "than", "that", "the", "their", "them", "then", "there", "these",
+		"they", "this", "those", "to", "up", "was", "we", "were", "what", "when", "which", "who", "will", "with",
+		"would", "you", "your",
+	),
+	"de": toSet(
+		"aber", "als", "am", "an", "auch", "auf", "aus", "bei", "bin", "bis", "das", "dass", "dem", "den", "der",
+		"des", "die", "durch", "ein", "eine", "einem", "einen", "einer", "er", "es", "für", "hat", "ich", "ihr",
+		"im", "in", "ist", "mit", "nach", "nicht", "noch", "nur", "oder", "sich", "sie", "sind", "über", "um",
+		"und", "uns", "vom", "von", "vor", "war", "wie", "wir", "wird", "zu", "zum", "zur",
+	),
+	"fr": toSet(
+		"à", "au", "aux", "avec", "ce", "ces", "cette", "dans", "de", "des", "du", "elle", "en", "est", "et",
+		"il", "ils", "je", "la", "le", "les", "leur", "mais", "ne", "nous", "ou", "par", "pas", "plus", "pour",
+		"qu", "que", "qui", "sa", "se", "ses", "son", "sont", "sur", "un", "une", "vous", "été", "être",
+	),
+	"es": toSet(
+		"al", "como", "con", "de", "del", "el", "en", "es", "esta", "este", "está", "la", "las", "lo", "los",
+		"más", "no", "o", "para", "pero", "por", "que", "se", "sin", "sobre", "son", "su", "sus", "también",
+		"un", "una", "y", "ya",
+	),
+	"it": toSet(
+		"al", "alla", "anche", "che", "chi", "con", "come", "da", "dei", "del", "della", "di", "e", "è", "gli",
+		"il", "in", "la", "le", "lo", "ma", "nel", "nella", "non", "per", "più", "questo", "si", "sono", "su",
+		"sua", "suo", "tra", "un", "una",
+	),
+	"pt": toSet(
+		"ao", "as", "com", "como", "da", "das", "de", "do", "dos", "e", "é", "em", "mais", "mas", "na", "não",
+		"no", "nos", "o", "os", "ou", "para", "pela", "pelo", "por", "que", "se", "sem", "seu", "sua", "são",
+		"também", "um", "uma",
+	),
+	"nl": toSet(
+		"aan", "als", "bij", "dat", "de", "die", "dit", "door", "een", "en", "er", "het", "hij", "ik", "in",
+		"is", "je", "met", "naar", "niet", "of", "om", "ook", "op", "te", "tot", "uit", "van", "voor", "was",
+		"wat", "we", "wordt", "zijn",
+	),
+	"sv": toSet(
+		"att", "av", "de", "den", "det", "en", "ett", "för", "har", "i", "inte", "jag", "kan", "med", "men",
+		"och", "om", "på", "som", "till", "var", "vi", "är",
+	),
+}
+
+// scriptLanguages maps the writing systems used by a single language to its code.
+var scriptLanguages = []struct {
+	script   *unicode.RangeTable
+	language string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Thai, "th"},
+	{unicode.Devanagari, "hi"},
+}
+
+// DetectLanguage guesses the language of the text. Texts in a script used by one language are identified by the
+// script, Japanese before Chinese as it mixes kana with Han characters. Texts in the Latin script are identified by
+// their stopwords. It returns an empty string when the text is too short or no language stands out.
+func DetectLanguage(text string, words []string) string {
+	scripts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, candidate := range scriptLanguages {
+			if unicode.Is(candidate.script, r) {
+				scripts[candidate.language]++
+				break
+			}
+		}
+	}
+	if letters == 0 {
+		return constant.EMPTY
+	}
+	if scripts["ja"]*20 > letters && scripts["ja"]+scripts["zh"] > letters/2 {
+		return "ja"
+	}
+	for _, candidate := range scriptLanguages {
+		if scripts[candidate.language] > letters/2 {
+			return candidate.language
+		}
+	}
+
+	if len(words) < minLanguageWords {
+		return constant.EMPTY
+	}
+	best, bestHits, secondHits := constant.EMPTY, 0, 0
+	for language, stopwords := range languageStopwords {
+		hits := 0
+		for _, word := range words {
+			if stopwords[word] {
+				hits++
+			}
+		}
+		switch {
+		case hits > bestHits || (hits == bestHits && language < best):
+			best, bestHits, secondHits = language, hits, bestHits
+		case hits > secondHits:
+			secondHits = hits
+		}
+	}
+	// the stopwords of the language make up a good part of any running text and clearly more than the runner up
+	if bestHits*10 < len(words) || bestHits < secondHits*3/2 {
+		return constant.EMPTY
+	}
+	return best
+}
@@ -0,0 +1,189 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// sessionCookieRegex matches cookie names that usually carry a session or authentication token.
+var sessionCookieRegex = regexp.MustCompile(`(?i)(sess|^sid$|_sid$|auth|jwt|login|remember)`)
+
+// CookieAnalyzer implements the Analyzer interface for the cookies set on first load.
+type CookieAnalyzer struct{}
+
+// NewCookieAnalyzer creates a new CookieAnalyzer.
+func NewCookieAnalyzer() *CookieAnalyzer {
+	return &CookieAnalyzer{}
+}
+
+// Analyze lists the cookies set by the page, its redirects and, when link probing is on, third-party subresources.
+func (a *CookieAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing cookies function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("CookieAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+
+	report := &response.CookieReport{}
+	for _, redirect := range wc.Redirects {
+		report.Cookies = append(report.Cookies, ReadResponseCookies(redirect.Url, redirect.Headers, false)...)
+	}
+	report.Cookies = append(report.Cookies, ReadResponseCookies(pageURL, wc.Headers, false)...)
+
+	if configs.GetConfig().LinkProbe {
+		doc, err := wc.Document()
+		if err != nil {
+			return &response.ErrorResponse{
+				Message:  "Failed to parse HTML while analyzing cookies",
+				ErrorMsg: err.Error(),
+				Code:     http.StatusBadRequest,
+			}
+		}
+		var resources []Subresource
+		extractSubresources(doc, res.BasePath, &resources)
+		report.ThirdPartyProbed = true
+		report.Cookies = append(report.Cookies, probeThirdPartyCookies(probeClient(wc), resources, pageURL)...)
+	}
+
+	for _, cookie := range report.Cookies {
+		if cookie.ThirdParty {
+			report.ThirdPartyCount++
+		}
+		if len(cookie.Findings) > 0 {
+			report.FlaggedCount++
+		}
+	}
+	res.Cookies = report
+	return nil
+}
+
+// ReadResponseCookies parses the Set-Cookie headers of a response and audits each cookie's flags.
+func ReadResponseCookies(sourceUrl string, headers http.Header, thirdParty bool) []response.CookieInfo {
+	if headers == nil {
+		return nil
+	}
+	isHttps := strings.HasPrefix(strings.ToLower(sourceUrl), constant.HTTPS_SCHEME+"://")
+
+	var cookies []response.CookieInfo
+	for _, cookie := range (&http.Response{Header: headers}).Cookies() {
+		info := response.CookieInfo{
+			Name:       cookie.Name,
+			Domain:     cookie.Domain,
+			Path:       cookie.Path,
+			MaxAge:     cookie.MaxAge,
+			Session:    cookie.Expires.IsZero() && cookie.MaxAge == 0,
+			Secure:     cookie.Secure,
+			HttpOnly:   cookie.HttpOnly,
+			SameSite:   sameSiteName(cookie.SameSite),
+			SourceUrl:  sourceUrl,
+			ThirdParty: thirdParty,
+		}
+		if !cookie.Expires.IsZero() {
+			expires := cookie.Expires
+			info.Expires = &expires
+		}
+		if info.Domain == constant.EMPTY {
+			if parsedURL, err := url.Parse(sourceUrl); err == nil {
+				info.Domain = parsedURL.Hostname()
+			}
+		}
+		info.Findings = auditCookie(info, isHttps)
+		cookies = append(cookies, info)
+	}
+	return cookies
+}
+
+// auditCookie flags missing Secure, HttpOnly and SameSite protections.
+func auditCookie(cookie response.CookieInfo, isHttps bool) []response.Finding {
+	var findings []response.Finding
+	if isHttps && !cookie.Secure {
+		findings = append(findings, newFinding("cookie-not-secure", constant.SEVERITY_WARNING,
+			"Cookie "+cookie.Name+" is set over HTTPS without the Secure flag"))
+	}
+	if sessionCookieRegex.MatchString(cookie.Name) && !cookie.HttpOnly {
+		findings = append(findings, newFinding("session-cookie-not-httponly", constant.SEVERITY_WARNING,
+			"Session cookie "+cookie.Name+" is readable from JavaScript because HttpOnly is missing"))
+	}
+	if cookie.SameSite == "None" && !cookie.Secure {
+		findings = append(findings, newFinding("samesite-none-not-secure", constant.SEVERITY_ERROR,
+			"Cookie "+cookie.Name+" uses SameSite=None without Secure and is rejected by browsers"))
+	}
+	if cookie.ThirdParty {
+		findings = append(findings, newFinding("third-party-cookie", constant.SEVERITY_INFO,
+			"Cookie "+cookie.Name+" is set by a third-party subresource"))
+	}
+	return findings
+}
+
+// probeThirdPartyCookies requests each cross-site subresource once, with a bounded number of concurrent requests,
+// and collects the cookies they set in the order of the subresources.
+func probeThirdPartyCookies(client *http.Client, resources []Subresource, pageURL string) []response.CookieInfo {
+	seen := make(map[string]bool)
+	var probed []string
+	found := make(map[string][]response.CookieInfo)
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentProbes)
+
+	for _, resource := range resources {
+		if seen[resource.Url] || !isThirdParty(resource.Url, pageURL) {
+			continue
+		}
+		seen[resource.Url] = true
+		probed = append(probed, resource.Url)
+
+		wg.Add(1)
+		go func(link string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			resp, err := probeGet(client, link)
+			if err != nil {
+				log.Printf("Failed probing subresource for cookies: %s | Error: %v", link, err)
+				return
+			}
+			resp.Body.Close()
+
+			cookies := ReadResponseCookies(link, resp.Header, true)
+			mu.Lock()
+			found[link] = cookies
+			mu.Unlock()
+		}(resource.Url)
+	}
+	wg.Wait()
+
+	var cookies []response.CookieInfo
+	for _, link := range probed {
+		cookies = append(cookies, found[link]...)
+	}
+	return cookies
+}
+
+func sameSiteName(mode http.SameSite) string {
+	switch mode {
+	case http.SameSiteLaxMode:
+		return "Lax"
+	case http.SameSiteStrictMode:
+		return "Strict"
+	case http.SameSiteNoneMode:
+		return "None"
+	default:
+		return constant.EMPTY
+	}
+}
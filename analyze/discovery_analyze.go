@@ -0,0 +1,312 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// serviceWorkerRegex matches the registration of a service worker and captures its script URL.
+	serviceWorkerRegex = regexp.MustCompile("serviceWorker\\s*\\.\\s*register\\(\\s*['\"`]([^'\"`]+)['\"`]")
+	// serviceWorkerCheckRegex matches the feature detection written before a registration built at run time.
+	serviceWorkerCheckRegex = regexp.MustCompile(`['"]serviceWorker['"]\s+in\s+navigator|navigator\.serviceWorker`)
+)
+
+// feedTypes maps the media types of feed links to the format they announce.
+var feedTypes = map[string]string{
+	"application/rss+xml":   constant.FEED_RSS,
+	"application/rdf+xml":   constant.FEED_RDF,
+	"application/atom+xml":  constant.FEED_ATOM,
+	"application/feed+json": constant.FEED_JSON,
+}
+
+// iconRels lists the link relations of the icons browsers and home screens use for the page.
+var iconRels = []string{"icon", "apple-touch-icon", "apple-touch-icon-precomposed", "mask-icon"}
+
+// installableDisplays lists the manifest display modes that open the app in its own window.
+var installableDisplays = toSet("fullscreen", "standalone", "minimal-ui")
+
+// DiscoveryAnalyzer implements the Analyzer interface for the feeds, manifest and other resources a page
+// advertises through its links.
+type DiscoveryAnalyzer struct{}
+
+// NewDiscoveryAnalyzer creates a new DiscoveryAnalyzer.
+func NewDiscoveryAnalyzer() *DiscoveryAnalyzer {
+	return &DiscoveryAnalyzer{}
+}
+
+// Analyze lists the discovery links of the page, fetches and validates its feeds and manifest and reports the
+// progressive web app checklist.
+func (a *DiscoveryAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing discovery links function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("DiscoveryAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing discovery links",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	report := ExtractDiscoveryLinks(doc, pageURL)
+	cfg := configs.GetConfig()
+	if cfg.LinkProbe {
+		fetchDiscoveryResources(probeClient(wc), report)
+	}
+	AuditDiscovery(doc, pageURL, report)
+	res.Discovery = report
+	return nil
+}
+
+// ExtractDiscoveryLinks lists the feeds, manifest, icons, rel=me profiles, OpenSearch description and AMP version
+// linked from the page and the service worker registrations of its inline scripts.
+func ExtractDiscoveryLinks(doc *html.Node, pageURL string) *response.DiscoveryReport {
+	report := &response.DiscoveryReport{}
+	seenFeeds := make(map[string]bool)
+
+	for _, n := range findElements(doc, "link", "a") {
+		rel := getAttr(n, "rel")
+		link := resolveURL(strings.TrimSpace(getAttr(n, constant.H_REF)), pageURL)
+		if link == constant.EMPTY {
+			continue
+		}
+		if hasToken(rel, "me") {
+			report.Me = appendUnique(report.Me, link)
+		}
+		if n.Data != "link" {
+			continue
+		}
+		mediaType := strings.ToLower(strings.TrimSpace(getAttr(n, "type")))
+
+		switch {
+		case hasToken(rel, "alternate") && feedTypes[mediaType] != constant.EMPTY:
+			if !seenFeeds[link] {
+				seenFeeds[link] = true
+				report.Feeds = append(report.Feeds, response.FeedInfo{
+					Url:   link,
+					Title: normalizeSpace(getAttr(n, "title")),
+					Type:  mediaType,
+				})
+			}
+		case hasToken(rel, "amphtml"):
+			if report.AmpUrl == constant.EMPTY {
+				report.AmpUrl = link
+			}
+		case hasToken(rel, "manifest"):
+			if report.Manifest == nil {
+				report.Manifest = &response.ManifestInfo{Url: link}
+			} else if report.Manifest.Url != link {
+				report.Findings = append(report.Findings, newFinding("manifest-multiple", constant.SEVERITY_WARNING,
+					"Only the first manifest link is used, ignoring "+link))
+			}
+		case hasToken(rel, "search") && mediaType == "application/opensearchdescription+xml":
+			if report.Search == nil {
+				report.Search = &response.OpenSearchLink{Url: link, Title: normalizeSpace(getAttr(n, "title"))}
+			}
+		case hasToken(rel, "serviceworker"):
+			report.ServiceWorker.ScriptUrl = link
+			report.ServiceWorker.Hints = append(report.ServiceWorker.Hints, "<link rel=serviceworker> "+link)
+		}
+		for _, iconRel := range iconRels {
+			if hasToken(rel, iconRel) {
+				report.Icons = append(report.Icons, response.IconLink{
+					Url:   link,
+					Rel:   iconRel,
+					Sizes: strings.ToLower(strings.TrimSpace(getAttr(n, "sizes"))),
+					Type:  mediaType,
+				})
+				break
+			}
+		}
+	}
+
+	for _, n := range findElements(doc, "html") {
+		report.IsAmp = hasAttr(n, "amp") || hasAttr(n, "⚡")
+	}
+	findServiceWorker(doc, pageURL, &report.ServiceWorker)
+	return report
+}
+
+// findServiceWorker looks for service worker registrations in the inline scripts and for service worker libraries
+// among the external ones. Registrations made by external scripts are not seen.
+func findServiceWorker(doc *html.Node, pageURL string, hint *response.ServiceWorkerHint) {
+	for _, script := range findElements(doc, "script") {
+		if src := getAttr(script, constant.SRC); src != constant.EMPTY {
+			if strings.Contains(strings.ToLower(src), "workbox") {
+				hint.Hints = append(hint.Hints, "Workbox script "+src)
+			}
+			continue
+		}
+		code := nodeText(script)
+		if match := serviceWorkerRegex.FindStringSubmatch(code); match != nil {
+			hint.Registered = true
+			if hint.ScriptUrl == constant.EMPTY {
+				hint.ScriptUrl = resolveURL(match[1], pageURL)
+			}
+			hint.Hints = append(hint.Hints, "navigator.serviceWorker.register call")
+		} else if serviceWorkerCheckRegex.MatchString(code) {
+			hint.Hints = append(hint.Hints, "navigator.serviceWorker feature detection")
+		}
+	}
+	if hint.ScriptUrl != constant.EMPTY {
+		hint.Registered = true
+	}
+}
+
+// AuditDiscovery reports broken, invalid and empty feeds, a broken or invalid manifest and a missing favicon, and
+// fills the progressive web app checklist.
+func AuditDiscovery(doc *html.Node, pageURL string, report *response.DiscoveryReport) {
+	for _, feed := range report.Feeds {
+		switch {
+		case feed.Status != 0 && feed.Status != http.StatusOK:
+			report.Findings = append(report.Findings, newFinding("feed-broken", constant.SEVERITY_ERROR,
+				fmt.Sprintf("Feed %s returned status %d", feed.Url, feed.Status)))
+		case feed.Error != constant.EMPTY:
+			report.Findings = append(report.Findings, newFinding("feed-invalid", constant.SEVERITY_ERROR,
+				fmt.Sprintf("Feed %s could not be parsed: %s", feed.Url, feed.Error)))
+		case feed.Valid && feed.Items == 0:
+			report.Findings = append(report.Findings, newFinding("feed-empty", constant.SEVERITY_WARNING,
+				"Feed has no items: "+feed.Url))
+		}
+		if feed.Valid && feed.Format != feedTypes[feed.Type] &&
+			!(feed.Format == constant.FEED_RDF && feed.Type == "application/rss+xml") {
+			report.Findings = append(report.Findings, newFinding("feed-type-mismatch", constant.SEVERITY_WARNING,
+				fmt.Sprintf("Feed %s is linked as %s but is a %s feed", feed.Url, feed.Type, feed.Format)))
+		}
+	}
+
+	if manifest := report.Manifest; manifest != nil {
+		switch {
+		case manifest.Status != 0 && manifest.Status != http.StatusOK:
+			report.Findings = append(report.Findings, newFinding("manifest-broken", constant.SEVERITY_ERROR,
+				fmt.Sprintf("Manifest %s returned status %d", manifest.Url, manifest.Status)))
+		case manifest.Error != constant.EMPTY:
+			report.Findings = append(report.Findings, newFinding("manifest-invalid", constant.SEVERITY_ERROR,
+				fmt.Sprintf("Manifest %s is not valid JSON: %s", manifest.Url, manifest.Error)))
+		}
+	}
+
+	hasFavicon := false
+	for _, icon := range report.Icons {
+		hasFavicon = hasFavicon || icon.Rel == "icon"
+	}
+	if !hasFavicon {
+		report.Findings = append(report.Findings, newFinding("favicon-missing", constant.SEVERITY_INFO,
+			"No <link rel=icon>, browsers fall back to requesting /favicon.ico"))
+	}
+
+	report.Pwa = pwaChecklist(doc, pageURL, report)
+}
+
+// pwaChecklist checks the installability criteria of a progressive web app. The app is ready when every required
+// check passes.
+func pwaChecklist(doc *html.Node, pageURL string, report *response.DiscoveryReport) response.PwaReport {
+	pwa := response.PwaReport{Ready: true}
+	check := func(name string, required, passed bool, detail string) {
+		pwa.Checks = append(pwa.Checks, response.PwaCheck{Name: name, Passed: passed, Required: required, Detail: detail})
+		if required && !passed {
+			pwa.Ready = false
+		}
+	}
+
+	secure := false
+	if parsedURL, err := url.Parse(pageURL); err == nil {
+		host := parsedURL.Hostname()
+		secure = parsedURL.Scheme == constant.HTTPS_SCHEME || host == "localhost" || host == "127.0.0.1"
+	}
+	check("https", true, secure, "The page must be served over HTTPS")
+
+	manifest := report.Manifest
+	switch {
+	case manifest == nil:
+		check("manifest", true, false, "No <link rel=manifest>")
+	case !manifest.Valid:
+		detail := "The manifest could not be loaded"
+		if manifest.Status == 0 && manifest.Error == constant.EMPTY {
+			detail = "The manifest was not fetched, link probing is disabled"
+		}
+		check("manifest", true, false, detail)
+	default:
+		check("manifest", true, true, manifest.Url)
+	}
+
+	valid := manifest != nil && manifest.Valid
+	check("manifest-name", true, valid && (manifest.Name != constant.EMPTY || manifest.ShortName != constant.EMPTY),
+		"The manifest needs a name or short_name")
+	check("manifest-start-url", true, valid && manifest.StartUrl != constant.EMPTY,
+		"The manifest needs a start_url")
+	check("manifest-display", true, valid && installableDisplays[manifest.Display],
+		"The manifest display must be fullscreen, standalone or minimal-ui")
+	has192, has512 := false, false
+	if valid {
+		has192, has512 = manifestIconSizes(manifest.Icons)
+	}
+	check("manifest-icons", true, has192 && has512, "The manifest needs icons of at least 192px and 512px")
+
+	check("service-worker", true, report.ServiceWorker.Registered,
+		"No service worker registration found in the inline scripts")
+
+	viewport, themeColor := false, valid && manifest.ThemeColor != constant.EMPTY
+	for _, meta := range findElements(doc, "meta") {
+		switch strings.ToLower(getAttr(meta, "name")) {
+		case "viewport":
+			viewport = viewport || strings.Contains(strings.ReplaceAll(getAttr(meta, "content"), " ", ""),
+				"width=device-width")
+		case "theme-color":
+			themeColor = themeColor || strings.TrimSpace(getAttr(meta, "content")) != constant.EMPTY
+		}
+	}
+	check("viewport", true, viewport, "A <meta name=viewport> with width=device-width is needed")
+	check("theme-color", false, themeColor, "A theme color in the manifest or <meta name=theme-color>")
+
+	appleIcon := false
+	for _, icon := range report.Icons {
+		appleIcon = appleIcon || strings.HasPrefix(icon.Rel, "apple-touch-icon")
+	}
+	check("apple-touch-icon", false, appleIcon, "iOS uses <link rel=apple-touch-icon> for the home screen")
+	return pwa
+}
+
+// manifestIconSizes reports whether the manifest has icons of at least 192 and 512 pixels. Scalable icons declared
+// with the size any fit both.
+func manifestIconSizes(icons []response.ManifestIcon) (bool, bool) {
+	largest := 0
+	for _, icon := range icons {
+		for _, size := range strings.Fields(strings.ToLower(icon.Sizes)) {
+			if size == "any" {
+				return true, true
+			}
+			width, height, found := strings.Cut(size, "x")
+			if !found {
+				continue
+			}
+			w, errW := strconv.Atoi(width)
+			h, errH := strconv.Atoi(height)
+			if errW == nil && errH == nil {
+				largest = max(largest, min(w, h))
+			}
+		}
+	}
+	return largest >= 192, largest >= 512
+}
@@ -0,0 +1,265 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html/charset"
+)
+
+// maxDiscoveryBytes bounds how much of a feed or manifest is read.
+const maxDiscoveryBytes = 2 * 1024 * 1024
+
+// feedDateLayouts lists the date formats of RSS (RFC 822 and its common variants) and Atom (RFC 3339).
+var feedDateLayouts = []string{
+	time.RFC1123Z, time.RFC1123, time.RFC3339, time.RFC3339Nano, time.RFC822Z, time.RFC822,
+	"Mon, 2 Jan 2006 15:04:05 -0700", "Mon, 2 Jan 2006 15:04:05 MST", "2 Jan 2006 15:04:05 -0700",
+	"Mon, 02 Jan 2006 15:04 -0700", "2006-01-02T15:04:05", "2006-01-02",
+}
+
+// xmlFeed covers RSS 2.0, RSS 1.0 (RDF) and Atom documents. Elements are matched by their local name.
+type xmlFeed struct {
+	XMLName xml.Name
+	Channel struct {
+		Title         string      `xml:"title"`
+		LastBuildDate string      `xml:"lastBuildDate"`
+		PubDate       string      `xml:"pubDate"`
+		Date          string      `xml:"date"`
+		Items         []feedEntry `xml:"item"`
+	} `xml:"channel"`
+	Title   string      `xml:"title"`
+	Updated string      `xml:"updated"`
+	Items   []feedEntry `xml:"item"`
+	Entries []feedEntry `xml:"entry"`
+}
+
+// feedEntry holds the dates of an RSS item or Atom entry.
+type feedEntry struct {
+	PubDate   string `xml:"pubDate"`
+	Date      string `xml:"date"`
+	Updated   string `xml:"updated"`
+	Published string `xml:"published"`
+}
+
+// jsonFeed is a JSON Feed document.
+type jsonFeed struct {
+	Version string `json:"version"`
+	Title   string `json:"title"`
+	Items   []struct {
+		DatePublished string `json:"date_published"`
+		DateModified  string `json:"date_modified"`
+	} `json:"items"`
+}
+
+// webManifest is the part of a web app manifest the checklist needs.
+type webManifest struct {
+	Name            string                  `json:"name"`
+	ShortName       string                  `json:"short_name"`
+	StartUrl        string                  `json:"start_url"`
+	Scope           string                  `json:"scope"`
+	Display         string                  `json:"display"`
+	ThemeColor      string                  `json:"theme_color"`
+	BackgroundColor string                  `json:"background_color"`
+	Icons           []response.ManifestIcon `json:"icons"`
+}
+
+// fetchDiscoveryResources fetches the feeds and the manifest of the report concurrently and fills in what they
+// contain.
+func fetchDiscoveryResources(client *http.Client, report *response.DiscoveryReport) {
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentProbes)
+	run := func(task func()) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			task()
+		}()
+	}
+
+	for i := range report.Feeds {
+		feed := &report.Feeds[i]
+		run(func() { fetchFeed(client, feed) })
+	}
+	if report.Manifest != nil {
+		run(func() { fetchManifest(client, report.Manifest) })
+	}
+	wg.Wait()
+}
+
+// fetchDocument requests link and returns its status, media type and the first maxDiscoveryBytes of its body.
+func fetchDocument(client *http.Client, link, accept string) (int, string, []byte, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return 0, constant.EMPTY, nil, err
+	}
+	req.Header.Set("Accept", accept)
+	resp, err := sendProbe(client, req)
+	if err != nil {
+		return 0, constant.EMPTY, nil, err
+	}
+	defer resp.Body.Close()
+
+	mediaType := strings.ToLower(strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0]))
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, mediaType, nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxDiscoveryBytes))
+	return resp.StatusCode, mediaType, body, err
+}
+
+// fetchFeed requests the feed and records its format, title, number of items and last update.
+func fetchFeed(client *http.Client, feed *response.FeedInfo) {
+	status, mediaType, body, err := fetchDocument(client, feed.Url, feed.Type+", */*;q=0.5")
+	feed.Status = status
+	if err != nil {
+		log.Printf("Failed fetching feed: %s | Error: %v", feed.Url, err)
+		feed.Error = err.Error()
+		return
+	}
+	if status != http.StatusOK {
+		return
+	}
+	if err := ParseFeed(body, mediaType, feed); err != nil {
+		feed.Error = err.Error()
+		return
+	}
+	feed.Valid = true
+}
+
+// ParseFeed parses an RSS, Atom or JSON feed. The format is read from the root element, JSON feeds are recognized
+// by their media type or by starting with a brace.
+func ParseFeed(body []byte, mediaType string, feed *response.FeedInfo) error {
+	trimmed := bytes.TrimSpace(bytes.TrimPrefix(body, []byte("\uFEFF")))
+	if strings.Contains(mediaType, "json") || bytes.HasPrefix(trimmed, []byte("{")) {
+		return parseJSONFeed(trimmed, feed)
+	}
+
+	decoder := xml.NewDecoder(bytes.NewReader(trimmed))
+	decoder.CharsetReader = charset.NewReaderLabel
+	var parsed xmlFeed
+	if err := decoder.Decode(&parsed); err != nil {
+		return err
+	}
+
+	var entries []feedEntry
+	var dates []string
+	switch parsed.XMLName.Local {
+	case "rss":
+		feed.Format, feed.FeedTitle = constant.FEED_RSS, parsed.Channel.Title
+		entries = parsed.Channel.Items
+		dates = append(dates, parsed.Channel.LastBuildDate, parsed.Channel.PubDate)
+	case "RDF":
+		feed.Format, feed.FeedTitle = constant.FEED_RDF, parsed.Channel.Title
+		entries = parsed.Items
+		dates = append(dates, parsed.Channel.Date)
+	case "feed":
+		feed.Format, feed.FeedTitle = constant.FEED_ATOM, parsed.Title
+		entries = parsed.Entries
+		dates = append(dates, parsed.Updated)
+	default:
+		return fmt.Errorf("unknown root element <%s>", parsed.XMLName.Local)
+	}
+
+	feed.FeedTitle = normalizeSpace(feed.FeedTitle)
+	feed.Items = len(entries)
+	for _, entry := range entries {
+		dates = append(dates, entry.PubDate, entry.Date, entry.Updated, entry.Published)
+	}
+	feed.LastUpdated = latestDate(dates)
+	return nil
+}
+
+// parseJSONFeed parses a JSON Feed, which is identified by its version URL.
+func parseJSONFeed(body []byte, feed *response.FeedInfo) error {
+	var parsed jsonFeed
+	if err := json.Unmarshal(body, &parsed); err != nil {
+		return err
+	}
+	if !strings.HasPrefix(parsed.Version, "https://jsonfeed.org/version/") {
+		return fmt.Errorf("missing JSON Feed version")
+	}
+	feed.Format, feed.FeedTitle, feed.Items = constant.FEED_JSON, normalizeSpace(parsed.Title), len(parsed.Items)
+	var dates []string
+	for _, item := range parsed.Items {
+		dates = append(dates, item.DatePublished, item.DateModified)
+	}
+	feed.LastUpdated = latestDate(dates)
+	return nil
+}
+
+// latestDate returns the most recent of the dates that parse, in UTC.
+func latestDate(values []string) *time.Time {
+	var latest *time.Time
+	for _, value := range values {
+		value = strings.TrimSpace(value)
+		if value == constant.EMPTY {
+			continue
+		}
+		for _, layout := range feedDateLayouts {
+			if parsed, err := time.Parse(layout, value); err == nil {
+				parsed = parsed.UTC()
+				if latest == nil || parsed.After(*latest) {
+					latest = &parsed
+				}
+				break
+			}
+		}
+	}
+	return latest
+}
+
+// fetchManifest requests the web app manifest and records its members, resolving its URLs against the manifest.
+func fetchManifest(client *http.Client, manifest *response.ManifestInfo) {
+	status, _, body, err := fetchDocument(client, manifest.Url, "application/manifest+json, application/json")
+	manifest.Status = status
+	if err != nil {
+		log.Printf("Failed fetching manifest: %s | Error: %v", manifest.Url, err)
+		manifest.Error = err.Error()
+		return
+	}
+	if status != http.StatusOK {
+		return
+	}
+	if err := ParseManifest(body, manifest); err != nil {
+		manifest.Error = err.Error()
+		return
+	}
+	manifest.Valid = true
+}
+
+// ParseManifest parses the JSON of a web app manifest into manifest.
+func ParseManifest(body []byte, manifest *response.ManifestInfo) error {
+	var parsed webManifest
+	if err := json.Unmarshal(bytes.TrimPrefix(body, []byte("\uFEFF")), &parsed); err != nil {
+		return err
+	}
+	manifest.Name = strings.TrimSpace(parsed.Name)
+	manifest.ShortName = strings.TrimSpace(parsed.ShortName)
+	manifest.Display = strings.ToLower(strings.TrimSpace(parsed.Display))
+	manifest.ThemeColor = strings.TrimSpace(parsed.ThemeColor)
+	manifest.BackgroundColor = strings.TrimSpace(parsed.BackgroundColor)
+	if parsed.StartUrl != constant.EMPTY {
+		manifest.StartUrl = resolveURL(parsed.StartUrl, manifest.Url)
+	}
+	if parsed.Scope != constant.EMPTY {
+		manifest.Scope = resolveURL(parsed.Scope, manifest.Url)
+	}
+	for _, icon := range parsed.Icons {
+		if icon.Src = resolveURL(strings.TrimSpace(icon.Src), manifest.Url); icon.Src != constant.EMPTY {
+			manifest.Icons = append(manifest.Icons, icon)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package analyze
+
+import (
+	"api/constant"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	formControlTags = []string{"input", "select", "textarea"}
+	formButtonTags  = []string{"button", "input", "a"}
+)
+
+// findElements returns the descendant elements of n with one of the given tag names in document order.
+func findElements(n *html.Node, tags ...string) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode {
+				for _, tag := range tags {
+					if child.Data == tag {
+						found = append(found, child)
+						break
+					}
+				}
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// findElementsFunc returns the descendant elements of n for which match returns true in document order.
+func findElementsFunc(n *html.Node, match func(*html.Node) bool) []*html.Node {
+	var found []*html.Node
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type == html.ElementNode && match(child) {
+				found = append(found, child)
+			}
+			walk(child)
+		}
+	}
+	walk(n)
+	return found
+}
+
+// getAttr returns the value of the attribute key of n, or an empty string when it is not set.
+func getAttr(n *html.Node, key string) string {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return attr.Val
+		}
+	}
+	return ""
+}
+
+// nodeText returns the concatenated text of n and its descendants.
+func nodeText(n *html.Node) string {
+	var text strings.Builder
+	var walk func(*html.Node)
+	walk = func(node *html.Node) {
+		if node.Type == html.TextNode {
+			text.WriteString(node.Data)
+		}
+		for child := node.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(n)
+	return text.String()
+}
+
+// IsHTMLMediaType reports whether a page with the media type can be analyzed. A missing type is sniffed as HTML.
+func IsHTMLMediaType(mediaType string) bool {
+	return mediaType == constant.EMPTY || mediaType == "text/html" || mediaType == "application/xhtml+xml"
+}
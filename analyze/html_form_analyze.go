@@ -0,0 +1,204 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/antchfx/htmlquery"
+	"golang.org/x/net/html"
+)
+
+// csrfFieldRegex matches the hidden input names commonly used for anti-CSRF tokens.
+var csrfFieldRegex = regexp.MustCompile(`(?i)(csrf|xsrf|authenticity_token|requestverificationtoken|^_token$|form_key|nonce)`)
+
+// captchaMarkers maps the captcha providers to the class names and URL fragments of their widgets.
+var captchaMarkers = map[string][]string{
+	"reCAPTCHA":         {"g-recaptcha", "google.com/recaptcha", "recaptcha.net"},
+	"hCaptcha":          {"h-captcha", "hcaptcha.com"},
+	"Turnstile":         {"cf-turnstile", "challenges.cloudflare.com/turnstile"},
+	"Friendly Captcha":  {"frc-captcha", "friendlycaptcha.com"},
+	"Arkose FunCaptcha": {"funcaptcha", "arkoselabs.com"},
+}
+
+// HtmlFormAnalyzer implements the Analyzer interface for the inventory of every HTML form.
+type HtmlFormAnalyzer struct{}
+
+// NewHtmlFormAnalyzer creates a new HtmlFormAnalyzer.
+func NewHtmlFormAnalyzer() *HtmlFormAnalyzer {
+	return &HtmlFormAnalyzer{}
+}
+
+// Analyze lists every form of the page with its fields, CSRF token, captcha widgets and file uploads.
+func (a *HtmlFormAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing HTML forms function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("HtmlFormAnalyzer.Analyze succesfully completed in %v", time.Since(start))
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Parser error while analyze forms",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	labels := collectLabels(doc)
+	for _, form := range htmlquery.Find(doc, constant.FORM_TAG_EXP) {
+		res.Forms = append(res.Forms, InspectForm(doc, form, pageURL, labels))
+	}
+	return nil
+}
+
+// InspectForm builds the inventory entry of a single form. labels maps element ids to their <label for> text.
+func InspectForm(doc, form *html.Node, pageURL string, labels map[string]string) response.FormInfo {
+	info := response.FormInfo{
+		Action:  resolveFormAction(htmlquery.SelectAttr(form, "action"), pageURL),
+		Method:  strings.ToUpper(htmlquery.SelectAttr(form, "method")),
+		Enctype: strings.ToLower(htmlquery.SelectAttr(form, "enctype")),
+	}
+	if info.Method == constant.EMPTY {
+		info.Method = http.MethodGet
+	}
+	if info.Enctype == constant.EMPTY {
+		info.Enctype = "application/x-www-form-urlencoded"
+	}
+
+	for _, control := range findElements(form, formControlTags...) {
+		field := response.FormField{
+			Name:         htmlquery.SelectAttr(control, "name"),
+			Tag:          control.Data,
+			Type:         strings.ToLower(htmlquery.SelectAttr(control, "type")),
+			Required:     htmlquery.ExistsAttr(control, "required"),
+			Autocomplete: htmlquery.SelectAttr(control, "autocomplete"),
+			Label:        fieldLabel(doc, control, labels),
+		}
+		if field.Tag == "input" && field.Type == constant.EMPTY {
+			field.Type = "text"
+		}
+		switch field.Type {
+		case "submit", "button", "reset", "image":
+			continue
+		case "hidden":
+			if info.CsrfToken == constant.EMPTY && csrfFieldRegex.MatchString(field.Name) {
+				info.CsrfToken = field.Name
+			}
+		case "file":
+			info.FileUploads = append(info.FileUploads, field.Name)
+		}
+		info.Fields = append(info.Fields, field)
+	}
+	info.Captchas = detectCaptchas(form)
+
+	if actionURL, err := url.Parse(info.Action); err == nil {
+		info.InsecureAction = actionURL.Scheme == constant.HTTP_SCHEME
+	}
+	info.ThirdPartyAction = isThirdParty(info.Action, pageURL)
+	info.Findings = auditForm(info)
+	return info
+}
+
+// auditForm flags forms submitting to another site or over plain HTTP and common misconfigurations.
+func auditForm(info response.FormInfo) []response.Finding {
+	var findings []response.Finding
+	if info.ThirdPartyAction {
+		findings = append(findings, newFinding("form-third-party-action", constant.SEVERITY_WARNING,
+			"Form submits to a third-party origin: "+info.Action))
+	}
+	if info.InsecureAction {
+		findings = append(findings, newFinding("form-insecure-action", constant.SEVERITY_ERROR,
+			"Form submits over plain HTTP: "+info.Action))
+	}
+	if len(info.FileUploads) > 0 && info.Enctype != "multipart/form-data" {
+		findings = append(findings, newFinding("form-file-upload-enctype", constant.SEVERITY_WARNING,
+			"Form has file upload fields but does not use multipart/form-data"))
+	}
+	if info.Method == http.MethodPost && info.CsrfToken == constant.EMPTY {
+		findings = append(findings, newFinding("form-no-csrf-token", constant.SEVERITY_INFO,
+			"POST form has no CSRF token looking hidden field"))
+	}
+	return findings
+}
+
+// collectLabels maps element ids to the text of the <label for> elements pointing at them.
+func collectLabels(doc *html.Node) map[string]string {
+	labels := make(map[string]string)
+	for _, label := range findElements(doc, "label") {
+		if target := htmlquery.SelectAttr(label, "for"); target != constant.EMPTY {
+			labels[target] = normalizeSpace(htmlquery.InnerText(label))
+		}
+	}
+	return labels
+}
+
+// fieldLabel resolves the label of a form control from aria-label, <label for>, a wrapping <label> or aria-labelledby.
+func fieldLabel(doc, control *html.Node, labels map[string]string) string {
+	if ariaLabel := normalizeSpace(htmlquery.SelectAttr(control, "aria-label")); ariaLabel != constant.EMPTY {
+		return ariaLabel
+	}
+	if id := htmlquery.SelectAttr(control, "id"); id != constant.EMPTY {
+		if label, ok := labels[id]; ok {
+			return label
+		}
+	}
+	for parent := control.Parent; parent != nil; parent = parent.Parent {
+		if parent.Type == html.ElementNode && parent.Data == "label" {
+			return normalizeSpace(htmlquery.InnerText(parent))
+		}
+	}
+
+	var parts []string
+	for _, id := range strings.Fields(htmlquery.SelectAttr(control, "aria-labelledby")) {
+		labelled := findElementsFunc(doc, func(n *html.Node) bool { return htmlquery.SelectAttr(n, "id") == id })
+		if len(labelled) > 0 {
+			parts = append(parts, normalizeSpace(htmlquery.InnerText(labelled[0])))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// detectCaptchas finds the captcha widgets rendered inside the form.
+func detectCaptchas(form *html.Node) []string {
+	found := make(map[string]bool)
+	for _, node := range findElementsFunc(form, isCaptchaCandidate) {
+		markers := strings.ToLower(htmlquery.SelectAttr(node, "class") + " " + htmlquery.SelectAttr(node, constant.SRC))
+		for provider, fragments := range captchaMarkers {
+			for _, fragment := range fragments {
+				if strings.Contains(markers, fragment) {
+					found[provider] = true
+				}
+			}
+		}
+	}
+
+	captchas := make([]string, 0, len(found))
+	for provider := range found {
+		captchas = append(captchas, provider)
+	}
+	sort.Strings(captchas)
+	return captchas
+}
+
+// isCaptchaCandidate reports whether the element may render a captcha: a classed element, an iframe or a script.
+func isCaptchaCandidate(n *html.Node) bool {
+	return n.Data == "iframe" || n.Data == "script" || htmlquery.ExistsAttr(n, "class")
+}
+
+// normalizeSpace trims the text and collapses inner whitespace runs into single spaces.
+func normalizeSpace(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
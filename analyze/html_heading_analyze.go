@@ -1,18 +1,24 @@
 package analyze
 
 import (
+	"api/constant"
 	"api/response"
+	"fmt"
 	"io"
 	"log"
 	"net/http"
 	"regexp"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"golang.org/x/net/html"
 )
 
-const headingHTMLTagRegex = `h[1-6]`
+const (
+	headingHTMLTagRegex = `^h[1-6]$`
+	maxHeadingLength    = 70
+)
 
 // HtmlHeadingAnalyzer implements the Analyzer interface for HTML headings.
 type HtmlHeadingAnalyzer struct{}
@@ -45,57 +51,91 @@ func (a *HtmlHeadingAnalyzer) Analyze(wc *response.WebContent, res *response.Suc
 	return ValidateHeaderTokenHandler(metaData, regex, res, startTime)
 }
 
+// ValidateHeaderTokenHandler collects every heading with its full text, then builds the per level
+// counts, the nested outline and the hierarchy findings.
 func ValidateHeaderTokenHandler(metaData *html.Tokenizer, regex *regexp.Regexp, res *response.SuccessResponse, startTime time.Time) *response.ErrorResponse {
-OuterLoop:
+	// next holds a heading start tag that ended the previous heading
+	var next *html.Token
 	for {
-		switch metaData.Next() {
-		case html.StartTagToken:
-			token, match := ExactRegexPatternAndToken(metaData, regex)
-			if match {
-				// scan next token and return
-				metaData.Next()
-				tempToken := metaData.Token()
-				if tempToken.Type == html.TextToken {
-					SetHeadingDataToResponse(tempToken, res, token)
-				} else {
-					// handle deeper nested or multiline text content
-					for {
-						switch metaData.Next() {
-						case html.TextToken:
-							SetHeadingDataToResponse(tempToken, res, token)
-							// break out of the outer loop
-							break OuterLoop
-						case html.ErrorToken:
-							err := metaData.Err()
-							// EOF mean no more input is available
-							if err == io.EOF {
-								break OuterLoop
-							}
-							log.Printf("HTML tokenizer error: %v", err)
-							// break out of the outer loop
-							break OuterLoop
-						}
-					}
+		token := next
+		if token == nil {
+			tokenType := metaData.Next()
+			if tokenType == html.ErrorToken {
+				if err := metaData.Err(); err != io.EOF {
+					log.Printf("HTML tokenizer error: %v", err)
 				}
+				break
 			}
-
-		case html.ErrorToken:
-			err := metaData.Err()
-			if err == io.EOF {
-				break OuterLoop
+			if tokenType != html.StartTagToken {
+				continue
+			}
+			tagToken, match := ExactRegexPatternAndToken(metaData, regex)
+			if !match {
+				continue
 			}
-			log.Printf("HTML tokenizer error: %v", err)
-			break OuterLoop
+			token = &tagToken
 		}
+
+		var text string
+		text, next = readHeadingText(metaData, regex)
+		SetHeadingDataToResponse(html.Token{Type: html.TextToken, Data: text}, res, *token)
 	}
+
+	res.HeadingCounts = CountHeadings(res.Headings)
+	res.HeadingOutline = BuildHeadingOutline(res.Headings)
+	res.HeadingFindings = AuditHeadings(res.Headings, res.HeadingCounts)
 	log.Printf("Analyzing HTML Headings succesfully completed in %d ms", time.Since(startTime).Milliseconds())
 	return nil
 }
 
+// readHeadingText reads the tokens up to the end of the heading and joins their text, including image alt text.
+// Any heading end tag ends the heading, and a new heading start tag too, the same way the HTML parser closes it,
+// and is returned.
+func readHeadingText(metaData *html.Tokenizer, regex *regexp.Regexp) (string, *html.Token) {
+	var text strings.Builder
+	for {
+		switch metaData.Next() {
+		case html.ErrorToken:
+			return normalizeSpace(text.String()), nil
+		case html.TextToken:
+			text.Write(metaData.Text())
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := metaData.Token()
+			if regex.MatchString(token.Data) {
+				return normalizeSpace(text.String()), &token
+			}
+			if token.Data == "img" {
+				for _, attr := range token.Attr {
+					if attr.Key == "alt" {
+						text.WriteString(" " + attr.Val + " ")
+					}
+				}
+			}
+		case html.EndTagToken:
+			name, _ := metaData.TagName()
+			if regex.Match(name) {
+				return normalizeSpace(text.String()), nil
+			}
+			// inline elements do not separate words, block elements do
+			if !inlineElements[string(name)] {
+				text.WriteString(" ")
+			}
+		}
+	}
+}
+
+// inlineElements lists the phrasing elements whose end tag does not introduce a word break.
+var inlineElements = map[string]bool{
+	"a": true, "abbr": true, "b": true, "bdi": true, "bdo": true, "cite": true, "code": true, "data": true,
+	"dfn": true, "em": true, "i": true, "kbd": true, "mark": true, "q": true, "s": true, "samp": true,
+	"small": true, "span": true, "strong": true, "sub": true, "sup": true, "time": true, "u": true, "var": true,
+}
+
 func SetHeadingDataToResponse(tempToken html.Token, res *response.SuccessResponse, token html.Token) {
 	res.Headings = append(res.Headings, response.Heading{
-		Tag:  token.Data,
-		Text: tempToken.Data,
+		Tag:   token.Data,
+		Level: headingLevel(token.Data),
+		Text:  tempToken.Data,
 	})
 }
 
@@ -105,3 +145,74 @@ func ExactRegexPatternAndToken(metaData *html.Tokenizer, regex *regexp.Regexp) (
 	match := regex.Match([]byte(token.Data))
 	return token, match
 }
+
+// CountHeadings returns the number of headings for every level from h1 to h6.
+func CountHeadings(headings []response.Heading) map[string]int {
+	counts := make(map[string]int)
+	for level := 1; level <= 6; level++ {
+		counts[fmt.Sprintf("h%d", level)] = 0
+	}
+	for _, heading := range headings {
+		counts[heading.Tag]++
+	}
+	return counts
+}
+
+// BuildHeadingOutline nests each heading under the closest preceding heading of a higher level.
+func BuildHeadingOutline(headings []response.Heading) []*response.HeadingNode {
+	var roots []*response.HeadingNode
+	var stack []*response.HeadingNode
+	for _, heading := range headings {
+		node := &response.HeadingNode{Tag: heading.Tag, Level: heading.Level, Text: heading.Text}
+		for len(stack) > 0 && stack[len(stack)-1].Level >= node.Level {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) == 0 {
+			roots = append(roots, node)
+		} else {
+			parent := stack[len(stack)-1]
+			parent.Children = append(parent.Children, node)
+		}
+		stack = append(stack, node)
+	}
+	return roots
+}
+
+// AuditHeadings reports a missing or repeated h1, skipped levels, empty headings and overly long headings.
+func AuditHeadings(headings []response.Heading, counts map[string]int) []response.Finding {
+	var findings []response.Finding
+	switch {
+	case counts["h1"] == 0:
+		findings = append(findings, newFinding("heading-missing-h1", constant.SEVERITY_ERROR,
+			"Page has no h1 heading"))
+	case counts["h1"] > 1:
+		findings = append(findings, newFinding("heading-multiple-h1", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Page has %d h1 headings", counts["h1"])))
+	}
+
+	previous := 0
+	for _, heading := range headings {
+		if previous > 0 && heading.Level > previous+1 {
+			findings = append(findings, newFinding("heading-skipped-level", constant.SEVERITY_WARNING,
+				fmt.Sprintf("Heading level skipped from h%d to %s: %q", previous, heading.Tag, heading.Text)))
+		}
+		previous = heading.Level
+
+		if heading.Text == constant.EMPTY {
+			findings = append(findings, newFinding("heading-empty", constant.SEVERITY_WARNING,
+				fmt.Sprintf("Empty %s heading", heading.Tag)))
+		} else if length := utf8.RuneCountInString(heading.Text); length > maxHeadingLength {
+			findings = append(findings, newFinding("heading-too-long", constant.SEVERITY_INFO,
+				fmt.Sprintf("%s heading is %d characters long, keep it under %d: %q", heading.Tag, length, maxHeadingLength, heading.Text)))
+		}
+	}
+	return findings
+}
+
+// headingLevel returns the numeric level of an h1-h6 tag name.
+func headingLevel(tag string) int {
+	if len(tag) == 2 && tag[1] >= '1' && tag[1] <= '6' {
+		return int(tag[1] - '0')
+	}
+	return 0
+}
@@ -5,6 +5,9 @@ import (
 	"api/response"
 	"log"
 	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
 	"strings"
 	"time"
 
@@ -12,6 +15,27 @@ import (
 	"golang.org/x/net/html"
 )
 
+const minAuthFormConfidence = 40
+
+var (
+	usernameFieldRegex = regexp.MustCompile(`(?i)(user|email|e-mail|login|account|phone|identifier)`)
+	loginTextRegex     = regexp.MustCompile(`(?i)(log\s*-?\s*in|sign\s*-?\s*in|signin|login|authenticate)`)
+	signUpTextRegex    = regexp.MustCompile(`(?i)(sign\s*-?\s*up|signup|register|create\s+(an\s+)?account|join\s+now)`)
+	resetTextRegex     = regexp.MustCompile(`(?i)(forgot|reset|recover)`)
+	ssoTextRegex       = regexp.MustCompile(`(?i)((sign|log)\s*(in|on)|continue|login)\s+with`)
+)
+
+// ssoProviders maps the SSO providers to the URL patterns of their authorization endpoints.
+var ssoProviders = map[string]*regexp.Regexp{
+	"Google":    regexp.MustCompile(`accounts\.google\.com`),
+	"Microsoft": regexp.MustCompile(`login\.(microsoftonline\.com|live\.com)`),
+	"GitHub":    regexp.MustCompile(`github\.com/login/oauth`),
+	"Apple":     regexp.MustCompile(`appleid\.apple\.com`),
+	"Facebook":  regexp.MustCompile(`facebook\.com/(v[0-9.]+/)?dialog/oauth`),
+	"LinkedIn":  regexp.MustCompile(`linkedin\.com/oauth`),
+	"Okta":      regexp.MustCompile(`\.okta\.com`),
+}
+
 // HtmlLoginFormAnalyzer implements the Analyzer interface for HTML login forms.
 type HtmlLoginFormAnalyzer struct{}
 
@@ -29,7 +53,7 @@ func (a *HtmlLoginFormAnalyzer) Analyze(wc *response.WebContent, res *response.S
 		log.Printf("HtmlLoginFormAnalyzer.Analyze succesfully completed in %v", time.Since(start))
 	}(startTime)
 
-	nodes, err := htmlquery.Parse(strings.NewReader(wc.Content))
+	nodes, err := wc.Document()
 	if err != nil {
 		log.Printf("Parser error while analyze login form and time taken for %v", time.Since(startTime))
 		return &response.ErrorResponse{
@@ -38,37 +62,248 @@ func (a *HtmlLoginFormAnalyzer) Analyze(wc *response.WebContent, res *response.S
 			Code:     http.StatusBadRequest,
 		}
 	}
-	forms := htmlquery.Find(nodes, constant.FORM_TAG_EXP)
-	ValidateLoginFormHandler(forms, res)
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	ValidateLoginFormHandler(nodes, pageURL, res)
 	return nil
 }
 
-func ValidateLoginFormHandler(forms []*html.Node, res *response.SuccessResponse) {
-	for _, form := range forms {
-		var hasUsername, hasPassword, hasSubmit bool
+// ValidateLoginFormHandler detects the authentication forms of the document, including password
+// inputs placed outside any <form>, and sets HasLogin when one of them is a login form.
+func ValidateLoginFormHandler(doc *html.Node, pageURL string, res *response.SuccessResponse) {
+	for _, form := range htmlquery.Find(doc, constant.FORM_TAG_EXP) {
+		controls := findElements(form, formControlTags...)
+		if authForm, ok := detectAuthForm(form, form.Parent, controls, pageURL); ok {
+			authForm.InForm = true
+			res.AuthForms = append(res.AuthForms, authForm)
+		}
+	}
+
+	// password inputs outside any form are usually submitted by script
+	var orphans []*html.Node
+	for _, input := range htmlquery.Find(doc, constant.INPUT_TAG_EXP) {
+		if !hasFormAncestor(input) && !htmlquery.ExistsAttr(input, "form") {
+			orphans = append(orphans, input)
+		}
+	}
+	if authForm, ok := detectAuthForm(nil, doc, orphans, pageURL); ok {
+		res.AuthForms = append(res.AuthForms, authForm)
+	}
+
+	for _, authForm := range res.AuthForms {
+		if authForm.Type == constant.AUTH_LOGIN || authForm.Type == constant.AUTH_LOGIN_STEP {
+			res.HasLogin = true
+		}
+	}
+}
+
+// detectAuthForm scores the controls of a form and classifies it as a login, login step, sign-up
+// or password reset form. scope is the element searched for SSO buttons.
+func detectAuthForm(form, scope *html.Node, controls []*html.Node, pageURL string) (response.AuthForm, bool) {
+	var passwords, newPasswords, usernames int
+	var hasUsernameAutocomplete, hasCurrentPassword, hasSubmit bool
+	var submitText string
+	authForm := response.AuthForm{}
 
-		// check the input type is text or email
-		usernameInputs := htmlquery.Find(form, constant.LOGIN_INPUT_VALIDATION)
-		if len(usernameInputs) > 0 {
-			hasUsername = true
+	for _, control := range controls {
+		inputType := strings.ToLower(htmlquery.SelectAttr(control, "type"))
+		autocomplete := strings.ToLower(htmlquery.SelectAttr(control, "autocomplete"))
+		name := htmlquery.SelectAttr(control, "name")
+		if name == constant.EMPTY {
+			name = htmlquery.SelectAttr(control, "id")
 		}
 
-		// check the input type is password
-		passwordInputs := htmlquery.Find(form, constant.LOGIN_PASSWORD_VALIDATION)
-		if len(passwordInputs) > 0 {
-			hasPassword = true
+		switch {
+		case control.Data == "input" && inputType == "password":
+			passwords++
+			if strings.Contains(autocomplete, "new-password") {
+				newPasswords++
+			}
+			if strings.Contains(autocomplete, "current-password") {
+				hasCurrentPassword = true
+			}
+		case control.Data == "input" && (inputType == "submit" || inputType == "image"):
+			continue
+		case control.Data == "input" && (inputType == "hidden" || inputType == "button" || inputType == "reset"):
+			continue
+		case control.Data == "input" && isUsernameInput(inputType, autocomplete, name):
+			usernames++
+			if strings.Contains(autocomplete, "username") {
+				hasUsernameAutocomplete = true
+			}
 		}
+		if name != constant.EMPTY {
+			authForm.Fields = append(authForm.Fields, name)
+		}
+	}
 
-		// check for submit button or input
-		submitButtons := htmlquery.Find(form, constant.LOGIN_SUBMIT_BUTTON_VALIDATION)
-		if len(submitButtons) > 0 {
-			hasSubmit = true
+	var text string
+	if form != nil {
+		text = htmlquery.InnerText(form) + " " + htmlquery.SelectAttr(form, "action") + " " +
+			htmlquery.SelectAttr(form, "id") + " " + htmlquery.SelectAttr(form, "class")
+		hasSubmit, submitText = findSubmitButtons(form)
+		authForm.Method = http.MethodGet
+		if method := htmlquery.SelectAttr(form, "method"); method != constant.EMPTY {
+			authForm.Method = strings.ToUpper(method)
 		}
+		authForm.Action = resolveFormAction(htmlquery.SelectAttr(form, "action"), pageURL)
+	}
+	intent := formIntent(submitText, text)
 
-		// login form need user name field, password and submit
-		// based on this condition we identiy login form is exist
-		if hasUsername && hasPassword && hasSubmit {
-			res.HasLogin = true
+	switch {
+	case passwords == 0 && usernames > 0 && intent == constant.AUTH_PASSWORD_RESET:
+		authForm.Type = constant.AUTH_PASSWORD_RESET
+		authForm.Confidence = 50
+	case passwords == 0 && usernames > 0 && (hasUsernameAutocomplete || intent == constant.AUTH_LOGIN):
+		// first step of a login split across multiple pages
+		authForm.Type = constant.AUTH_LOGIN_STEP
+		authForm.Confidence = 30
+		if hasUsernameAutocomplete {
+			authForm.Confidence += 20
+		}
+	case passwords == 0:
+		return authForm, false
+	case intent == constant.AUTH_PASSWORD_RESET && (newPasswords > 0 || passwords > 1):
+		authForm.Type = constant.AUTH_PASSWORD_RESET
+		authForm.Confidence = 60
+	case passwords > 1 || newPasswords > 0 || intent == constant.AUTH_SIGNUP:
+		authForm.Type = constant.AUTH_SIGNUP
+		authForm.Confidence = 60
+	default:
+		authForm.Type = constant.AUTH_LOGIN
+		authForm.Confidence = 40
+		if usernames > 0 {
+			authForm.Confidence += 20
+		}
+		if hasCurrentPassword || hasUsernameAutocomplete {
+			authForm.Confidence += 10
+		}
+	}
+
+	if hasSubmit {
+		authForm.Confidence += 15
+	}
+	if intent != constant.EMPTY {
+		authForm.Confidence += 15
+	}
+	if authForm.Confidence > 100 {
+		authForm.Confidence = 100
+	}
+	if authForm.Confidence < minAuthFormConfidence {
+		return authForm, false
+	}
+
+	authForm.SsoProviders = detectSsoProviders(scope)
+	authForm.HasSso = len(authForm.SsoProviders) > 0
+	if actionURL, err := url.Parse(authForm.Action); err == nil {
+		authForm.InsecureSubmit = actionURL.Scheme == constant.HTTP_SCHEME
+	}
+	return authForm, true
+}
+
+// isUsernameInput reports whether an input looks like the user identifier of an auth form.
+func isUsernameInput(inputType, autocomplete, name string) bool {
+	if strings.Contains(autocomplete, "username") || strings.Contains(autocomplete, "email") {
+		return true
+	}
+	switch inputType {
+	case "email":
+		return true
+	case constant.EMPTY, "text", "tel":
+		return usernameFieldRegex.MatchString(name)
+	}
+	return false
+}
+
+// findSubmitButtons reports whether the form has a submit control and returns the text of those controls.
+// A <button> without a type submits the form.
+func findSubmitButtons(form *html.Node) (bool, string) {
+	var found bool
+	var text strings.Builder
+	for _, button := range findElements(form, formButtonTags...) {
+		buttonType := strings.ToLower(htmlquery.SelectAttr(button, "type"))
+		switch {
+		case button.Data == "button" && (buttonType == constant.EMPTY || buttonType == "submit"):
+			text.WriteString(htmlquery.InnerText(button) + " ")
+		case button.Data == "input" && (buttonType == "submit" || buttonType == "image"):
+			text.WriteString(htmlquery.SelectAttr(button, "value") + " " + htmlquery.SelectAttr(button, "alt") + " ")
+		default:
+			continue
+		}
+		found = true
+	}
+	return found, text.String()
+}
+
+// formIntent classifies the purpose of a form from its submit button text first, then from the whole form text
+// where login wins because login forms usually link to sign-up and password reset.
+func formIntent(submitText, formText string) string {
+	switch {
+	case resetTextRegex.MatchString(submitText):
+		return constant.AUTH_PASSWORD_RESET
+	case signUpTextRegex.MatchString(submitText):
+		return constant.AUTH_SIGNUP
+	case loginTextRegex.MatchString(submitText):
+		return constant.AUTH_LOGIN
+	case loginTextRegex.MatchString(formText):
+		return constant.AUTH_LOGIN
+	case signUpTextRegex.MatchString(formText):
+		return constant.AUTH_SIGNUP
+	case resetTextRegex.MatchString(formText):
+		return constant.AUTH_PASSWORD_RESET
+	}
+	return constant.EMPTY
+}
+
+// detectSsoProviders finds "Sign in with ..." buttons and links to known identity providers.
+func detectSsoProviders(scope *html.Node) []string {
+	if scope == nil {
+		return nil
+	}
+	found := make(map[string]bool)
+	for _, control := range findElements(scope, formButtonTags...) {
+		text := htmlquery.InnerText(control) + " " + htmlquery.SelectAttr(control, "value") + " " +
+			htmlquery.SelectAttr(control, "aria-label")
+		href := strings.ToLower(htmlquery.SelectAttr(control, constant.H_REF) + " " + htmlquery.SelectAttr(control, "formaction"))
+		for provider, endpoint := range ssoProviders {
+			if ssoTextRegex.MatchString(text) && strings.Contains(strings.ToLower(text), strings.ToLower(provider)) {
+				found[provider] = true
+			}
+			if endpoint.MatchString(href) {
+				found[provider] = true
+			}
+		}
+	}
+
+	providers := make([]string, 0, len(found))
+	for provider := range found {
+		providers = append(providers, provider)
+	}
+	sort.Strings(providers)
+	return providers
+}
+
+// resolveFormAction resolves the form action against the page URL. An empty action submits to the page itself.
+func resolveFormAction(action, pageURL string) string {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return action
+	}
+	resolved, err := base.Parse(strings.TrimSpace(action))
+	if err != nil {
+		return action
+	}
+	return resolved.String()
+}
+
+func hasFormAncestor(n *html.Node) bool {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		if parent.Type == html.ElementNode && parent.Data == "form" {
+			return true
 		}
 	}
+	return false
 }
@@ -17,6 +17,16 @@ import (
 
 type LinkAnalyzeData struct {
 	Links []string
+	// Sources holds the element each link was found on, in the same order as Links
+	Sources []LinkSource
+}
+
+// LinkSource is a link together with the element and attribute it was read from.
+type LinkSource struct {
+	Url  string
+	Host string
+	Attr string
+	Node *html.Node
 }
 
 // HtmlUrlLinkAnalyzer implements the Analyzer interface for HTML URLs and links.
@@ -33,7 +43,7 @@ func (a *HtmlUrlLinkAnalyzer) Analyze(wc *response.WebContent, res *response.Suc
 	startTime := time.Now()
 
 	// Parse HTML content
-	doc, err := html.Parse(strings.NewReader(wc.Content))
+	doc, err := wc.Document()
 	if err != nil {
 		log.Println("❌ Failed to parse HTML content:", err)
 		return &response.ErrorResponse{
@@ -48,8 +58,12 @@ func (a *HtmlUrlLinkAnalyzer) Analyze(wc *response.WebContent, res *response.Suc
 	extractLinks(doc, res.BasePath, &data)
 	log.Printf("📎 Found %d links", len(data.Links))
 
-	// Check accessibility
-	checkLinkAccessibility(data.Links, res)
+	// Check accessibility only when link probing is enabled
+	if configs.GetConfig().LinkProbe {
+		checkLinkAccessibility(probeClient(wc), data.Links, res)
+	} else {
+		listLinks(data.Links, res)
+	}
 	log.Printf("✅ Completed URL and Link analysis in %d ms", time.Since(startTime).Milliseconds())
 	return nil
 }
@@ -61,6 +75,7 @@ func extractLinks(n *html.Node, base string, data *LinkAnalyzeData) {
 			if attr.Key == constant.H_REF || attr.Key == constant.SRC {
 				if absURL := resolveURL(attr.Val, base); absURL != constant.EMPTY {
 					data.Links = append(data.Links, absURL)
+					data.Sources = append(data.Sources, LinkSource{Url: absURL, Host: hostOf(absURL), Attr: attr.Key, Node: n})
 				}
 			}
 		}
@@ -70,6 +85,34 @@ func extractLinks(n *html.Node, base string, data *LinkAnalyzeData) {
 	}
 }
 
+// InternalPageLinks returns the pages the <a> and <area> links of the document point to on the host and port of
+// base, normalized and without duplicates, in document order.
+func InternalPageLinks(doc *html.Node, base string) []string {
+	var data LinkAnalyzeData
+	extractLinks(doc, base, &data)
+	baseURL, err := url.Parse(base)
+	if err != nil {
+		return nil
+	}
+
+	var links []string
+	seen := make(map[string]bool)
+	for _, source := range data.Sources {
+		if source.Attr != constant.H_REF || (source.Node.Data != "a" && source.Node.Data != "area") ||
+			!isHTTPURL(source.Url) {
+			continue
+		}
+		if linkURL, err := url.Parse(source.Url); err != nil || !strings.EqualFold(linkURL.Host, baseURL.Host) {
+			continue
+		}
+		if link := NormalizePageURL(source.Url); link != constant.EMPTY && !seen[link] {
+			seen[link] = true
+			links = append(links, link)
+		}
+	}
+	return links
+}
+
 // resolveURL resolves relative URLs against the base and filters anchors or invalid URLs.
 func resolveURL(rawURL, base string) string {
 	if strings.HasPrefix(rawURL, constant.HASH_CODE) {
@@ -95,21 +138,21 @@ func resolveURL(rawURL, base string) string {
 }
 
 // checkLinkAccessibility checks which links are accessible and classifies them as internal/external.
-func checkLinkAccessibility(links []string, res *response.SuccessResponse) {
+func checkLinkAccessibility(client *http.Client, links []string, res *response.SuccessResponse) {
 	log.Println("🌐 Checking link accessibility...")
 
 	urlChan := make(chan response.Url)
+	collected := make(chan struct{})
 	var wg sync.WaitGroup
 
 	// Collector goroutine
 	go func() {
+		defer close(collected)
 		for urlData := range urlChan {
 			res.Urls = append(res.Urls, urlData)
 		}
 	}()
 
-	client := configs.GetConfig().Client
-
 	for _, link := range links {
 		wg.Add(1)
 		go func(link string) {
@@ -120,6 +163,17 @@ func checkLinkAccessibility(links []string, res *response.SuccessResponse) {
 
 	wg.Wait()
 	close(urlChan)
+	<-collected
+}
+
+// listLinks classifies the links as internal/external without requesting them.
+func listLinks(links []string, res *response.SuccessResponse) {
+	for _, link := range links {
+		res.Urls = append(res.Urls, response.Url{
+			Url:  link,
+			Type: classifyLinkType(link, res.BasePath),
+		})
+	}
 }
 
 // checkSingleURL checks the accessibility of a single URL and sends the result through a channel.
@@ -129,6 +183,12 @@ func checkSingleURL(link, basePath string, client *http.Client, urlChan chan<- r
 		Type: classifyLinkType(link, basePath),
 	}
 
+	if allowed, _ := CrawlAllowed(link); !allowed {
+		result.Disallowed = true
+		urlChan <- result
+		return
+	}
+
 	start := time.Now()
 	resp, err := client.Get(link)
 	result.UrlExecutionTime = time.Since(start).Milliseconds()
@@ -1,34 +1,129 @@
 package analyze
 
 import (
+	"api/constant"
 	"api/response"
+	"io"
 	"log"
+	"mime"
 	"strings"
 	"time"
+
+	"golang.org/x/net/html"
 )
 
-// HtmlVersionAnalyzer implements the Analyzer interface for HTML versions.
-type HtmlVersionAnalyzer struct {
-	types map[string]string
+const xhtmlNamespace = "http://www.w3.org/1999/xhtml"
+
+// doctypeVersion maps a public identifier, compared without case, to the document version it declares.
+type doctypeVersion struct {
+	publicId string
+	version  string
 }
 
+// doctypeVersions lists the known public identifiers. A slice keeps the lookup deterministic.
+var doctypeVersions = []doctypeVersion{
+	{"-//W3C//DTD HTML 4.01//EN", "HTML 4.01 Strict"},
+	{"-//W3C//DTD HTML 4.01 Transitional//EN", "HTML 4.01 Transitional"},
+	{"-//W3C//DTD HTML 4.01 Frameset//EN", "HTML 4.01 Frameset"},
+	{"-//W3C//DTD HTML 4.0//EN", "HTML 4.0 Strict"},
+	{"-//W3C//DTD HTML 4.0 Transitional//EN", "HTML 4.0 Transitional"},
+	{"-//W3C//DTD HTML 4.0 Frameset//EN", "HTML 4.0 Frameset"},
+	{"-//W3C//DTD HTML 3.2 Final//EN", "HTML 3.2"},
+	{"-//W3C//DTD HTML 3.2//EN", "HTML 3.2"},
+	{"-//IETF//DTD HTML 2.0//EN", "HTML 2.0"},
+	{"-//IETF//DTD HTML//EN", "HTML 2.0"},
+	{"-//W3C//DTD XHTML 1.0 Strict//EN", "XHTML 1.0 Strict"},
+	{"-//W3C//DTD XHTML 1.0 Transitional//EN", "XHTML 1.0 Transitional"},
+	{"-//W3C//DTD XHTML 1.0 Frameset//EN", "XHTML 1.0 Frameset"},
+	{"-//W3C//DTD XHTML 1.1//EN", "XHTML 1.1"},
+	{"-//W3C//DTD XHTML Basic 1.0//EN", "XHTML Basic 1.0"},
+	{"-//W3C//DTD XHTML Basic 1.1//EN", "XHTML Basic 1.1"},
+	{"-//WAPFORUM//DTD XHTML Mobile 1.0//EN", "XHTML Mobile 1.0"},
+	{"-//WAPFORUM//DTD XHTML Mobile 1.1//EN", "XHTML Mobile 1.1"},
+	{"-//WAPFORUM//DTD XHTML Mobile 1.2//EN", "XHTML Mobile 1.2"},
+	{"-//W3C//DTD XHTML+RDFa 1.0//EN", "XHTML+RDFa 1.0"},
+	{"-//W3C//DTD XHTML+RDFa 1.1//EN", "XHTML+RDFa 1.1"},
+	{"-//W3C//DTD XHTML 1.1 plus MathML 2.0 plus SVG 1.1//EN", "XHTML 1.1 plus MathML 2.0 plus SVG 1.1"},
+	{"-//W3C//DTD XHTML 1.1 plus MathML 2.0//EN", "XHTML 1.1 plus MathML 2.0"},
+	{"-//W3C//DTD MathML 2.0//EN", "MathML 2.0"},
+	{"-//W3C//DTD SVG 1.1//EN", "SVG 1.1"},
+	{"-//W3C//DTD SVG 1.0//EN", "SVG 1.0"},
+	{"-//W3C//DTD SVG 1.1 Basic//EN", "SVG 1.1 Basic"},
+	{"-//W3C//DTD SVG 1.1 Tiny//EN", "SVG 1.1 Tiny"},
+}
+
+// quirksPublicIds lists the public identifiers that put a browser in quirks mode.
+var quirksPublicIds = []string{"-//W3O//DTD W3 HTML Strict 3.0//EN//", "-/W3C/DTD HTML 4.0 Transitional/EN", "HTML"}
+
+// quirksPublicPrefixes lists the public identifier prefixes that put a browser in quirks mode, from the HTML
+// standard's initial insertion mode.
+var quirksPublicPrefixes = []string{
+	"+//Silmaril//dtd html Pro v0r11 19970101//",
+	"-//AS//DTD HTML 3.0 asWedit + extensions//",
+	"-//AdvaSoft Ltd//DTD HTML 3.0 asWedit + extensions//",
+	"-//IETF//DTD HTML 2.0 Level 1//",
+	"-//IETF//DTD HTML 2.0 Level 2//",
+	"-//IETF//DTD HTML 2.0 Strict Level 1//",
+	"-//IETF//DTD HTML 2.0 Strict Level 2//",
+	"-//IETF//DTD HTML 2.0 Strict//",
+	"-//IETF//DTD HTML 2.0//",
+	"-//IETF//DTD HTML 2.1E//",
+	"-//IETF//DTD HTML 3.0//",
+	"-//IETF//DTD HTML 3.2 Final//",
+	"-//IETF//DTD HTML 3.2//",
+	"-//IETF//DTD HTML 3//",
+	"-//IETF//DTD HTML Level 0//",
+	"-//IETF//DTD HTML Level 1//",
+	"-//IETF//DTD HTML Level 2//",
+	"-//IETF//DTD HTML Level 3//",
+	"-//IETF//DTD HTML Strict Level 0//",
+	"-//IETF//DTD HTML Strict Level 1//",
+	"-//IETF//DTD HTML Strict Level 2//",
+	"-//IETF//DTD HTML Strict Level 3//",
+	"-//IETF//DTD HTML Strict//",
+	"-//IETF//DTD HTML//",
+	"-//Metrius//DTD Metrius Presentational//",
+	"-//Microsoft//DTD Internet Explorer 2.0 HTML Strict//",
+	"-//Microsoft//DTD Internet Explorer 2.0 HTML//",
+	"-//Microsoft//DTD Internet Explorer 2.0 Tables//",
+	"-//Microsoft//DTD Internet Explorer 3.0 HTML Strict//",
+	"-//Microsoft//DTD Internet Explorer 3.0 HTML//",
+	"-//Microsoft//DTD Internet Explorer 3.0 Tables//",
+	"-//Netscape Comm. Corp.//DTD HTML//",
+	"-//Netscape Comm. Corp.//DTD Strict HTML//",
+	"-//O'Reilly and Associates//DTD HTML 2.0//",
+	"-//O'Reilly and Associates//DTD HTML Extended 1.0//",
+	"-//O'Reilly and Associates//DTD HTML Extended Relaxed 1.0//",
+	"-//SQ//DTD HTML 2.0 HoTMetaL + extensions//",
+	"-//SoftQuad Software//DTD HoTMetaL PRO 6.0::19990601::extensions to HTML 4.0//",
+	"-//SoftQuad//DTD HoTMetaL PRO 4.0::19971010::extensions to HTML 4.0//",
+	"-//Spyglass//DTD HTML 2.0 Extended//",
+	"-//Sun Microsystems Corp.//DTD HotJava HTML//",
+	"-//Sun Microsystems Corp.//DTD HotJava Strict HTML//",
+	"-//W3C//DTD HTML 3 1995-03-24//",
+	"-//W3C//DTD HTML 3.2 Draft//",
+	"-//W3C//DTD HTML 3.2 Final//",
+	"-//W3C//DTD HTML 3.2//",
+	"-//W3C//DTD HTML 3.2S Draft//",
+	"-//W3C//DTD HTML 4.0 Frameset//",
+	"-//W3C//DTD HTML 4.0 Transitional//",
+	"-//W3C//DTD HTML Experimental 19960712//",
+	"-//W3C//DTD HTML Experimental 970421//",
+	"-//W3C//DTD W3 HTML//",
+	"-//W3O//DTD W3 HTML 3.0//",
+	"-//WebTechs//DTD Mozilla HTML 2.0//",
+	"-//WebTechs//DTD Mozilla HTML//",
+}
+
+// HtmlVersionAnalyzer implements the Analyzer interface for HTML versions.
+type HtmlVersionAnalyzer struct{}
+
 // NewHtmlVersionAnalyzer creates a new HtmlVersionAnalyzer.
 func NewHtmlVersionAnalyzer() *HtmlVersionAnalyzer {
-	return &HtmlVersionAnalyzer{
-		types: map[string]string{
-			"HTML 5":                 `<!DOCTYPE html>`,
-			"HTML 4.01 Strict":       `"-//W3C//DTD HTML 4.01//EN"`,
-			"HTML 4.01 Transitional": `"-//W3C//DTD HTML 4.01 Transitional//EN"`,
-			"HTML 4.01 Frameset":     `"-//W3C//DTD HTML 4.01 Frameset//EN"`,
-			"XHTML 1.0 Strict":       `"-//W3C//DTD XHTML 1.0 Strict//EN"`,
-			"XHTML 1.0 Transitional": `"-//W3C//DTD XHTML 1.0 Transitional//EN"`,
-			"XHTML 1.0 Frameset":     `"-//W3C//DTD XHTML 1.0 Frameset//EN"`,
-			"XHTML 1.1":              `"-//W3C//DTD XHTML 1.1//EN"`,
-		},
-	}
+	return &HtmlVersionAnalyzer{}
 }
 
-// Analyze performs HTML version analysis on the web content.
+// Analyze reads the doctype token of the page and reports the declared version and the browser rendering mode.
 func (a *HtmlVersionAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
 	log.Println("Analyzing HTML version function is started...")
 	startTime := time.Now()
@@ -37,12 +132,218 @@ func (a *HtmlVersionAnalyzer) Analyze(wc *response.WebContent, res *response.Suc
 		log.Printf("HtmlVersionAnalyzer.Analyze completed. Time taken : %d Microseconds", time.Since(startTime).Microseconds())
 	}(startTime)
 
-	htmlContent := strings.ToLower(wc.Content)
-	for key, val := range a.types {
-		if strings.Contains(htmlContent, strings.ToLower(val)) {
-			res.HtmlVersion = key
-			break
+	doctype := ReadDoctype(html.NewTokenizer(strings.NewReader(wc.Content)))
+	if wc.Headers != nil {
+		if mediaType, _, err := mime.ParseMediaType(wc.Headers.Get("Content-Type")); err == nil {
+			doctype.ContentType = mediaType
+			doctype.ServedAsXml = mediaType == "application/xhtml+xml" || mediaType == "application/xml" ||
+				mediaType == "text/xml" || mediaType == "image/svg+xml"
 		}
 	}
+	if doctype.ServedAsXml {
+		// the XML parser has no quirks modes
+		doctype.Mode = constant.MODE_NO_QUIRKS
+	}
+	doctype.Findings = auditDoctype(doctype)
+
+	res.HtmlVersion = doctype.Version
+	res.Doctype = doctype
 	return nil
 }
+
+// ReadDoctype finds the doctype token that precedes the first element, ignoring whitespace, comments and an XML
+// declaration, and derives the version and rendering mode from its identifiers.
+func ReadDoctype(tokenizer *html.Tokenizer) *response.DoctypeInfo {
+	doctype := &response.DoctypeInfo{Mode: constant.MODE_QUIRKS}
+	position := newPositionTracker()
+	seenContent := false
+
+	for {
+		tokenType := tokenizer.Next()
+		line := position.line
+		raw := string(tokenizer.Raw())
+		position.advance(tokenizer.Raw())
+
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				log.Printf("HTML tokenizer error: %v", tokenizer.Err())
+			}
+			return doctype
+		case html.CommentToken:
+			continue
+		case html.TextToken:
+			if strings.TrimLeft(raw, " \t\r\n\f\uFEFF") == constant.EMPTY {
+				continue
+			}
+			seenContent = true
+		case html.DoctypeToken:
+			if seenContent || doctype.Present {
+				doctype.Findings = append(doctype.Findings, newFinding("doctype-misplaced", constant.SEVERITY_WARNING,
+					"A doctype after the start of the document is ignored by browsers: "+raw))
+				continue
+			}
+			doctype.Present, doctype.Raw, doctype.Line = true, raw, line
+			forceQuirks := parseDoctypeIdentifiers(doctype, raw)
+			doctype.Version = doctypeVersionName(doctype)
+			doctype.Mode = doctypeMode(doctype, forceQuirks)
+			switch {
+			case forceQuirks:
+				doctype.Findings = append(doctype.Findings, newFinding("doctype-malformed", constant.SEVERITY_ERROR,
+					"Doctype is malformed and forces quirks mode: "+raw))
+			case !strings.ContainsAny(raw[len("<!doctype"):][:1], " \t\r\n\f"):
+				doctype.Findings = append(doctype.Findings, newFinding("doctype-malformed", constant.SEVERITY_WARNING,
+					"Doctype is missing the whitespace before its name: "+raw))
+			}
+		case html.StartTagToken, html.SelfClosingTagToken:
+			if !seenContent {
+				token := tokenizer.Token()
+				if !doctype.Present {
+					// standalone SVG and MathML documents do not need a doctype
+					switch token.Data {
+					case "svg":
+						doctype.Version = "SVG"
+					case "math":
+						doctype.Version = "MathML"
+					}
+				}
+				for _, attr := range token.Attr {
+					if token.Data == "html" && attr.Key == "xmlns" && attr.Val == xhtmlNamespace {
+						doctype.XhtmlXmlns = true
+					}
+				}
+			}
+			seenContent = true
+		default:
+			seenContent = true
+		}
+	}
+}
+
+// parseDoctypeIdentifiers reads the name and the public and system identifiers of a raw doctype. It reports
+// whether the doctype sets the force-quirks flag of the HTML tokenizer, as a missing name or a broken identifier do.
+func parseDoctypeIdentifiers(doctype *response.DoctypeInfo, raw string) bool {
+	body := strings.TrimSuffix(raw[len("<!doctype"):], ">")
+	fields := strings.TrimLeft(body, " \t\r\n\f")
+	if fields == constant.EMPTY {
+		return true
+	}
+
+	name, rest := fields, constant.EMPTY
+	if index := strings.IndexAny(fields, " \t\r\n\f"); index >= 0 {
+		name, rest = fields[:index], strings.TrimSpace(fields[index:])
+	}
+	doctype.Name = strings.ToLower(name)
+	if rest == constant.EMPTY {
+		return false
+	}
+	if len(rest) < 6 {
+		return true
+	}
+
+	var ok bool
+	keyword, rest := strings.ToUpper(rest[:6]), strings.TrimSpace(rest[6:])
+	switch keyword {
+	case "PUBLIC":
+		if doctype.PublicId, rest, ok = readQuotedIdentifier(rest); !ok {
+			return true
+		}
+		if rest = strings.TrimSpace(rest); rest != constant.EMPTY {
+			doctype.SystemId, _, ok = readQuotedIdentifier(rest)
+		}
+	case "SYSTEM":
+		doctype.SystemId, _, ok = readQuotedIdentifier(rest)
+	}
+	return !ok
+}
+
+// readQuotedIdentifier reads a single or double quoted identifier and returns the text that follows it.
+func readQuotedIdentifier(value string) (string, string, bool) {
+	if value == constant.EMPTY || (value[0] != '"' && value[0] != '\'') {
+		return constant.EMPTY, value, false
+	}
+	end := strings.IndexByte(value[1:], value[0])
+	if end < 0 {
+		return value[1:], constant.EMPTY, false
+	}
+	return value[1 : end+1], value[end+2:], true
+}
+
+// doctypeVersionName returns the version declared by the doctype.
+func doctypeVersionName(doctype *response.DoctypeInfo) string {
+	for _, known := range doctypeVersions {
+		if strings.EqualFold(doctype.PublicId, known.publicId) {
+			return known.version
+		}
+	}
+	switch {
+	case doctype.Name == "html" && doctype.PublicId == constant.EMPTY &&
+		(doctype.SystemId == constant.EMPTY || doctype.SystemId == "about:legacy-compat"):
+		return "HTML 5"
+	case doctype.Name == "svg":
+		return "SVG"
+	case doctype.Name == "math":
+		return "MathML"
+	}
+	return constant.EMPTY
+}
+
+// doctypeMode returns the rendering mode the doctype selects, following the HTML standard.
+func doctypeMode(doctype *response.DoctypeInfo, forceQuirks bool) string {
+	publicId := strings.ToLower(doctype.PublicId)
+	systemId := strings.ToLower(doctype.SystemId)
+	hasPrefix := func(prefixes ...string) bool {
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(publicId, strings.ToLower(prefix)) {
+				return true
+			}
+		}
+		return false
+	}
+
+	if forceQuirks || doctype.Name != "html" || systemId == "http://www.ibm.com/data/dtd/v11/ibmxhtml1-transitional.dtd" {
+		return constant.MODE_QUIRKS
+	}
+	for _, id := range quirksPublicIds {
+		if publicId == strings.ToLower(id) {
+			return constant.MODE_QUIRKS
+		}
+	}
+	html401 := []string{"-//W3C//DTD HTML 4.01 Frameset//", "-//W3C//DTD HTML 4.01 Transitional//"}
+	switch {
+	case hasPrefix(quirksPublicPrefixes...):
+		return constant.MODE_QUIRKS
+	case doctype.SystemId == constant.EMPTY && hasPrefix(html401...):
+		return constant.MODE_QUIRKS
+	case hasPrefix("-//W3C//DTD XHTML 1.0 Frameset//", "-//W3C//DTD XHTML 1.0 Transitional//"):
+		return constant.MODE_LIMITED_QUIRKS
+	case doctype.SystemId != constant.EMPTY && hasPrefix(html401...):
+		return constant.MODE_LIMITED_QUIRKS
+	}
+	return constant.MODE_NO_QUIRKS
+}
+
+// auditDoctype reports a missing doctype, legacy rendering modes and XHTML doctypes parsed as HTML.
+func auditDoctype(doctype *response.DoctypeInfo) []response.Finding {
+	findings := doctype.Findings
+	switch {
+	case !doctype.Present && doctype.Version == constant.EMPTY && !doctype.ServedAsXml:
+		findings = append(findings, newFinding("doctype-missing", constant.SEVERITY_WARNING,
+			"Page has no doctype and renders in quirks mode, add <!DOCTYPE html>"))
+	case !doctype.Present || doctype.ServedAsXml:
+	case doctype.Version == constant.EMPTY:
+		findings = append(findings, newFinding("doctype-unknown", constant.SEVERITY_INFO,
+			"Doctype does not declare a known version: "+doctype.Raw))
+	case doctype.Mode == constant.MODE_QUIRKS:
+		findings = append(findings, newFinding("doctype-quirks", constant.SEVERITY_WARNING,
+			doctype.Version+" doctype renders the page in quirks mode"))
+	case doctype.Mode == constant.MODE_LIMITED_QUIRKS:
+		findings = append(findings, newFinding("doctype-limited-quirks", constant.SEVERITY_INFO,
+			doctype.Version+" doctype renders the page in limited-quirks mode"))
+	}
+	if doctype.Present && strings.HasPrefix(doctype.Version, "XHTML") && !doctype.ServedAsXml && doctype.ContentType != constant.EMPTY {
+		findings = append(findings, newFinding("xhtml-served-as-html", constant.SEVERITY_INFO,
+			"XHTML document is served as "+doctype.ContentType+" and parsed as HTML"))
+	}
+	return findings
+}
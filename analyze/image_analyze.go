@@ -0,0 +1,288 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxConcurrentProbes bounds the number of subresources requested at the same time.
+const maxConcurrentProbes = 8
+
+var (
+	// cssBackgroundRegex matches background and background-image declarations.
+	cssBackgroundRegex = regexp.MustCompile(`(?i)background(?:-image)?\s*:([^;}]*)`)
+	// cssURLRegex matches the url() references of a CSS value.
+	cssURLRegex = regexp.MustCompile(`(?i)url\(\s*['"]?([^'")]+?)['"]?\s*\)`)
+)
+
+// legacyImageFormats lists the formats that have a smaller modern WebP or AVIF equivalent.
+var legacyImageFormats = map[string]bool{"jpeg": true, "png": true, "gif": true, "bmp": true}
+
+// imageExtensionFormats maps the file extensions to image formats for images that were not probed.
+var imageExtensionFormats = map[string]string{
+	".jpg": "jpeg", ".jpeg": "jpeg", ".png": "png", ".gif": "gif", ".bmp": "bmp",
+	".webp": "webp", ".avif": "avif", ".svg": "svg", ".ico": "ico",
+}
+
+// ImageAnalyzer implements the Analyzer interface for the image inventory of the page.
+type ImageAnalyzer struct{}
+
+// NewImageAnalyzer creates a new ImageAnalyzer.
+func NewImageAnalyzer() *ImageAnalyzer {
+	return &ImageAnalyzer{}
+}
+
+// Analyze lists every image of the page, probes their size and format and reports optimization opportunities.
+func (a *ImageAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing images function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("ImageAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing images",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	report := &response.ImageReport{Images: ExtractImages(doc, pageURL)}
+	cfg := configs.GetConfig()
+	if cfg.LinkProbe {
+		probeImages(probeClient(wc), report.Images)
+	}
+	AuditImages(doc, pageURL, report, cfg.ImageSizeBudget, cfg.ImageEagerLimit)
+	res.Images = report
+	return nil
+}
+
+// ExtractImages lists the <img> elements, their srcset candidates, the <picture> sources and the CSS background
+// images of inline styles and <style> elements in document order.
+func ExtractImages(doc *html.Node, pageURL string) []response.ImageInfo {
+	var images []response.ImageInfo
+	add := func(raw, source string) {
+		if link := resolveURL(strings.TrimSpace(raw), pageURL); link != constant.EMPTY {
+			images = append(images, response.ImageInfo{Url: link, Source: source, Format: formatFromURL(link)})
+		}
+	}
+
+	walkElements(doc, func(n *html.Node) {
+		switch n.Data {
+		case "img":
+			if src := getAttr(n, constant.SRC); strings.TrimSpace(src) != constant.EMPTY {
+				link := resolveURL(strings.TrimSpace(src), pageURL)
+				if link != constant.EMPTY {
+					images = append(images, response.ImageInfo{
+						Url:     link,
+						Source:  constant.IMAGE_SOURCE_IMG,
+						Alt:     getAttr(n, "alt"),
+						Width:   getAttr(n, "width"),
+						Height:  getAttr(n, "height"),
+						Loading: strings.ToLower(getAttr(n, "loading")),
+						Format:  formatFromURL(link),
+					})
+				}
+			}
+			for _, candidate := range parseSrcset(getAttr(n, "srcset")) {
+				add(candidate, constant.IMAGE_SOURCE_SRCSET)
+			}
+		case "source":
+			if n.Parent != nil && n.Parent.Data == "picture" {
+				for _, candidate := range parseSrcset(getAttr(n, "srcset")) {
+					add(candidate, constant.IMAGE_SOURCE_PICTURE)
+				}
+			}
+		case "style":
+			for _, link := range cssBackgroundURLs(nodeText(n)) {
+				add(link, constant.IMAGE_SOURCE_BACKGROUND)
+			}
+		}
+		if style := getAttr(n, "style"); style != constant.EMPTY {
+			for _, link := range cssBackgroundURLs(style) {
+				add(link, constant.IMAGE_SOURCE_BACKGROUND)
+			}
+		}
+	})
+	return images
+}
+
+// cssBackgroundURLs returns the url() references of the background declarations of a stylesheet or style attribute.
+func cssBackgroundURLs(css string) []string {
+	var urls []string
+	for _, declaration := range cssBackgroundRegex.FindAllStringSubmatch(css, -1) {
+		for _, match := range cssURLRegex.FindAllStringSubmatch(declaration[1], -1) {
+			urls = append(urls, match[1])
+		}
+	}
+	return urls
+}
+
+// probeImages requests every distinct http(s) image once, with a bounded number of concurrent requests.
+func probeImages(client *http.Client, images []response.ImageInfo) {
+	probes := make(map[string]*response.ImageProbe)
+	seen := make(map[string]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentProbes)
+
+	for _, image := range images {
+		if seen[image.Url] || !isHTTPURL(image.Url) {
+			continue
+		}
+		seen[image.Url] = true
+		wg.Add(1)
+		go func(link string) {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+
+			probe, err := probeImage(client, link)
+			if err != nil {
+				log.Printf("Failed probing image: %s | Error: %v", link, err)
+			}
+			mu.Lock()
+			probes[link] = probe
+			mu.Unlock()
+		}(image.Url)
+	}
+	wg.Wait()
+
+	for i := range images {
+		if probe := probes[images[i].Url]; probe != nil {
+			images[i].Probe = probe
+			if format := formatFromProbe(probe); format != constant.EMPTY {
+				images[i].Format = format
+			}
+		}
+	}
+}
+
+// AuditImages flags images causing layout shift, eager images below the fold, legacy formats without a modern
+// alternative and images above the size budget, and sums the transfer size of the distinct images.
+func AuditImages(doc *html.Node, pageURL string, report *response.ImageReport, budget int64, eagerLimit int) {
+	modern := hasModernAlternatives(doc, pageURL)
+	counted := make(map[string]bool)
+	imgIndex := 0
+
+	for i := range report.Images {
+		image := &report.Images[i]
+		if image.Probe != nil && !counted[image.Url] {
+			counted[image.Url] = true
+			if image.Probe.Status == http.StatusOK && image.Probe.ContentLength > 0 {
+				report.TotalBytes += image.Probe.ContentLength
+			}
+		}
+
+		if image.Probe != nil && image.Probe.Status != 0 && image.Probe.Status != http.StatusOK {
+			image.Findings = append(image.Findings, newFinding("image-broken", constant.SEVERITY_ERROR,
+				fmt.Sprintf("Image %s returned status %d", image.Url, image.Probe.Status)))
+		}
+		if image.Probe != nil && budget > 0 && image.Probe.ContentLength > budget {
+			image.Findings = append(image.Findings, newFinding("image-oversized", constant.SEVERITY_WARNING,
+				fmt.Sprintf("Image %s is %d KB, above the %d KB budget", image.Url, image.Probe.ContentLength/1024, budget/1024)))
+		}
+
+		if image.Source != constant.IMAGE_SOURCE_IMG {
+			continue
+		}
+		imgIndex++
+		if image.Width == constant.EMPTY || image.Height == constant.EMPTY {
+			image.Findings = append(image.Findings, newFinding("image-missing-dimensions", constant.SEVERITY_WARNING,
+				"Image has no width and height attributes and causes a layout shift: "+image.Url))
+		}
+		switch {
+		case imgIndex > eagerLimit && image.Loading != "lazy":
+			image.Findings = append(image.Findings, newFinding("image-not-lazy", constant.SEVERITY_INFO,
+				fmt.Sprintf("Image %d is likely below the fold and should use loading=\"lazy\": %s", imgIndex, image.Url)))
+		case imgIndex <= eagerLimit && image.Loading == "lazy":
+			image.Findings = append(image.Findings, newFinding("image-lazy-above-fold", constant.SEVERITY_INFO,
+				fmt.Sprintf("Image %d is likely above the fold, lazy loading delays it: %s", imgIndex, image.Url)))
+		}
+		if legacyImageFormats[image.Format] && !modern[image.Url] {
+			image.Findings = append(image.Findings, newFinding("image-legacy-format", constant.SEVERITY_INFO,
+				fmt.Sprintf("Image is served as %s without a WebP or AVIF alternative: %s", image.Format, image.Url)))
+		}
+	}
+
+	report.Count = len(report.Images)
+	for _, image := range report.Images {
+		report.Findings = append(report.Findings, image.Findings...)
+	}
+}
+
+// hasModernAlternatives maps the resolved src of each <img> to whether its srcset or enclosing <picture> offers WebP or AVIF.
+func hasModernAlternatives(doc *html.Node, pageURL string) map[string]bool {
+	modern := make(map[string]bool)
+	for _, img := range findElements(doc, "img") {
+		candidates := parseSrcset(getAttr(img, "srcset"))
+		found := false
+		if img.Parent != nil && img.Parent.Data == "picture" {
+			for _, source := range findElements(img.Parent, "source") {
+				sourceType := strings.ToLower(getAttr(source, "type"))
+				if strings.Contains(sourceType, "webp") || strings.Contains(sourceType, "avif") {
+					found = true
+				}
+				candidates = append(candidates, parseSrcset(getAttr(source, "srcset"))...)
+			}
+		}
+		for _, candidate := range candidates {
+			if format := formatFromURL(candidate); format == "webp" || format == "avif" {
+				found = true
+			}
+		}
+		if found {
+			modern[resolveURL(strings.TrimSpace(getAttr(img, constant.SRC)), pageURL)] = true
+		}
+	}
+	return modern
+}
+
+// formatFromProbe returns the image format from the decoded header or the content type.
+func formatFromProbe(probe *response.ImageProbe) string {
+	if probe.Format != constant.EMPTY {
+		return probe.Format
+	}
+	if subtype, ok := strings.CutPrefix(probe.ContentType, "image/"); ok {
+		return strings.TrimSuffix(strings.TrimPrefix(subtype, "x-"), "+xml")
+	}
+	return constant.EMPTY
+}
+
+// formatFromURL guesses the image format from the path extension or the media type of a data URL.
+func formatFromURL(link string) string {
+	if mediaType, ok := strings.CutPrefix(link, "data:image/"); ok {
+		mediaType = strings.SplitN(strings.SplitN(mediaType, ";", 2)[0], ",", 2)[0]
+		return strings.TrimSuffix(mediaType, "+xml")
+	}
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return constant.EMPTY
+	}
+	return imageExtensionFormats[strings.ToLower(path.Ext(parsedURL.Path))]
+}
+
+// isHTTPURL reports whether the link can be requested over HTTP.
+func isHTTPURL(link string) bool {
+	parsedURL, err := url.Parse(link)
+	return err == nil && (parsedURL.Scheme == constant.HTTP_SCHEME || parsedURL.Scheme == constant.HTTPS_SCHEME)
+}
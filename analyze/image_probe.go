@@ -0,0 +1,75 @@
+package analyze
+
+import (
+	"api/response"
+	"bytes"
+	"encoding/binary"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxImageHeaderBytes bounds how much of an image is read to find its dimensions.
+const maxImageHeaderBytes = 64 * 1024
+
+// probeImage requests an image and reads its content type, size and dimensions from the image header.
+func probeImage(client *http.Client, link string) (*response.ImageProbe, error) {
+	probe := &response.ImageProbe{Url: link}
+	resp, err := probeGet(client, link)
+	if err != nil {
+		return probe, err
+	}
+	defer resp.Body.Close()
+
+	probe.Status = resp.StatusCode
+	probe.ContentType = strings.TrimSpace(strings.Split(resp.Header.Get("Content-Type"), ";")[0])
+	probe.ContentLength = resp.ContentLength
+	if resp.StatusCode != http.StatusOK {
+		return probe, nil
+	}
+
+	header, err := io.ReadAll(io.LimitReader(resp.Body, maxImageHeaderBytes))
+	if err != nil {
+		return probe, err
+	}
+	probe.Format, probe.Width, probe.Height = decodeImageHeader(header)
+	return probe, nil
+}
+
+// decodeImageHeader returns the format and dimensions of the GIF, JPEG, PNG or WebP image starting with header.
+func decodeImageHeader(header []byte) (string, int, int) {
+	if width, height, ok := decodeWebpHeader(header); ok {
+		return "webp", width, height
+	}
+	config, format, err := image.DecodeConfig(bytes.NewReader(header))
+	if err != nil {
+		return "", 0, 0
+	}
+	return format, config.Width, config.Height
+}
+
+// decodeWebpHeader reads the canvas size of a lossy, lossless or extended WebP image.
+func decodeWebpHeader(header []byte) (int, int, bool) {
+	if len(header) < 30 || string(header[0:4]) != "RIFF" || string(header[8:12]) != "WEBP" {
+		return 0, 0, false
+	}
+	chunk := header[12:16]
+	switch string(chunk) {
+	case "VP8 ":
+		width := int(binary.LittleEndian.Uint16(header[26:28]) & 0x3fff)
+		height := int(binary.LittleEndian.Uint16(header[28:30]) & 0x3fff)
+		return width, height, true
+	case "VP8L":
+		bits := binary.LittleEndian.Uint32(header[21:25])
+		return int(bits&0x3fff) + 1, int((bits>>14)&0x3fff) + 1, true
+	case "VP8X":
+		width := int(header[24]) | int(header[25])<<8 | int(header[26])<<16
+		height := int(header[27]) | int(header[28])<<8 | int(header[29])<<16
+		return width + 1, height + 1, true
+	}
+	return 0, 0, false
+}
@@ -0,0 +1,148 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"io"
+	"log"
+	"net/url"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// passiveContentTags lists the elements whose insecure subresources browsers only warn about.
+var passiveContentTags = map[string]bool{
+	"img":    true,
+	"audio":  true,
+	"video":  true,
+	"source": true,
+	"track":  true,
+	"input":  true,
+}
+
+// MixedContentAnalyzer implements the Analyzer interface for mixed content on HTTPS pages.
+type MixedContentAnalyzer struct{}
+
+// NewMixedContentAnalyzer creates a new MixedContentAnalyzer.
+func NewMixedContentAnalyzer() *MixedContentAnalyzer {
+	return &MixedContentAnalyzer{}
+}
+
+// Analyze reports the http:// subresources of an HTTPS page, split into active and passive mixed content.
+func (a *MixedContentAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing mixed content function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("MixedContentAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+
+	report := &response.MixedContentReport{
+		HttpsPage: strings.HasPrefix(strings.ToLower(pageURL), constant.HTTPS_SCHEME+"://"),
+	}
+	if report.HttpsPage {
+		FindMixedContent(html.NewTokenizer(strings.NewReader(wc.Content)), pageURL, report)
+	}
+	res.MixedContent = report
+	return nil
+}
+
+// FindMixedContent scans the tokens of the page and records every subresource loaded over plain HTTP.
+func FindMixedContent(tokenizer *html.Tokenizer, pageURL string, report *response.MixedContentReport) {
+	base, err := url.Parse(pageURL)
+	if err != nil {
+		return
+	}
+	position := newPositionTracker()
+
+	for {
+		tokenType := tokenizer.Next()
+		line, column := position.line, position.column
+		position.advance(tokenizer.Raw())
+
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				log.Printf("HTML tokenizer error: %v", tokenizer.Err())
+			}
+			report.ActiveCount = len(report.Active)
+			report.PassiveCount = len(report.Passive)
+			return
+		case html.StartTagToken, html.SelfClosingTagToken:
+			token := tokenizer.Token()
+			if token.Data == "base" {
+				// a <base href> changes how the relative URLs that follow are resolved
+				for _, attr := range token.Attr {
+					if attr.Key == constant.H_REF {
+						if baseURL, err := base.Parse(strings.TrimSpace(attr.Val)); err == nil {
+							base = baseURL
+						}
+					}
+				}
+				continue
+			}
+			for _, item := range insecureReferences(token, base) {
+				item.Line, item.Column = line, column
+				if passiveContentTags[token.Data] || isIconLink(token) {
+					report.Passive = append(report.Passive, item)
+				} else {
+					report.Active = append(report.Active, item)
+				}
+			}
+		}
+	}
+}
+
+// insecureReferences returns the subresource, srcset and form action URLs of a token that resolve to http://.
+func insecureReferences(token html.Token, base *url.URL) []response.MixedContent {
+	var refs []response.MixedContent
+	add := func(rawURL, attribute string) {
+		resolved, err := base.Parse(strings.TrimSpace(rawURL))
+		if err == nil && resolved.Scheme == constant.HTTP_SCHEME {
+			refs = append(refs, response.MixedContent{
+				Url:       resolved.String(),
+				Tag:       token.Data,
+				Attribute: attribute,
+			})
+		}
+	}
+
+	if rawURL, _, ok := subresourceURL(token.Data, token.Attr); ok {
+		add(rawURL, subresourceAttrs[token.Data])
+	}
+	for _, attr := range token.Attr {
+		switch {
+		case attr.Key == "srcset" && (token.Data == "img" || token.Data == "source"):
+			for _, candidate := range parseSrcset(attr.Val) {
+				add(candidate, attr.Key)
+			}
+		case attr.Key == "action" && token.Data == "form":
+			add(attr.Val, attr.Key)
+		}
+	}
+	return refs
+}
+
+// isIconLink reports whether the token is a <link> loading a favicon, which browsers treat as passive content.
+func isIconLink(token html.Token) bool {
+	if token.Data != "link" {
+		return false
+	}
+	for _, attr := range token.Attr {
+		if attr.Key == "rel" {
+			for _, rel := range strings.Fields(strings.ToLower(attr.Val)) {
+				if rel == "icon" {
+					return true
+				}
+			}
+		}
+	}
+	return false
+}
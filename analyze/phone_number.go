@@ -0,0 +1,116 @@
+package analyze
+
+import (
+	"api/constant"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	// phoneTextRegex matches international numbers starting with + or 00 and national numbers written in at
+	// least three groups, which keeps years, prices and other figures out.
+	phoneTextRegex = regexp.MustCompile(`(?:\+|\b00)[1-9][\d\s().\-/]{5,20}\d|` +
+		`\(?\b\d{2,5}\)?[\s.\-/]\d{2,4}[\s.\-/]\d{2,5}(?:[\s.\-/]\d{2,5})?\b`)
+	// dateLikeRegex matches the numeric dates phoneTextRegex would take for a national number.
+	dateLikeRegex = regexp.MustCompile(`^(?:\d{4}[-./]\d{1,2}[-./]\d{1,2}|\d{1,2}[-./]\d{1,2}[-./]\d{2,4})$`)
+	// ipv4Regex matches the dotted quads phoneTextRegex would take for a national number.
+	ipv4Regex = regexp.MustCompile(`^\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}$`)
+	// thousandsRegex matches figures grouped by thousands with spaces, such as the price 12 345 678.
+	thousandsRegex = regexp.MustCompile(`^[1-9]\d{0,2}(?: \d{3})+$`)
+)
+
+// isNumericFigure reports whether a national number match of phoneTextRegex is a date, an IPv4 address or a figure
+// grouped by thousands rather than a phone number.
+func isNumericFigure(match string) bool {
+	match = strings.TrimSpace(match)
+	return dateLikeRegex.MatchString(match) || ipv4Regex.MatchString(match) || thousandsRegex.MatchString(match)
+}
+
+// callingCode is the country calling code of a region with the trunk prefix dialled before national numbers.
+type callingCode struct {
+	code  string
+	trunk string
+}
+
+// callingCodes maps ISO 3166 region codes to their calling code. Italian numbers keep their leading zero.
+var callingCodes = map[string]callingCode{
+	"us": {"1", "1"}, "ca": {"1", "1"}, "gb": {"44", "0"}, "uk": {"44", "0"}, "ie": {"353", "0"},
+	"de": {"49", "0"}, "at": {"43", "0"}, "ch": {"41", "0"}, "fr": {"33", "0"}, "be": {"32", "0"},
+	"nl": {"31", "0"}, "lu": {"352", ""}, "es": {"34", ""}, "pt": {"351", ""}, "it": {"39", ""},
+	"se": {"46", "0"}, "no": {"47", ""}, "dk": {"45", ""}, "fi": {"358", "0"}, "pl": {"48", ""},
+	"cz": {"420", ""}, "gr": {"30", ""}, "tr": {"90", "0"}, "au": {"61", "0"}, "nz": {"64", "0"},
+	"in": {"91", "0"}, "lk": {"94", "0"}, "sg": {"65", ""}, "jp": {"81", "0"}, "kr": {"82", "0"},
+	"cn": {"86", "0"}, "br": {"55", "0"}, "mx": {"52", ""}, "za": {"27", "0"}, "ae": {"971", "0"},
+}
+
+// NormalizePhoneNumber returns the E.164 form of a phone number. International numbers need no region, national
+// numbers are resolved with the calling code of region.
+func NormalizePhoneNumber(raw, region string) (string, bool) {
+	raw = strings.TrimSpace(raw)
+	digits := strings.Map(func(r rune) rune {
+		if r >= '0' && r <= '9' {
+			return r
+		}
+		return -1
+	}, raw)
+	info, known := callingCodes[strings.ToLower(region)]
+
+	international := strings.HasPrefix(raw, "+")
+	switch {
+	case international:
+	case info.code == "1" && strings.HasPrefix(digits, "011"):
+		digits, international = digits[3:], true
+	case info.code != "1" && strings.HasPrefix(digits, "00"):
+		digits, international = digits[2:], true
+	}
+	if international {
+		if len(digits) < 7 || len(digits) > 15 || digits[0] == '0' {
+			return constant.EMPTY, false
+		}
+		return "+" + digits, true
+	}
+
+	if !known {
+		return constant.EMPTY, false
+	}
+	national := digits
+	if info.code == "1" {
+		if len(national) == 11 && national[0] == '1' {
+			national = national[1:]
+		}
+		if len(national) != 10 || national[0] < '2' {
+			return constant.EMPTY, false
+		}
+		return "+1" + national, true
+	}
+	if info.trunk != constant.EMPTY {
+		national = strings.TrimPrefix(national, info.trunk)
+	}
+	if len(national) < 6 || len(info.code)+len(national) > 15 || (info.trunk != constant.EMPTY && national[0] == '0') {
+		return constant.EMPTY, false
+	}
+	return "+" + info.code + national, true
+}
+
+// phoneRegion guesses the region of the national phone numbers of a page from the country code top-level domain of
+// its host, then from the region subtag of its language.
+func phoneRegion(pageURL, lang string) string {
+	if parsedURL, err := url.Parse(pageURL); err == nil {
+		host := parsedURL.Hostname()
+		if index := strings.LastIndex(host, "."); index >= 0 {
+			if tld := strings.ToLower(host[index+1:]); len(tld) == 2 {
+				if _, ok := callingCodes[tld]; ok {
+					return tld
+				}
+			}
+		}
+	}
+	parts := strings.FieldsFunc(lang, func(r rune) bool { return r == '-' || r == '_' })
+	for _, part := range parts[min(1, len(parts)):] {
+		if _, ok := callingCodes[strings.ToLower(part)]; ok && len(part) == 2 {
+			return strings.ToLower(part)
+		}
+	}
+	return constant.EMPTY
+}
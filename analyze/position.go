@@ -0,0 +1,65 @@
+package analyze
+
+import (
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// positionTracker follows the line and column of an html.Tokenizer through its raw input.
+type positionTracker struct {
+	line   int
+	column int
+}
+
+func newPositionTracker() *positionTracker {
+	return &positionTracker{line: 1, column: 1}
+}
+
+// advance moves the position past the raw bytes of the current token.
+func (p *positionTracker) advance(raw []byte) {
+	for _, b := range raw {
+		if b == '\n' {
+			p.line++
+			p.column = 1
+		} else {
+			p.column++
+		}
+	}
+}
+
+// mapSourceLines returns the line of the start tag of every element of doc parsed from content. The start tags
+// are matched to the elements by tag name in document order, elements implied by the parser get no line.
+func mapSourceLines(content string, doc *html.Node) map[*html.Node]int {
+	tagLines := make(map[string][]int)
+	tokenizer := html.NewTokenizer(strings.NewReader(content))
+	position := newPositionTracker()
+	for {
+		tokenType := tokenizer.Next()
+		if tokenType == html.ErrorToken {
+			break
+		}
+		line := position.line
+		position.advance(tokenizer.Raw())
+		if tokenType == html.StartTagToken || tokenType == html.SelfClosingTagToken {
+			name, _ := tokenizer.TagName()
+			tagLines[string(name)] = append(tagLines[string(name)], line)
+		}
+	}
+
+	lines := make(map[*html.Node]int)
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if queue := tagLines[n.Data]; len(queue) > 0 {
+				lines[n] = queue[0]
+				tagLines[n.Data] = queue[1:]
+			}
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return lines
+}
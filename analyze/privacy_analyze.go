@@ -0,0 +1,231 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+var (
+	// scriptURLRegex matches the absolute and protocol relative URLs written in inline scripts.
+	scriptURLRegex = regexp.MustCompile(`(?i)(?:https?:)?//[a-z0-9-]+(?:\.[a-z0-9-]+)+(?:/[^\s'"\\)]*)?`)
+	// consentModeRegex matches the default command of Google Consent Mode.
+	consentModeRegex = regexp.MustCompile(`gtag\(\s*['"]consent['"]\s*,\s*['"]default['"]`)
+)
+
+// trackerTags lists the elements that load a tracker when the page renders.
+var trackerTags = map[string]bool{
+	"script": true, "iframe": true, "img": true, "link": true, "embed": true, "object": true,
+}
+
+// consentGateAttrs lists the attributes consent-management platforms use to hold elements back until consent.
+var consentGateAttrs = []string{
+	"data-cookieconsent", "data-cookiecategory", "data-category", "data-consent", "data-cookie-consent",
+	"data-usercentrics", "data-categories",
+}
+
+// PrivacyAnalyzer implements the Analyzer interface for third-party trackers and consent management.
+type PrivacyAnalyzer struct{}
+
+// NewPrivacyAnalyzer creates a new PrivacyAnalyzer.
+func NewPrivacyAnalyzer() *PrivacyAnalyzer {
+	return &PrivacyAnalyzer{}
+}
+
+// Analyze lists the trackers of the page by category, the consent-management platforms and the trackers loading
+// without an apparent consent gate.
+func (a *PrivacyAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing privacy function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("PrivacyAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing privacy",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	res.Privacy = AnalyzePrivacy(doc, wc.Content, pageURL, loadTrackerList())
+	return nil
+}
+
+// AnalyzePrivacy matches the hosts collected by extractLinks and the URLs of inline scripts against the tracker list.
+func AnalyzePrivacy(doc *html.Node, content, pageURL string, list *TrackerList) *response.PrivacyReport {
+	var data LinkAnalyzeData
+	extractLinks(doc, pageURL, &data)
+
+	var inlineScripts []*html.Node
+	for _, script := range findElements(doc, "script") {
+		if !hasAttr(script, constant.SRC) {
+			inlineScripts = append(inlineScripts, script)
+		}
+	}
+
+	report := &response.PrivacyReport{}
+	report.ConsentPlatforms = detectConsentPlatforms(data.Sources, content, list)
+	for _, script := range inlineScripts {
+		if consentModeRegex.MatchString(nodeText(script)) {
+			report.ConsentMode = true
+		}
+	}
+
+	trackers := make(map[string]*response.TrackerInfo)
+	thirdPartyHosts := make(map[string]bool)
+	record := func(link, tag string, node *html.Node) {
+		tracker := list.MatchTracker(link)
+		if tracker == nil {
+			return
+		}
+		info, ok := trackers[tracker.Name]
+		if !ok {
+			info = &response.TrackerInfo{Name: tracker.Name, Company: tracker.Company, Category: tracker.Category, Gated: true}
+			trackers[tracker.Name] = info
+		}
+		info.Hosts = appendUnique(info.Hosts, hostOf(link))
+		info.Urls = appendUnique(info.Urls, link)
+		info.Tags = appendUnique(info.Tags, tag)
+		// Google tags honour the consent mode defaults set before they load
+		if !consentGated(node) && !(report.ConsentMode && tracker.Company == "Google") {
+			info.Gated = false
+		}
+	}
+
+	for _, source := range data.Sources {
+		if isThirdParty(source.Url, pageURL) {
+			thirdPartyHosts[source.Host] = true
+		}
+		if !trackerTags[source.Node.Data] {
+			continue
+		}
+		if source.Node.Data == "link" {
+			if _, _, loads := subresourceURL("link", source.Node.Attr); !loads {
+				continue
+			}
+		}
+		record(source.Url, source.Node.Data, source.Node)
+	}
+	for _, script := range inlineScripts {
+		for _, match := range scriptURLRegex.FindAllString(nodeText(script), -1) {
+			if strings.HasPrefix(match, "//") {
+				match = constant.HTTPS_SCHEME + ":" + match
+			}
+			record(match, "inline-script", script)
+		}
+	}
+
+	for _, tracker := range trackers {
+		report.Trackers = append(report.Trackers, *tracker)
+	}
+	sort.Slice(report.Trackers, func(i, j int) bool { return report.Trackers[i].Name < report.Trackers[j].Name })
+	report.Summary = summarizePrivacy(report.Trackers, len(thirdPartyHosts))
+	report.Findings = auditPrivacy(report)
+	return report
+}
+
+// detectConsentPlatforms finds the consent-management platforms by the hosts the page loads from and their markers.
+func detectConsentPlatforms(sources []LinkSource, content string, list *TrackerList) []string {
+	var platforms []string
+	for _, platform := range list.ConsentPlatforms {
+		found := false
+		for _, source := range sources {
+			if source.Node.Data == "script" && matchesAnyDomain(source.Url, platform.Domains) {
+				found = true
+				break
+			}
+		}
+		for _, marker := range platform.Markers {
+			if !found && strings.Contains(content, marker) {
+				found = true
+			}
+		}
+		if found {
+			platforms = append(platforms, platform.Name)
+		}
+	}
+	sort.Strings(platforms)
+	return platforms
+}
+
+// consentGated reports whether the element is held back until consent: a script with a non JavaScript type such as
+// text/plain, or an element marked with the attributes or classes of a consent-management platform.
+func consentGated(n *html.Node) bool {
+	if n.Data == "script" {
+		scriptType := strings.ToLower(strings.TrimSpace(getAttr(n, "type")))
+		if scriptType != "module" && !javaScriptTypes[scriptType] {
+			return true
+		}
+	}
+	for _, attr := range consentGateAttrs {
+		if hasAttr(n, attr) {
+			return true
+		}
+	}
+	class := getAttr(n, "class")
+	return strings.Contains(class, "optanon-category-") || strings.Contains(class, "cmplz-")
+}
+
+func summarizePrivacy(trackers []response.TrackerInfo, thirdPartyHosts int) response.PrivacySummary {
+	summary := response.PrivacySummary{
+		TrackerCount:    len(trackers),
+		ThirdPartyHosts: thirdPartyHosts,
+		Categories:      make(map[string]int),
+		Rating:          constant.PRIVACY_CLEAN,
+	}
+	companies := make(map[string]bool)
+	for _, tracker := range trackers {
+		summary.Categories[tracker.Category]++
+		companies[tracker.Company] = true
+		if !tracker.Gated {
+			summary.UngatedCount++
+		}
+	}
+	for company := range companies {
+		summary.Companies = append(summary.Companies, company)
+	}
+	sort.Strings(summary.Companies)
+
+	switch {
+	case summary.UngatedCount > 0:
+		summary.Rating = constant.PRIVACY_EXPOSED
+	case summary.TrackerCount > 0:
+		summary.Rating = constant.PRIVACY_GATED
+	}
+	return summary
+}
+
+func auditPrivacy(report *response.PrivacyReport) []response.Finding {
+	var findings []response.Finding
+	if len(report.Trackers) > 0 && len(report.ConsentPlatforms) == 0 && !report.ConsentMode {
+		findings = append(findings, newFinding("consent-platform-missing", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Page loads %d trackers but no consent-management platform was found", len(report.Trackers))))
+	}
+	for _, tracker := range report.Trackers {
+		if !tracker.Gated {
+			findings = append(findings, newFinding("tracker-without-consent", constant.SEVERITY_WARNING,
+				fmt.Sprintf("%s (%s) loads without an apparent consent gate", tracker.Name, tracker.Category)))
+		}
+		if tracker.Category == "session_replay" {
+			findings = append(findings, newFinding("session-replay", constant.SEVERITY_INFO,
+				tracker.Name+" records user sessions, make sure form inputs are masked"))
+		}
+	}
+	return findings
+}
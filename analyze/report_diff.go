@@ -0,0 +1,404 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"slices"
+	"sort"
+	"strings"
+)
+
+// maxSectionChanges bounds the changes listed per report section.
+const maxSectionChanges = 50
+
+// diffedFields are the report fields compared by dedicated diffs, and the fields that differ between any two runs.
+var diffedFields = map[string]bool{
+	"htmlVersion":         true,
+	"title":               true,
+	"pageStatus":          true,
+	"headings":            true,
+	"headingCounts":       true,
+	"headingOutline":      true,
+	"urls":                true,
+	"hasLogin":            true,
+	"serviceTime":         true,
+	"webPageExtractTime":  true,
+	"executedUrl":         true,
+	"basePath":            true,
+	"appExecuteTotalTime": true,
+	"reportId":            true,
+}
+
+// volatileFields are nested fields that change between runs of an unchanged page.
+var volatileFields = map[string]bool{
+	"urlExecutionTime": true,
+	"expires":          true,
+	"daysRemaining":    true,
+}
+
+// CompareReports returns the differences of target from base. When the reports are of different sites, such as
+// staging and production, URLs of each site are made relative to it so the same pages compare equal.
+func CompareReports(base, target *response.SuccessResponse) *response.ComparisonReport {
+	report := &response.ComparisonReport{
+		BaseUrl:   base.ExecutedUrl,
+		TargetUrl: target.ExecutedUrl,
+		Changes:   []response.ValueChange{},
+		Sections:  []response.SectionDiff{},
+	}
+	baseSite, targetSite := constant.EMPTY, constant.EMPTY
+	if !strings.EqualFold(base.BasePath, target.BasePath) {
+		baseSite, targetSite = base.BasePath, target.BasePath
+	}
+
+	compareValue(report, "htmlVersion", base.HtmlVersion, target.HtmlVersion)
+	compareValue(report, "title", base.Title, target.Title)
+	compareValue(report, "pageStatus", base.PageStatus, target.PageStatus)
+	compareValue(report, "hasLogin", base.HasLogin, target.HasLogin)
+	report.Headings = compareHeadings(base.Headings, target.Headings)
+	report.Links = compareLinks(base.Urls, target.Urls, baseSite, targetSite)
+	report.Sections = compareSections(base, target, baseSite, targetSite)
+	report.Summary = summarizeComparison(report)
+	return report
+}
+
+// compareValue records a change of a top level value.
+func compareValue(report *response.ComparisonReport, path string, base, target any) {
+	if base != target {
+		report.Changes = append(report.Changes, response.ValueChange{
+			Path: path, Kind: constant.CHANGE_CHANGED, Base: base, Target: target,
+		})
+	}
+}
+
+// compareHeadings matches the headings by tag and text. An unmatched heading removed from a level is paired with
+// one added to the same level and reported as changed.
+func compareHeadings(base, target []response.Heading) response.HeadingDiff {
+	diff := response.HeadingDiff{
+		Added:   []response.Heading{},
+		Removed: []response.Heading{},
+		Changed: []response.HeadingChange{},
+	}
+	remaining := make(map[response.Heading]int)
+	for _, heading := range target {
+		remaining[heading]++
+	}
+	var removed []response.Heading
+	for _, heading := range base {
+		if remaining[heading] > 0 {
+			remaining[heading]--
+			continue
+		}
+		removed = append(removed, heading)
+	}
+	var added []response.Heading
+	for _, heading := range target {
+		if remaining[heading] > 0 {
+			remaining[heading]--
+			added = append(added, heading)
+		}
+	}
+
+	for _, heading := range removed {
+		index := slices.IndexFunc(added, func(candidate response.Heading) bool {
+			return candidate.Tag == heading.Tag
+		})
+		if index < 0 {
+			diff.Removed = append(diff.Removed, heading)
+			continue
+		}
+		diff.Changed = append(diff.Changed, response.HeadingChange{
+			Tag: heading.Tag, Base: heading.Text, Target: added[index].Text,
+		})
+		added = slices.Delete(added, index, index+1)
+	}
+	diff.Added = append(diff.Added, added...)
+	return diff
+}
+
+// compareLinks compares the links of the pages by URL and reports the status changes of the links on both.
+func compareLinks(base, target []response.Url, baseSite, targetSite string) response.LinkDiff {
+	diff := response.LinkDiff{
+		Added:         []string{},
+		Removed:       []string{},
+		StatusChanged: []response.LinkStatusChange{},
+		NewlyBroken:   []string{},
+		Fixed:         []string{},
+	}
+	baseLinks, baseOrder := indexLinks(base, baseSite)
+	targetLinks, targetOrder := indexLinks(target, targetSite)
+
+	for _, link := range baseOrder {
+		if _, ok := targetLinks[link]; !ok {
+			diff.Removed = append(diff.Removed, link)
+		}
+	}
+	for _, link := range targetOrder {
+		targetLink := targetLinks[link]
+		baseLink, ok := baseLinks[link]
+		if !ok {
+			diff.Added = append(diff.Added, link)
+			if brokenLink(targetLink) {
+				diff.NewlyBroken = append(diff.NewlyBroken, link)
+			}
+			continue
+		}
+		if baseLink.Status == targetLink.Status {
+			continue
+		}
+		diff.StatusChanged = append(diff.StatusChanged, response.LinkStatusChange{
+			Url: link, BaseStatus: baseLink.Status, TargetStatus: targetLink.Status,
+		})
+		switch {
+		case brokenLink(targetLink) && !brokenLink(baseLink):
+			diff.NewlyBroken = append(diff.NewlyBroken, link)
+		case brokenLink(baseLink) && !brokenLink(targetLink):
+			diff.Fixed = append(diff.Fixed, link)
+		}
+	}
+	return diff
+}
+
+// indexLinks maps the links of a page by their URL, relative to site when it is set, in page order.
+func indexLinks(urls []response.Url, site string) (map[string]response.Url, []string) {
+	links := make(map[string]response.Url, len(urls))
+	var order []string
+	for _, link := range urls {
+		key := relativeToSite(link.Url, site)
+		if _, seen := links[key]; seen {
+			continue
+		}
+		links[key] = link
+		order = append(order, key)
+	}
+	return links, order
+}
+
+// brokenLink reports whether a probed link answered with an error status.
+func brokenLink(link response.Url) bool {
+	return link.Status >= http.StatusBadRequest
+}
+
+// relativeToSite strips the site origin from a URL of that site.
+func relativeToSite(link, site string) string {
+	if site == constant.EMPTY || len(link) < len(site) || !strings.EqualFold(link[:len(site)], site) {
+		return link
+	}
+	if relative := link[len(site):]; relative != constant.EMPTY {
+		return relative
+	}
+	return "/"
+}
+
+// compareSections diffs the remaining report sections, in report order, on their JSON form.
+func compareSections(base, target *response.SuccessResponse, baseSite, targetSite string) []response.SectionDiff {
+	baseTree := reportTree(base, baseSite)
+	targetTree := reportTree(target, targetSite)
+	sections := []response.SectionDiff{}
+
+	reportType := reflect.TypeOf(response.SuccessResponse{})
+	for i := 0; i < reportType.NumField(); i++ {
+		name := strings.Split(reportType.Field(i).Tag.Get("json"), ",")[0]
+		if name == constant.EMPTY || name == "-" || diffedFields[name] {
+			continue
+		}
+		var changes []response.ValueChange
+		diffTree(name, baseTree[name], targetTree[name], &changes)
+		if len(changes) == 0 {
+			continue
+		}
+		section := response.SectionDiff{Section: name, Changes: changes}
+		if len(changes) > maxSectionChanges {
+			section.Changes, section.Truncated = changes[:maxSectionChanges], true
+		}
+		sections = append(sections, section)
+	}
+	return sections
+}
+
+// reportTree returns the JSON form of a report without its volatile fields, with URLs of site made relative.
+func reportTree(res *response.SuccessResponse, site string) map[string]any {
+	data, err := json.Marshal(res)
+	if err != nil {
+		return nil
+	}
+	var tree map[string]any
+	if err := json.Unmarshal(data, &tree); err != nil {
+		return nil
+	}
+	return normalizeTree(tree, site).(map[string]any)
+}
+
+// normalizeTree drops the volatile fields of a JSON value, treats empty arrays as absent and makes the URLs of site
+// relative.
+func normalizeTree(value any, site string) any {
+	switch typed := value.(type) {
+	case map[string]any:
+		for key, child := range typed {
+			if volatileFields[key] {
+				delete(typed, key)
+				continue
+			}
+			typed[key] = normalizeTree(child, site)
+		}
+	case []any:
+		if len(typed) == 0 {
+			return nil
+		}
+		for i, child := range typed {
+			typed[i] = normalizeTree(child, site)
+		}
+	case string:
+		return relativeToSite(typed, site)
+	}
+	return value
+}
+
+// diffTree appends the differences of two JSON values. Objects are compared key by key, arrays as unordered
+// collections whose elements are added or removed, also when the other side is absent, and anything else by value.
+func diffTree(path string, base, target any, changes *[]response.ValueChange) {
+	if base == nil && target == nil {
+		return
+	}
+
+	baseArray, baseIsArray := base.([]any)
+	targetArray, targetIsArray := target.([]any)
+	if (baseIsArray || base == nil) && (targetIsArray || target == nil) {
+		diffArrays(path+"[]", baseArray, targetArray, changes)
+		return
+	}
+
+	switch {
+	case base == nil:
+		*changes = append(*changes, response.ValueChange{Path: path, Kind: constant.CHANGE_ADDED, Target: target})
+		return
+	case target == nil:
+		*changes = append(*changes, response.ValueChange{Path: path, Kind: constant.CHANGE_REMOVED, Base: base})
+		return
+	}
+
+	baseObject, baseIsObject := base.(map[string]any)
+	targetObject, targetIsObject := target.(map[string]any)
+	if baseIsObject && targetIsObject {
+		keys := make(map[string]bool)
+		for key := range baseObject {
+			keys[key] = true
+		}
+		for key := range targetObject {
+			keys[key] = true
+		}
+		sortedKeys := make([]string, 0, len(keys))
+		for key := range keys {
+			sortedKeys = append(sortedKeys, key)
+		}
+		sort.Strings(sortedKeys)
+		for _, key := range sortedKeys {
+			diffTree(path+"."+key, baseObject[key], targetObject[key], changes)
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(base, target) {
+		*changes = append(*changes, response.ValueChange{
+			Path: path, Kind: constant.CHANGE_CHANGED, Base: base, Target: target,
+		})
+	}
+}
+
+// diffArrays reports the elements only one of the arrays holds, matching equal elements once each.
+func diffArrays(path string, base, target []any, changes *[]response.ValueChange) {
+	remaining := make(map[string]int)
+	for _, element := range target {
+		remaining[elementKey(element)]++
+	}
+	for _, element := range base {
+		key := elementKey(element)
+		if remaining[key] > 0 {
+			remaining[key]--
+			continue
+		}
+		*changes = append(*changes, response.ValueChange{Path: path, Kind: constant.CHANGE_REMOVED, Base: element})
+	}
+	for _, element := range target {
+		key := elementKey(element)
+		if remaining[key] > 0 {
+			remaining[key]--
+			*changes = append(*changes, response.ValueChange{Path: path, Kind: constant.CHANGE_ADDED, Target: element})
+		}
+	}
+}
+
+// elementKey is the canonical JSON of an array element, map keys are sorted by the encoder.
+func elementKey(element any) string {
+	data, _ := json.Marshal(element)
+	return string(data)
+}
+
+// summarizeComparison describes the notable differences in short sentences.
+func summarizeComparison(report *response.ComparisonReport) []string {
+	var summary []string
+	for _, change := range report.Changes {
+		switch change.Path {
+		case "title":
+			summary = append(summary, fmt.Sprintf("Title changed from %q to %q", change.Base, change.Target))
+		case "pageStatus":
+			summary = append(summary, fmt.Sprintf("Page status changed from %v to %v", change.Base, change.Target))
+		case "htmlVersion":
+			summary = append(summary, fmt.Sprintf("HTML version changed from %v to %v", change.Base, change.Target))
+		case "hasLogin":
+			if change.Target == true {
+				summary = append(summary, "Login form appeared")
+			} else {
+				summary = append(summary, "Login form disappeared")
+			}
+		}
+	}
+
+	for _, heading := range report.Headings.Removed {
+		summary = append(summary, fmt.Sprintf("Lost %s %q", heading.Tag, heading.Text))
+	}
+	for _, heading := range report.Headings.Added {
+		summary = append(summary, fmt.Sprintf("New %s %q", heading.Tag, heading.Text))
+	}
+	for _, heading := range report.Headings.Changed {
+		summary = append(summary, fmt.Sprintf("%s changed from %q to %q", heading.Tag, heading.Base, heading.Target))
+	}
+
+	links := report.Links
+	if len(links.Added) > 0 {
+		summary = append(summary, countSentence(len(links.Added), "new link", "new links"))
+	}
+	if len(links.Removed) > 0 {
+		summary = append(summary, countSentence(len(links.Removed), "removed link", "removed links"))
+	}
+	if len(links.NewlyBroken) > 0 {
+		summary = append(summary, countSentence(len(links.NewlyBroken), "new broken link", "new broken links"))
+	}
+	if len(links.Fixed) > 0 {
+		summary = append(summary, countSentence(len(links.Fixed), "fixed broken link", "fixed broken links"))
+	}
+
+	for _, section := range report.Sections {
+		count := countSentence(len(section.Changes), "change", "changes")
+		if section.Truncated {
+			count = fmt.Sprintf("more than %d changes", maxSectionChanges)
+		}
+		summary = append(summary, fmt.Sprintf("%s: %s", section.Section, count))
+	}
+
+	if len(summary) == 0 {
+		summary = append(summary, "No differences")
+	}
+	return summary
+}
+
+// countSentence formats a count with the singular or plural noun.
+func countSentence(count int, singular, plural string) string {
+	if count == 1 {
+		return "1 " + singular
+	}
+	return fmt.Sprintf("%d %s", count, plural)
+}
@@ -0,0 +1,326 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// maxResourceBytes bounds how much of a script or stylesheet is read to measure its transfer size.
+const maxResourceBytes = 10 * 1024 * 1024
+
+// javaScriptTypes lists the script type values that browsers execute as classic scripts.
+var javaScriptTypes = map[string]bool{
+	"": true, "text/javascript": true, "application/javascript": true, "application/x-javascript": true,
+	"text/ecmascript": true, "application/ecmascript": true, "text/jscript": true,
+}
+
+// ResourceAnalyzer implements the Analyzer interface for the inventory of scripts and stylesheets.
+type ResourceAnalyzer struct{}
+
+// NewResourceAnalyzer creates a new ResourceAnalyzer.
+func NewResourceAnalyzer() *ResourceAnalyzer {
+	return &ResourceAnalyzer{}
+}
+
+// Analyze lists the scripts and stylesheets of the page with their loading behaviour, groups the external ones by
+// third-party host and flags cross-origin scripts loaded without subresource integrity.
+func (a *ResourceAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing scripts and stylesheets function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("ResourceAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing scripts and stylesheets",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	report := ExtractResources(doc, pageURL)
+	if configs.GetConfig().LinkProbe {
+		probeTransferSizes(probeClient(wc), report.Scripts, report.Stylesheets)
+	}
+	SummarizeResources(report, pageURL)
+	res.Resources = report
+	return nil
+}
+
+// ExtractResources lists every JavaScript <script>, <link rel=stylesheet> and <style> of the document in document order.
+func ExtractResources(doc *html.Node, pageURL string) *response.ResourceReport {
+	report := &response.ResourceReport{}
+	for _, node := range findElements(doc, "script", "link", "style") {
+		inHead := hasAncestor(node, "head")
+		switch node.Data {
+		case "script":
+			scriptType := strings.ToLower(strings.TrimSpace(strings.Split(getAttr(node, "type"), ";")[0]))
+			module := scriptType == "module"
+			if !module && !javaScriptTypes[scriptType] {
+				continue
+			}
+			info := response.ResourceInfo{
+				Async:       hasAttr(node, "async"),
+				Defer:       hasAttr(node, "defer"),
+				Module:      module,
+				InHead:      inHead,
+				Integrity:   strings.TrimSpace(getAttr(node, "integrity")),
+				CrossOrigin: crossOriginValue(node),
+			}
+			if src := strings.TrimSpace(getAttr(node, constant.SRC)); src != constant.EMPTY {
+				info.Url = resolveURL(src, pageURL)
+				info.ThirdParty = isThirdParty(info.Url, pageURL)
+				// modules are deferred by default and async and defer only apply to external scripts
+				info.RenderBlocking = inHead && !info.Async && !info.Defer && !module
+			} else {
+				info.Inline = true
+				info.InlineSize = len(nodeText(node))
+			}
+			report.Scripts = append(report.Scripts, info)
+		case "link":
+			if !hasToken(getAttr(node, "rel"), "stylesheet") || hasToken(getAttr(node, "rel"), "alternate") {
+				continue
+			}
+			href := strings.TrimSpace(getAttr(node, constant.H_REF))
+			if href == constant.EMPTY {
+				continue
+			}
+			info := response.ResourceInfo{
+				Url:         resolveURL(href, pageURL),
+				Media:       getAttr(node, "media"),
+				InHead:      inHead,
+				Integrity:   strings.TrimSpace(getAttr(node, "integrity")),
+				CrossOrigin: crossOriginValue(node),
+			}
+			info.ThirdParty = isThirdParty(info.Url, pageURL)
+			info.RenderBlocking = inHead && !hasAttr(node, "disabled") && blockingMedia(info.Media)
+			report.Stylesheets = append(report.Stylesheets, info)
+		case "style":
+			report.Stylesheets = append(report.Stylesheets, response.ResourceInfo{
+				Inline:     true,
+				InlineSize: len(nodeText(node)),
+				Media:      getAttr(node, "media"),
+				InHead:     inHead,
+			})
+		}
+	}
+	return report
+}
+
+// SummarizeResources groups the external resources by third-party host, totals the transfer sizes and reports
+// render-blocking scripts and cross-origin resources without subresource integrity.
+func SummarizeResources(report *response.ResourceReport, pageURL string) {
+	groups := make(map[string]*response.ThirdPartyGroup)
+	report.RenderBlocking, report.TotalBytes = 0, 0
+	report.ThirdParties, report.Findings = nil, nil
+
+	collect := func(resources []response.ResourceInfo, script bool) {
+		for _, resource := range resources {
+			report.TotalBytes += resource.TransferSize
+			if resource.RenderBlocking {
+				report.RenderBlocking++
+			}
+			if resource.Inline {
+				continue
+			}
+
+			if resource.ThirdParty {
+				host := hostOf(resource.Url)
+				group, ok := groups[host]
+				if !ok {
+					group = &response.ThirdPartyGroup{Host: host, Domain: registrableDomain(host)}
+					groups[host] = group
+				}
+				if script {
+					group.Scripts++
+				} else {
+					group.Stylesheets++
+				}
+				group.TransferSize += resource.TransferSize
+				group.Urls = append(group.Urls, resource.Url)
+			}
+			report.Findings = append(report.Findings, auditResource(resource, pageURL, script)...)
+		}
+	}
+	collect(report.Scripts, true)
+	collect(report.Stylesheets, false)
+
+	for _, group := range groups {
+		report.ThirdParties = append(report.ThirdParties, *group)
+	}
+	sort.Slice(report.ThirdParties, func(i, j int) bool {
+		return report.ThirdParties[i].Host < report.ThirdParties[j].Host
+	})
+}
+
+// auditResource flags a single external script or stylesheet.
+func auditResource(resource response.ResourceInfo, pageURL string, script bool) []response.Finding {
+	var findings []response.Finding
+	kind := "Stylesheet"
+	if script {
+		kind = "Script"
+	}
+
+	crossOrigin := isCrossOrigin(resource.Url, pageURL)
+	switch {
+	case crossOrigin && resource.Integrity == constant.EMPTY && script:
+		findings = append(findings, newFinding("script-missing-sri", constant.SEVERITY_WARNING,
+			"Cross-origin script is loaded without an integrity attribute: "+resource.Url))
+	case crossOrigin && resource.Integrity == constant.EMPTY:
+		findings = append(findings, newFinding("stylesheet-missing-sri", constant.SEVERITY_INFO,
+			"Cross-origin stylesheet is loaded without an integrity attribute: "+resource.Url))
+	case crossOrigin && resource.CrossOrigin == constant.EMPTY:
+		findings = append(findings, newFinding("sri-missing-crossorigin", constant.SEVERITY_ERROR,
+			kind+" has an integrity attribute but no crossorigin attribute, the browser will block it: "+resource.Url))
+	}
+	if script && resource.RenderBlocking {
+		findings = append(findings, newFinding("script-render-blocking", constant.SEVERITY_INFO,
+			"Script in <head> blocks rendering, consider async or defer: "+resource.Url))
+	}
+	if resource.Status != 0 && resource.Status != http.StatusOK {
+		findings = append(findings, newFinding("resource-broken", constant.SEVERITY_ERROR,
+			fmt.Sprintf("%s %s returned status %d", kind, resource.Url, resource.Status)))
+	}
+	return findings
+}
+
+// probeTransferSizes requests every distinct external resource once and records its status and the number of
+// bytes sent over the wire.
+func probeTransferSizes(client *http.Client, groups ...[]response.ResourceInfo) {
+	type result struct {
+		status int
+		size   int64
+	}
+	results := make(map[string]*result)
+	seen := make(map[string]bool)
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, maxConcurrentProbes)
+
+	for _, resources := range groups {
+		for _, resource := range resources {
+			if seen[resource.Url] || resource.Inline || !isHTTPURL(resource.Url) {
+				continue
+			}
+			seen[resource.Url] = true
+			wg.Add(1)
+			go func(link string) {
+				defer wg.Done()
+				limit <- struct{}{}
+				defer func() { <-limit }()
+
+				status, size, err := measureTransferSize(client, link)
+				if err != nil {
+					log.Printf("Failed probing resource: %s | Error: %v", link, err)
+				}
+				mu.Lock()
+				results[link] = &result{status: status, size: size}
+				mu.Unlock()
+			}(resource.Url)
+		}
+	}
+	wg.Wait()
+
+	for _, resources := range groups {
+		for i := range resources {
+			if probe := results[resources[i].Url]; probe != nil {
+				resources[i].Status, resources[i].TransferSize = probe.status, probe.size
+			}
+		}
+	}
+}
+
+// measureTransferSize requests the resource accepting compression, so the body is read as sent over the wire.
+func measureTransferSize(client *http.Client, link string) (int, int64, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	// setting Accept-Encoding stops the transport from transparently decompressing the body
+	req.Header.Set("Accept-Encoding", "gzip, deflate, br")
+	resp, err := sendProbe(client, req)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer resp.Body.Close()
+
+	size, err := io.Copy(io.Discard, io.LimitReader(resp.Body, maxResourceBytes))
+	return resp.StatusCode, size, err
+}
+
+// blockingMedia reports whether a stylesheet with the media attribute blocks the first render of a screen.
+func blockingMedia(media string) bool {
+	media = strings.ToLower(strings.TrimSpace(media))
+	return media == constant.EMPTY || strings.Contains(media, "all") || strings.Contains(media, "screen")
+}
+
+// crossOriginValue returns the CORS mode of the element, an empty crossorigin attribute means anonymous.
+func crossOriginValue(n *html.Node) string {
+	if !hasAttr(n, "crossorigin") {
+		return constant.EMPTY
+	}
+	if value := strings.ToLower(strings.TrimSpace(getAttr(n, "crossorigin"))); value == "use-credentials" {
+		return value
+	}
+	return "anonymous"
+}
+
+// isCrossOrigin reports whether link has another scheme, host or port than pageURL.
+func isCrossOrigin(link, pageURL string) bool {
+	linkURL, err := url.Parse(link)
+	if err != nil || linkURL.Host == constant.EMPTY {
+		return false
+	}
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	return !strings.EqualFold(linkURL.Scheme, page.Scheme) || !strings.EqualFold(linkURL.Host, page.Host)
+}
+
+// hasAncestor reports whether one of the ancestors of n is a tag element.
+func hasAncestor(n *html.Node, tag string) bool {
+	for parent := n.Parent; parent != nil; parent = parent.Parent {
+		if parent.Type == html.ElementNode && parent.Data == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// hasToken reports whether the space separated list contains the token, ignoring case.
+func hasToken(list, token string) bool {
+	for _, value := range strings.Fields(list) {
+		if strings.EqualFold(value, token) {
+			return true
+		}
+	}
+	return false
+}
+
+func hostOf(link string) string {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return constant.EMPTY
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
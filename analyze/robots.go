@@ -0,0 +1,331 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"bufio"
+	"errors"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxRobotsBytes is the part of a robots.txt file crawlers must parse, the rest is ignored (RFC 9309).
+	maxRobotsBytes = 500 * 1024
+	// robotsCacheTTL is how long a robots.txt file is reused before it is requested again.
+	robotsCacheTTL = 24 * time.Hour
+	// maxRobotsEntries bounds the hosts whose robots.txt is cached, the least recently used is dropped first.
+	maxRobotsEntries = 1000
+)
+
+// RobotsTxt holds the parsed robots.txt file of a host.
+type RobotsTxt struct {
+	Url    string
+	Status int
+	Error  string
+	// Unreachable is set when the file could not be fetched because of a server or network error, crawlers then
+	// treat the whole host as disallowed
+	Unreachable bool
+	Sitemaps    []string
+	groups      []robotsGroup
+}
+
+// robotsGroup is a set of rules shared by one or more user agents.
+type robotsGroup struct {
+	agents     []string
+	rules      []robotsRule
+	crawlDelay float64
+}
+
+// robotsRule is an allow or disallow line with its path pattern compiled to a regular expression.
+type robotsRule struct {
+	allow   bool
+	pattern string
+	regex   *regexp.Regexp
+}
+
+// errProbeDisallowed is returned by sendProbe for a link robots.txt does not allow.
+var errProbeDisallowed = errors.New("disallowed by robots.txt")
+
+// robotsCache keeps the robots.txt files per scheme and host, so the analyzed page and every probed link of the
+// same host share one request. The usedAt times of the entries are guarded by its lock.
+var robotsCache = struct {
+	sync.Mutex
+	entries map[string]*robotsEntry
+}{entries: make(map[string]*robotsEntry)}
+
+// robotsEntry is a cached robots.txt file. Its lock makes concurrent lookups for the same host wait for one fetch.
+type robotsEntry struct {
+	sync.Mutex
+	robots    *RobotsTxt
+	fetchedAt time.Time
+	usedAt    time.Time
+}
+
+// LoadRobots returns the robots.txt file of the host of link, fetching it when it is not cached or has expired.
+// A file that could not be fetched expires after the configured retry time instead of the cache TTL, so a transient
+// failure does not disallow the host for a day. It returns nil when link is not an http(s) URL.
+func LoadRobots(client *http.Client, link string) *RobotsTxt {
+	parsedURL, err := url.Parse(link)
+	if err != nil || parsedURL.Host == constant.EMPTY ||
+		(parsedURL.Scheme != constant.HTTP_SCHEME && parsedURL.Scheme != constant.HTTPS_SCHEME) {
+		return nil
+	}
+	key := parsedURL.Scheme + "://" + strings.ToLower(parsedURL.Host)
+
+	robotsCache.Lock()
+	entry, ok := robotsCache.entries[key]
+	if !ok {
+		if len(robotsCache.entries) >= maxRobotsEntries {
+			evictRobots()
+		}
+		entry = &robotsEntry{}
+		robotsCache.entries[key] = entry
+	}
+	entry.usedAt = time.Now()
+	robotsCache.Unlock()
+
+	entry.Lock()
+	defer entry.Unlock()
+	ttl := robotsCacheTTL
+	if entry.robots != nil && entry.robots.Unreachable {
+		ttl = configs.GetConfig().RobotsRetry
+	}
+	if entry.robots == nil || time.Since(entry.fetchedAt) >= ttl {
+		entry.robots = fetchRobots(client, key+"/robots.txt")
+		entry.fetchedAt = time.Now()
+	}
+	return entry.robots
+}
+
+// evictRobots drops the least recently used entry of the robots cache. The caller holds the cache lock.
+func evictRobots() {
+	var oldestKey string
+	var oldest time.Time
+	for key, entry := range robotsCache.entries {
+		if oldestKey == constant.EMPTY || entry.usedAt.Before(oldest) {
+			oldestKey, oldest = key, entry.usedAt
+		}
+	}
+	delete(robotsCache.entries, oldestKey)
+}
+
+// fetchRobots requests a robots.txt file. A missing file allows everything, a server error or an unreachable host
+// disallows everything.
+func fetchRobots(client *http.Client, robotsURL string) *RobotsTxt {
+	resp, err := client.Get(robotsURL)
+	if err != nil {
+		log.Printf("Failed fetching robots.txt: %s | Error: %v", robotsURL, err)
+		return &RobotsTxt{Url: robotsURL, Error: err.Error(), Unreachable: true}
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode >= http.StatusInternalServerError:
+		return &RobotsTxt{Url: robotsURL, Status: resp.StatusCode, Unreachable: true}
+	case resp.StatusCode != http.StatusOK:
+		return &RobotsTxt{Url: robotsURL, Status: resp.StatusCode}
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxRobotsBytes))
+	if err != nil {
+		return &RobotsTxt{Url: robotsURL, Status: resp.StatusCode, Error: err.Error(), Unreachable: true}
+	}
+	robots := ParseRobots(string(body))
+	robots.Url, robots.Status = robotsURL, resp.StatusCode
+	return robots
+}
+
+// ParseRobots parses the groups, rules and sitemaps of a robots.txt file. Lines it does not understand are skipped.
+func ParseRobots(content string) *RobotsTxt {
+	robots := &RobotsTxt{}
+	var group *robotsGroup
+	inRules := false
+
+	scanner := bufio.NewScanner(strings.NewReader(strings.TrimPrefix(content, "\uFEFF")))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxRobotsBytes)
+	for scanner.Scan() {
+		line, _, _ := strings.Cut(scanner.Text(), "#")
+		key, value, found := strings.Cut(line, ":")
+		if !found {
+			continue
+		}
+		key, value = strings.ToLower(strings.TrimSpace(key)), strings.TrimSpace(value)
+
+		switch key {
+		case "user-agent":
+			// consecutive user-agent lines share the group, one after a rule starts a new group
+			if group == nil || inRules {
+				robots.groups = append(robots.groups, robotsGroup{})
+				group, inRules = &robots.groups[len(robots.groups)-1], false
+			}
+			group.agents = append(group.agents, robotsProductToken(value))
+		case "allow", "disallow":
+			if group == nil {
+				continue
+			}
+			inRules = true
+			if value != constant.EMPTY {
+				rule := robotsRule{allow: key == "allow", pattern: value, regex: compileRobotsPattern(value)}
+				group.rules = append(group.rules, rule)
+			}
+		case "crawl-delay":
+			if group == nil {
+				continue
+			}
+			inRules = true
+			if delay, err := strconv.ParseFloat(value, 64); err == nil && delay >= 0 {
+				group.crawlDelay = delay
+			}
+		case "sitemap":
+			if value != constant.EMPTY {
+				robots.Sitemaps = appendUnique(robots.Sitemaps, value)
+			}
+		}
+	}
+	return robots
+}
+
+// compileRobotsPattern turns a path pattern into an anchored regular expression, * matching any characters and a
+// trailing $ the end of the path.
+func compileRobotsPattern(pattern string) *regexp.Regexp {
+	anchored := strings.HasSuffix(pattern, "$")
+	parts := strings.Split(strings.TrimSuffix(pattern, "$"), "*")
+	for i, part := range parts {
+		parts[i] = regexp.QuoteMeta(part)
+	}
+	expression := "^" + strings.Join(parts, ".*")
+	if anchored {
+		expression += "$"
+	}
+	return regexp.MustCompile(expression)
+}
+
+// robotsProductToken returns the lowercase product token of a user agent, the name before any version or comment.
+func robotsProductToken(agent string) string {
+	agent = strings.TrimSpace(agent)
+	if index := strings.IndexAny(agent, "/ ("); index > 0 {
+		agent = agent[:index]
+	}
+	return strings.ToLower(agent)
+}
+
+// groupsFor returns the groups that apply to agent: the ones naming its product token, otherwise the * groups.
+func (r *RobotsTxt) groupsFor(agent string) []robotsGroup {
+	token := robotsProductToken(agent)
+	var named, fallback []robotsGroup
+	for _, group := range r.groups {
+		switch {
+		case slices.Contains(group.agents, token):
+			named = append(named, group)
+		case slices.Contains(group.agents, "*"):
+			fallback = append(fallback, group)
+		}
+	}
+	if len(named) > 0 {
+		return named
+	}
+	return fallback
+}
+
+// Allowed reports whether agent may request link and returns the rule that decided it. The longest matching rule
+// wins and allow wins a tie. /robots.txt itself is always allowed.
+func (r *RobotsTxt) Allowed(agent, link string) (bool, string) {
+	if r == nil {
+		return true, constant.EMPTY
+	}
+	if r.Unreachable {
+		return false, "robots.txt unreachable"
+	}
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return true, constant.EMPTY
+	}
+	path := parsedURL.EscapedPath()
+	if path == constant.EMPTY {
+		path = "/"
+	}
+	if path == "/robots.txt" {
+		return true, constant.EMPTY
+	}
+	if parsedURL.RawQuery != constant.EMPTY {
+		path += "?" + parsedURL.RawQuery
+	}
+
+	var best *robotsRule
+	for _, group := range r.groupsFor(agent) {
+		for i := range group.rules {
+			rule := &group.rules[i]
+			if !rule.regex.MatchString(path) {
+				continue
+			}
+			if best == nil || len(rule.pattern) > len(best.pattern) ||
+				(len(rule.pattern) == len(best.pattern) && rule.allow && !best.allow) {
+				best = rule
+			}
+		}
+	}
+	if best == nil {
+		return true, constant.EMPTY
+	}
+	if best.allow {
+		return true, "Allow: " + best.pattern
+	}
+	return false, "Disallow: " + best.pattern
+}
+
+// CrawlDelay returns the crawl delay in seconds the groups of agent ask for, zero when none is set.
+func (r *RobotsTxt) CrawlDelay(agent string) float64 {
+	if r == nil {
+		return 0
+	}
+	delay := 0.0
+	for _, group := range r.groupsFor(agent) {
+		delay = max(delay, group.crawlDelay)
+	}
+	return delay
+}
+
+// CrawlAllowed reports whether link may be requested and the rule that decided it. It is always true when
+// robots.txt is not honored.
+func CrawlAllowed(link string) (bool, string) {
+	cfg := configs.GetConfig()
+	if !cfg.HonorRobots {
+		return true, constant.EMPTY
+	}
+	return LoadRobots(cfg.Client, link).Allowed(cfg.UserAgent, link)
+}
+
+// sendProbe sends a request the analyzers make for a resource of the page, such as an image, a script, a feed or a
+// hreflang alternate, unless robots.txt disallows its URL. It returns errProbeDisallowed without sending it then.
+func sendProbe(client *http.Client, req *http.Request) (*http.Response, error) {
+	if allowed, _ := CrawlAllowed(req.URL.String()); !allowed {
+		return nil, errProbeDisallowed
+	}
+	return client.Do(req)
+}
+
+// probeGet is sendProbe for a plain GET request of link.
+func probeGet(client *http.Client, link string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodGet, link, nil)
+	if err != nil {
+		return nil, err
+	}
+	return sendProbe(client, req)
+}
+
+// probeClient returns the client the probes of the page are sent with.
+func probeClient(wc *response.WebContent) *http.Client {
+	if wc.Client != nil {
+		return wc.Client
+	}
+	return configs.GetConfig().Client
+}
@@ -0,0 +1,125 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// RobotsAnalyzer implements the Analyzer interface for the robots.txt rules and the sitemaps of the page's host.
+type RobotsAnalyzer struct{}
+
+// NewRobotsAnalyzer creates a new RobotsAnalyzer.
+func NewRobotsAnalyzer() *RobotsAnalyzer {
+	return &RobotsAnalyzer{}
+}
+
+// Analyze reports whether robots.txt disallows the page and validates the sitemaps it declares.
+func (a *RobotsAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing robots.txt function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("RobotsAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	cfg := configs.GetConfig()
+	robots := LoadRobots(cfg.Client, pageURL)
+	report := BuildRobotsReport(robots, pageURL, cfg.UserAgent, cfg.HonorRobots)
+	if robots != nil && cfg.LinkProbe && len(robots.Sitemaps) > 0 {
+		report.Sitemaps = fetchSitemaps(probeClient(wc), robots.Sitemaps, pageURL)
+	}
+	AuditRobots(report)
+	res.Robots = report
+	return nil
+}
+
+// BuildRobotsReport describes the robots.txt file and whether it allows agent to request the page. The sitemaps
+// are listed without being fetched.
+func BuildRobotsReport(robots *RobotsTxt, pageURL, agent string, honored bool) *response.RobotsReport {
+	report := &response.RobotsReport{UserAgent: agent, Honored: honored}
+	if robots == nil {
+		return report
+	}
+	report.Url, report.Status, report.Error = robots.Url, robots.Status, robots.Error
+	report.Found = robots.Status == http.StatusOK
+	allowed, rule := robots.Allowed(agent, pageURL)
+	report.Disallowed, report.MatchedRule = !allowed, rule
+	report.CrawlDelay = robots.CrawlDelay(agent)
+	for _, link := range robots.Sitemaps {
+		report.Sitemaps = append(report.Sitemaps, response.SitemapInfo{Url: link})
+	}
+	return report
+}
+
+// AuditRobots reports a missing or unreachable robots.txt, a disallowed page and missing, broken or invalid
+// sitemaps, and whether the sitemaps list the page.
+func AuditRobots(report *response.RobotsReport) {
+	if report.Url == constant.EMPTY {
+		return
+	}
+	switch {
+	case report.Error != constant.EMPTY || report.Status >= http.StatusInternalServerError:
+		report.Findings = append(report.Findings, newFinding("robots-unreachable", constant.SEVERITY_WARNING,
+			"robots.txt could not be fetched, crawlers treat the whole host as disallowed"))
+	case !report.Found:
+		report.Findings = append(report.Findings, newFinding("robots-missing", constant.SEVERITY_INFO,
+			fmt.Sprintf("robots.txt returned status %d, crawlers treat the whole host as allowed", report.Status)))
+	}
+	if report.Disallowed {
+		report.Findings = append(report.Findings, newFinding("robots-disallowed", constant.SEVERITY_WARNING,
+			fmt.Sprintf("The page is disallowed for %s by %s", report.UserAgent, report.MatchedRule)))
+	}
+	if report.Found && len(report.Sitemaps) == 0 {
+		report.Findings = append(report.Findings, newFinding("sitemap-not-declared", constant.SEVERITY_INFO,
+			"robots.txt declares no sitemap"))
+	}
+
+	complete, fetched := true, false
+	var audit func(sitemaps []response.SitemapInfo)
+	audit = func(sitemaps []response.SitemapInfo) {
+		for _, sitemap := range sitemaps {
+			switch {
+			case sitemap.Status == 0 && sitemap.Error == constant.EMPTY:
+				complete = false
+				continue
+			case sitemap.Status == 0:
+				report.Findings = append(report.Findings, newFinding("sitemap-broken", constant.SEVERITY_ERROR,
+					fmt.Sprintf("Sitemap %s could not be fetched: %s", sitemap.Url, sitemap.Error)))
+				complete = false
+			case sitemap.Status != http.StatusOK:
+				report.Findings = append(report.Findings, newFinding("sitemap-broken", constant.SEVERITY_ERROR,
+					fmt.Sprintf("Sitemap %s returned status %d", sitemap.Url, sitemap.Status)))
+				complete = false
+			case !sitemap.Valid:
+				report.Findings = append(report.Findings, newFinding("sitemap-invalid", constant.SEVERITY_ERROR,
+					fmt.Sprintf("Sitemap %s could not be parsed: %s", sitemap.Url, sitemap.Error)))
+				complete = false
+			case sitemap.Type == constant.SITEMAP_URLSET && sitemap.Urls > maxSitemapUrls:
+				report.Findings = append(report.Findings, newFinding("sitemap-too-large", constant.SEVERITY_WARNING,
+					fmt.Sprintf("Sitemap %s lists %d URLs, the limit is %d", sitemap.Url, sitemap.Urls, maxSitemapUrls)))
+			}
+			fetched = true
+			report.PageInSitemap = report.PageInSitemap || sitemap.ListsPage
+			if sitemap.Skipped > 0 {
+				complete = false
+			}
+			audit(sitemap.Sitemaps)
+		}
+	}
+	audit(report.Sitemaps)
+
+	// the page can only be said to be missing when every sitemap was read
+	if fetched && complete && !report.PageInSitemap {
+		report.Findings = append(report.Findings, newFinding("page-not-in-sitemap", constant.SEVERITY_INFO,
+			"The page is not listed in the declared sitemaps"))
+	}
+}
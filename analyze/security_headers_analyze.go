@@ -0,0 +1,342 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	hstsMinMaxAge          = 15552000 // 180 days
+	hstsMaxScore           = 25
+	cspMaxScore            = 25
+	frameOptionsMaxScore   = 15
+	contentTypeMaxScore    = 10
+	referrerMaxScore       = 10
+	permissionsMaxScore    = 5
+	crossOriginOpenerMax   = 4
+	crossOriginEmbedMax    = 3
+	crossOriginResourceMax = 3
+)
+
+// SecurityHeadersAnalyzer implements the Analyzer interface for HTTP security response headers.
+type SecurityHeadersAnalyzer struct{}
+
+// NewSecurityHeadersAnalyzer creates a new SecurityHeadersAnalyzer.
+func NewSecurityHeadersAnalyzer() *SecurityHeadersAnalyzer {
+	return &SecurityHeadersAnalyzer{}
+}
+
+// Analyze grades the security headers returned with the page response.
+func (a *SecurityHeadersAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing security headers function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("SecurityHeadersAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	headers := wc.Headers
+	if headers == nil {
+		headers = http.Header{}
+	}
+	isHttps := strings.HasPrefix(strings.ToLower(wc.FinalUrl), constant.HTTPS_SCHEME+"://")
+	res.SecurityHeaders = GradeSecurityHeaders(headers, isHttps)
+	return nil
+}
+
+// GradeSecurityHeaders evaluates each security header and computes the overall score and grade.
+func GradeSecurityHeaders(headers http.Header, isHttps bool) *response.SecurityHeaders {
+	result := &response.SecurityHeaders{}
+
+	csp, cspFinding := checkContentSecurityPolicy(headers)
+	result.Csp = csp
+	result.Headers = []response.HeaderFinding{
+		checkStrictTransportSecurity(headers, isHttps),
+		cspFinding,
+		checkFrameOptions(headers, csp),
+		checkContentTypeOptions(headers),
+		checkReferrerPolicy(headers),
+		checkPresenceOnly(headers, "Permissions-Policy", permissionsMaxScore, constant.SEVERITY_WARNING,
+			"Permissions-Policy is missing, browser features are not restricted"),
+		checkAllowedValues(headers, "Cross-Origin-Opener-Policy", crossOriginOpenerMax,
+			[]string{"same-origin", "same-origin-allow-popups"}),
+		checkAllowedValues(headers, "Cross-Origin-Embedder-Policy", crossOriginEmbedMax,
+			[]string{"require-corp", "credentialless"}),
+		checkAllowedValues(headers, "Cross-Origin-Resource-Policy", crossOriginResourceMax,
+			[]string{"same-origin", "same-site"}),
+	}
+
+	for _, header := range result.Headers {
+		result.Score += header.Score
+		result.MaxScore += header.MaxScore
+	}
+	result.Grade = securityGrade(result.Score, result.MaxScore)
+	return result
+}
+
+// ParseContentSecurityPolicy splits a CSP header value into its directives and source lists.
+func ParseContentSecurityPolicy(value string) []response.CspDirective {
+	var directives []response.CspDirective
+	for _, part := range strings.Split(value, ";") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		directives = append(directives, response.CspDirective{
+			Name:    strings.ToLower(fields[0]),
+			Sources: fields[1:],
+		})
+	}
+	return directives
+}
+
+func checkStrictTransportSecurity(headers http.Header, isHttps bool) response.HeaderFinding {
+	finding := newHeaderFinding(headers, "Strict-Transport-Security", hstsMaxScore)
+	if !finding.Present {
+		finding.Findings = append(finding.Findings, newFinding("hsts-missing", constant.SEVERITY_ERROR,
+			"Strict-Transport-Security is missing, connections can be downgraded to HTTP"))
+		return finding
+	}
+	if !isHttps {
+		finding.Findings = append(finding.Findings, newFinding("hsts-over-http", constant.SEVERITY_WARNING,
+			"Strict-Transport-Security is ignored by browsers when served over HTTP"))
+		return finding
+	}
+
+	maxAge := -1
+	var includeSubDomains bool
+	for _, part := range strings.Split(finding.Value, ";") {
+		directive := strings.ToLower(strings.TrimSpace(part))
+		if strings.HasPrefix(directive, "max-age=") {
+			if age, err := strconv.Atoi(strings.Trim(strings.TrimPrefix(directive, "max-age="), `"`)); err == nil {
+				maxAge = age
+			}
+		}
+		if directive == "includesubdomains" {
+			includeSubDomains = true
+		}
+	}
+
+	switch {
+	case maxAge <= 0:
+		finding.Findings = append(finding.Findings, newFinding("hsts-max-age", constant.SEVERITY_ERROR,
+			"Strict-Transport-Security has no valid max-age"))
+	case maxAge < hstsMinMaxAge:
+		finding.Score = hstsMaxScore / 2
+		finding.Findings = append(finding.Findings, newFinding("hsts-max-age", constant.SEVERITY_WARNING,
+			"Strict-Transport-Security max-age is shorter than 180 days"))
+	default:
+		finding.Score = hstsMaxScore
+	}
+	if finding.Score > 0 && !includeSubDomains {
+		finding.Findings = append(finding.Findings, newFinding("hsts-subdomains", constant.SEVERITY_INFO,
+			"Strict-Transport-Security does not include subdomains"))
+	}
+	return finding
+}
+
+func checkContentSecurityPolicy(headers http.Header) (*response.ContentSecurity, response.HeaderFinding) {
+	finding := newHeaderFinding(headers, "Content-Security-Policy", cspMaxScore)
+	csp := &response.ContentSecurity{}
+	if !finding.Present {
+		reportOnly := headers.Get("Content-Security-Policy-Report-Only")
+		if reportOnly == constant.EMPTY {
+			finding.Findings = append(finding.Findings, newFinding("csp-missing", constant.SEVERITY_ERROR,
+				"Content-Security-Policy is missing"))
+			return nil, finding
+		}
+		csp.ReportOnly = true
+		finding.Value = reportOnly
+		finding.Findings = append(finding.Findings, newFinding("csp-report-only", constant.SEVERITY_WARNING,
+			"Content-Security-Policy is only set in report-only mode and is not enforced"))
+	}
+
+	csp.Directives = ParseContentSecurityPolicy(finding.Value)
+	for _, directive := range csp.Directives {
+		for _, source := range directive.Sources {
+			switch strings.ToLower(source) {
+			case "'unsafe-inline'":
+				csp.UnsafeInline = true
+			case "'unsafe-eval'":
+				csp.UnsafeEval = true
+			}
+			if isWildcardSource(source) {
+				csp.WildcardSources = append(csp.WildcardSources, directive.Name+" "+source)
+			}
+		}
+	}
+
+	if csp.ReportOnly {
+		return csp, finding
+	}
+	finding.Score = cspMaxScore
+	if csp.UnsafeInline {
+		finding.Score -= 10
+		finding.Findings = append(finding.Findings, newFinding("csp-unsafe-inline", constant.SEVERITY_WARNING,
+			"Content-Security-Policy allows 'unsafe-inline'"))
+	}
+	if csp.UnsafeEval {
+		finding.Score -= 5
+		finding.Findings = append(finding.Findings, newFinding("csp-unsafe-eval", constant.SEVERITY_WARNING,
+			"Content-Security-Policy allows 'unsafe-eval'"))
+	}
+	if len(csp.WildcardSources) > 0 {
+		finding.Score -= 10
+		finding.Findings = append(finding.Findings, newFinding("csp-wildcard", constant.SEVERITY_WARNING,
+			"Content-Security-Policy contains wildcard sources: "+strings.Join(csp.WildcardSources, ", ")))
+	}
+	if finding.Score < 0 {
+		finding.Score = 0
+	}
+	return csp, finding
+}
+
+// isWildcardSource reports whether a CSP source contains a host wildcard or allows any host of a scheme.
+func isWildcardSource(source string) bool {
+	switch strings.ToLower(source) {
+	case "http:", "https:", "data:", "blob:":
+		return true
+	}
+	return strings.Contains(source, "*")
+}
+
+func checkFrameOptions(headers http.Header, csp *response.ContentSecurity) response.HeaderFinding {
+	finding := newHeaderFinding(headers, "X-Frame-Options", frameOptionsMaxScore)
+	if csp != nil && !csp.ReportOnly {
+		for _, directive := range csp.Directives {
+			if directive.Name == "frame-ancestors" {
+				finding.Score = frameOptionsMaxScore
+				finding.Findings = append(finding.Findings, newFinding("frame-ancestors", constant.SEVERITY_INFO,
+					"Framing is controlled by the Content-Security-Policy frame-ancestors directive"))
+				return finding
+			}
+		}
+	}
+	if !finding.Present {
+		finding.Findings = append(finding.Findings, newFinding("frame-options-missing", constant.SEVERITY_ERROR,
+			"X-Frame-Options and frame-ancestors are missing, the page can be framed (clickjacking)"))
+		return finding
+	}
+
+	switch strings.ToUpper(strings.TrimSpace(finding.Value)) {
+	case "DENY", "SAMEORIGIN":
+		finding.Score = frameOptionsMaxScore
+	default:
+		finding.Findings = append(finding.Findings, newFinding("frame-options-invalid", constant.SEVERITY_WARNING,
+			"X-Frame-Options should be DENY or SAMEORIGIN"))
+	}
+	return finding
+}
+
+func checkContentTypeOptions(headers http.Header) response.HeaderFinding {
+	finding := newHeaderFinding(headers, "X-Content-Type-Options", contentTypeMaxScore)
+	if !finding.Present {
+		finding.Findings = append(finding.Findings, newFinding("content-type-options-missing", constant.SEVERITY_WARNING,
+			"X-Content-Type-Options is missing, browsers may MIME-sniff responses"))
+		return finding
+	}
+	if strings.EqualFold(strings.TrimSpace(finding.Value), "nosniff") {
+		finding.Score = contentTypeMaxScore
+		return finding
+	}
+	finding.Findings = append(finding.Findings, newFinding("content-type-options-invalid", constant.SEVERITY_WARNING,
+		"X-Content-Type-Options should be nosniff"))
+	return finding
+}
+
+func checkReferrerPolicy(headers http.Header) response.HeaderFinding {
+	finding := newHeaderFinding(headers, "Referrer-Policy", referrerMaxScore)
+	if !finding.Present {
+		finding.Findings = append(finding.Findings, newFinding("referrer-policy-missing", constant.SEVERITY_WARNING,
+			"Referrer-Policy is missing, the browser default policy applies"))
+		return finding
+	}
+
+	// the last recognised policy in a comma separated list wins
+	policies := strings.Split(finding.Value, ",")
+	policy := strings.ToLower(strings.TrimSpace(policies[len(policies)-1]))
+	switch policy {
+	case "no-referrer", "same-origin", "strict-origin", "strict-origin-when-cross-origin":
+		finding.Score = referrerMaxScore
+	case "origin", "origin-when-cross-origin":
+		finding.Score = referrerMaxScore / 2
+		finding.Findings = append(finding.Findings, newFinding("referrer-policy-weak", constant.SEVERITY_INFO,
+			"Referrer-Policy leaks the origin on downgrade to HTTP"))
+	default:
+		finding.Findings = append(finding.Findings, newFinding("referrer-policy-unsafe", constant.SEVERITY_WARNING,
+			"Referrer-Policy "+policy+" can leak full URLs to other origins"))
+	}
+	return finding
+}
+
+func checkPresenceOnly(headers http.Header, name string, maxScore int, severity, message string) response.HeaderFinding {
+	finding := newHeaderFinding(headers, name, maxScore)
+	if finding.Present {
+		finding.Score = maxScore
+		return finding
+	}
+	finding.Findings = append(finding.Findings, newFinding(strings.ToLower(name)+"-missing", severity, message))
+	return finding
+}
+
+func checkAllowedValues(headers http.Header, name string, maxScore int, allowed []string) response.HeaderFinding {
+	finding := newHeaderFinding(headers, name, maxScore)
+	if !finding.Present {
+		finding.Findings = append(finding.Findings, newFinding(strings.ToLower(name)+"-missing", constant.SEVERITY_INFO,
+			name+" is missing"))
+		return finding
+	}
+	value := strings.ToLower(strings.TrimSpace(finding.Value))
+	for _, candidate := range allowed {
+		if value == candidate {
+			finding.Score = maxScore
+			return finding
+		}
+	}
+	finding.Findings = append(finding.Findings, newFinding(strings.ToLower(name)+"-weak", constant.SEVERITY_INFO,
+		name+" should be one of "+strings.Join(allowed, ", ")))
+	return finding
+}
+
+func newHeaderFinding(headers http.Header, name string, maxScore int) response.HeaderFinding {
+	value := headers.Get(name)
+	return response.HeaderFinding{
+		Header:   name,
+		Present:  value != constant.EMPTY,
+		Value:    value,
+		MaxScore: maxScore,
+	}
+}
+
+func newFinding(rule, severity, message string) response.Finding {
+	return response.Finding{
+		Rule:     rule,
+		Severity: severity,
+		Message:  message,
+	}
+}
+
+// securityGrade maps the percentage score to a letter grade.
+func securityGrade(score, maxScore int) string {
+	if maxScore == 0 {
+		return "F"
+	}
+	percent := score * 100 / maxScore
+	switch {
+	case percent >= 90:
+		return "A"
+	case percent >= 75:
+		return "B"
+	case percent >= 60:
+		return "C"
+	case percent >= 40:
+		return "D"
+	default:
+		return "F"
+	}
+}
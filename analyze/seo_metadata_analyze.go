@@ -0,0 +1,373 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"io"
+	"log"
+	"mime"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	minTitleLength          = 30
+	maxTitleLength          = 60
+	maxTitlePixelWidth      = 580
+	minDescriptionLength    = 70
+	maxDescriptionLength    = 160
+	maxDescriptionPixelSize = 920
+	titleFontSize           = 20
+	descriptionFontSize     = 14
+	maxHreflangProbes       = 10
+	maxHreflangPageBytes    = 2 * 1024 * 1024
+)
+
+var (
+	hreflangRegex   = regexp.MustCompile(`(?i)^(x-default|[a-z]{2,3}(-[a-z]{4})?(-([a-z]{2}|\d{3}))?)$`)
+	linkHeaderRegex = regexp.MustCompile(`<([^>]*)>\s*;([^,]*)`)
+)
+
+// SeoMetadataAnalyzer implements the Analyzer interface for the SEO metadata of the page.
+type SeoMetadataAnalyzer struct{}
+
+// NewSeoMetadataAnalyzer creates a new SeoMetadataAnalyzer.
+func NewSeoMetadataAnalyzer() *SeoMetadataAnalyzer {
+	return &SeoMetadataAnalyzer{}
+}
+
+// Analyze extracts the description, robots directives, canonical URL, hreflang alternates, pagination
+// links and viewport, and reports the issues found with their severity.
+func (a *SeoMetadataAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing SEO metadata function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("SeoMetadataAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing SEO metadata",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	seo := ExtractSeoMetadata(doc, wc.Headers, pageURL)
+	if configs.GetConfig().LinkProbe {
+		checkHreflangReciprocity(probeClient(wc), seo.Hreflang, pageURL)
+	}
+	seo.Findings = append(seo.Findings, auditHreflangReciprocity(seo.Hreflang)...)
+	res.Seo = seo
+	return nil
+}
+
+// ExtractSeoMetadata reads the SEO metadata from the document and the X-Robots-Tag and Link headers.
+func ExtractSeoMetadata(doc *html.Node, headers http.Header, pageURL string) *response.SeoMetadata {
+	seo := &response.SeoMetadata{}
+	var canonicals []string
+
+	for _, node := range findElements(doc, "title", "meta", "link") {
+		switch node.Data {
+		case "title":
+			if seo.Title == constant.EMPTY {
+				seo.Title = normalizeSpace(nodeText(node))
+			}
+		case "meta":
+			name := strings.ToLower(getAttr(node, "name"))
+			content := getAttr(node, "content")
+			switch name {
+			case "description":
+				seo.Description = normalizeSpace(content)
+			case "robots":
+				seo.Robots = append(seo.Robots, parseRobotsDirectives(content)...)
+			case "googlebot":
+				seo.Googlebot = append(seo.Googlebot, parseRobotsDirectives(content)...)
+			case "viewport":
+				seo.Viewport = content
+			}
+		case "link":
+			href := resolveURL(strings.TrimSpace(getAttr(node, constant.H_REF)), pageURL)
+			for _, rel := range strings.Fields(strings.ToLower(getAttr(node, "rel"))) {
+				switch rel {
+				case "canonical":
+					canonicals = append(canonicals, href)
+				case "alternate":
+					if lang := getAttr(node, "hreflang"); lang != constant.EMPTY {
+						seo.Hreflang = append(seo.Hreflang, response.HreflangLink{Lang: lang, Url: href})
+					}
+				case "prev", "previous":
+					seo.Prev = href
+				case "next":
+					seo.Next = href
+				}
+			}
+		}
+	}
+
+	for _, value := range headers.Values("X-Robots-Tag") {
+		seo.XRobotsTag = append(seo.XRobotsTag, parseRobotsDirectives(value)...)
+	}
+	headerCanonicals := parseLinkHeader(headers.Values("Link"), "canonical", pageURL)
+
+	seo.TitleLength = utf8.RuneCountInString(seo.Title)
+	seo.TitlePixelWidth = EstimatePixelWidth(seo.Title, titleFontSize)
+	seo.DescriptionLength = utf8.RuneCountInString(seo.Description)
+	seo.DescriptionPixelWidth = EstimatePixelWidth(seo.Description, descriptionFontSize)
+
+	directives := append(append(append([]string{}, seo.Robots...), seo.Googlebot...), seo.XRobotsTag...)
+	seo.Indexable = !hasDirective(directives, "noindex") && !hasDirective(directives, "none")
+	seo.Followable = !hasDirective(directives, "nofollow") && !hasDirective(directives, "none")
+
+	seo.Canonical, seo.CanonicalStatus = classifyCanonical(append(canonicals, headerCanonicals...), pageURL)
+	seo.Findings = auditSeoMetadata(seo, pageURL, len(canonicals)+len(headerCanonicals))
+	return seo
+}
+
+// classifyCanonical compares the declared canonical URLs with the page URL.
+func classifyCanonical(canonicals []string, pageURL string) (string, string) {
+	if len(canonicals) == 0 {
+		return constant.EMPTY, constant.CANONICAL_MISSING
+	}
+	canonical := canonicals[0]
+	for _, other := range canonicals[1:] {
+		if !samePageURL(other, canonical) {
+			return canonical, constant.CANONICAL_CONFLICTING
+		}
+	}
+	switch {
+	case samePageURL(canonical, pageURL):
+		return canonical, constant.CANONICAL_SELF
+	case isThirdParty(canonical, pageURL):
+		return canonical, constant.CANONICAL_CROSS_DOMAIN
+	default:
+		return canonical, constant.CANONICAL_SAME_DOMAIN
+	}
+}
+
+// auditSeoMetadata turns the extracted metadata into findings with a severity.
+func auditSeoMetadata(seo *response.SeoMetadata, pageURL string, canonicalCount int) []response.Finding {
+	var findings []response.Finding
+	add := func(rule, severity, message string) {
+		findings = append(findings, newFinding(rule, severity, message))
+	}
+
+	switch {
+	case seo.Title == constant.EMPTY:
+		add("seo-title-missing", constant.SEVERITY_ERROR, "Page has no title")
+	case seo.TitleLength < minTitleLength:
+		add("seo-title-short", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Title is %d characters, aim for %d-%d", seo.TitleLength, minTitleLength, maxTitleLength))
+	case seo.TitleLength > maxTitleLength || seo.TitlePixelWidth > maxTitlePixelWidth:
+		add("seo-title-long", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Title is %d characters (about %d px) and may be truncated in search results", seo.TitleLength, seo.TitlePixelWidth))
+	}
+
+	switch {
+	case seo.Description == constant.EMPTY:
+		add("seo-description-missing", constant.SEVERITY_ERROR, "Page has no meta description")
+	case seo.DescriptionLength < minDescriptionLength:
+		add("seo-description-short", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Meta description is %d characters, aim for %d-%d", seo.DescriptionLength, minDescriptionLength, maxDescriptionLength))
+	case seo.DescriptionLength > maxDescriptionLength || seo.DescriptionPixelWidth > maxDescriptionPixelSize:
+		add("seo-description-long", constant.SEVERITY_INFO,
+			fmt.Sprintf("Meta description is %d characters (about %d px) and may be truncated in search results", seo.DescriptionLength, seo.DescriptionPixelWidth))
+	}
+
+	if !seo.Indexable {
+		add("seo-noindex", constant.SEVERITY_WARNING, "Page is excluded from search indexes by a noindex directive")
+	}
+	if !seo.Followable {
+		add("seo-nofollow", constant.SEVERITY_INFO, "Links on the page are not followed because of a nofollow directive")
+	}
+
+	switch seo.CanonicalStatus {
+	case constant.CANONICAL_MISSING:
+		add("seo-canonical-missing", constant.SEVERITY_INFO, "Page does not declare a canonical URL")
+	case constant.CANONICAL_CONFLICTING:
+		add("seo-canonical-conflicting", constant.SEVERITY_ERROR,
+			fmt.Sprintf("Page declares %d different canonical URLs", canonicalCount))
+	case constant.CANONICAL_CROSS_DOMAIN:
+		add("seo-canonical-cross-domain", constant.SEVERITY_WARNING, "Canonical URL points to another domain: "+seo.Canonical)
+	case constant.CANONICAL_SAME_DOMAIN:
+		add("seo-canonical-other-page", constant.SEVERITY_INFO, "Canonical URL points to another page: "+seo.Canonical)
+	}
+	if !seo.Indexable && seo.CanonicalStatus == constant.CANONICAL_SAME_DOMAIN {
+		add("seo-canonical-noindex", constant.SEVERITY_WARNING, "Page is noindex and also canonicalized to another page")
+	}
+
+	if len(seo.Hreflang) > 0 {
+		var hasSelf, hasDefault bool
+		seen := make(map[string]bool)
+		for _, alternate := range seo.Hreflang {
+			lang := strings.ToLower(alternate.Lang)
+			if !hreflangRegex.MatchString(lang) {
+				add("seo-hreflang-invalid", constant.SEVERITY_WARNING, "Invalid hreflang value: "+alternate.Lang)
+			}
+			if seen[lang] {
+				add("seo-hreflang-duplicate", constant.SEVERITY_WARNING, "Duplicate hreflang value: "+alternate.Lang)
+			}
+			seen[lang] = true
+			hasDefault = hasDefault || lang == "x-default"
+			hasSelf = hasSelf || samePageURL(alternate.Url, pageURL) ||
+				(seo.Canonical != constant.EMPTY && samePageURL(alternate.Url, seo.Canonical))
+		}
+		if !hasSelf {
+			add("seo-hreflang-no-self", constant.SEVERITY_WARNING, "hreflang alternates do not include the page itself")
+		}
+		if !hasDefault {
+			add("seo-hreflang-no-default", constant.SEVERITY_INFO, "hreflang alternates have no x-default entry")
+		}
+	}
+
+	viewport := parseViewport(seo.Viewport)
+	switch {
+	case seo.Viewport == constant.EMPTY:
+		add("seo-viewport-missing", constant.SEVERITY_WARNING, "Page has no viewport meta tag and is not mobile friendly")
+	case viewport["width"] != "device-width":
+		add("seo-viewport-width", constant.SEVERITY_WARNING, "Viewport does not set width=device-width")
+	}
+	maxScale, err := strconv.ParseFloat(viewport["maximum-scale"], 64)
+	if viewport["user-scalable"] == "no" || viewport["user-scalable"] == "0" || (err == nil && maxScale <= 1) {
+		add("seo-viewport-zoom", constant.SEVERITY_INFO, "Viewport prevents users from zooming")
+	}
+	return findings
+}
+
+// checkHreflangReciprocity fetches the hreflang alternates and records whether each one links back to the page.
+// Alternates that are not an HTML page answered with 200 are left undecided.
+func checkHreflangReciprocity(client *http.Client, alternates []response.HreflangLink, pageURL string) {
+	var wg sync.WaitGroup
+	probes := 0
+	for i := range alternates {
+		if samePageURL(alternates[i].Url, pageURL) || probes >= maxHreflangProbes {
+			continue
+		}
+		probes++
+
+		wg.Add(1)
+		go func(alternate *response.HreflangLink) {
+			defer wg.Done()
+			resp, err := probeGet(client, alternate.Url)
+			if err != nil {
+				log.Printf("Failed probing hreflang alternate: %s | Error: %v", alternate.Url, err)
+				return
+			}
+			defer resp.Body.Close()
+
+			mediaType, _, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+			if resp.StatusCode != http.StatusOK || !IsHTMLMediaType(mediaType) {
+				return
+			}
+			body, err := io.ReadAll(io.LimitReader(resp.Body, maxHreflangPageBytes))
+			if err != nil {
+				return
+			}
+			alternatePage := &response.WebContent{Content: string(body), Headers: resp.Header}
+			doc, err := alternatePage.Document()
+			if err != nil {
+				return
+			}
+			reciprocal := false
+			for _, link := range ExtractSeoMetadata(doc, resp.Header, alternate.Url).Hreflang {
+				if samePageURL(link.Url, pageURL) {
+					reciprocal = true
+				}
+			}
+			alternate.Reciprocal = &reciprocal
+		}(&alternates[i])
+	}
+	wg.Wait()
+}
+
+func auditHreflangReciprocity(alternates []response.HreflangLink) []response.Finding {
+	var findings []response.Finding
+	for _, alternate := range alternates {
+		if alternate.Reciprocal != nil && !*alternate.Reciprocal {
+			findings = append(findings, newFinding("seo-hreflang-not-reciprocal", constant.SEVERITY_WARNING,
+				"hreflang alternate "+alternate.Url+" does not link back to this page"))
+		}
+	}
+	return findings
+}
+
+// EstimatePixelWidth approximates the rendered width of text in Arial at the given font size.
+func EstimatePixelWidth(text string, fontSize float64) int {
+	var width float64
+	for _, r := range text {
+		switch {
+		case strings.ContainsRune("ijlI.,;:'!|ftr ", r):
+			width += 0.28
+		case strings.ContainsRune("mwMW@", r):
+			width += 0.83
+		case unicode.IsUpper(r):
+			width += 0.67
+		case unicode.IsDigit(r):
+			width += 0.56
+		default:
+			width += 0.5
+		}
+	}
+	return int(width*fontSize + 0.5)
+}
+
+// parseRobotsDirectives splits a robots meta or X-Robots-Tag value into lower case directives.
+// A user agent prefix such as "googlebot: noindex" is kept as part of the directive.
+func parseRobotsDirectives(value string) []string {
+	var directives []string
+	for _, part := range strings.Split(value, ",") {
+		if directive := strings.ToLower(strings.TrimSpace(part)); directive != constant.EMPTY {
+			directives = append(directives, directive)
+		}
+	}
+	return directives
+}
+
+// parseViewport splits the viewport content into lower case key/value pairs.
+func parseViewport(content string) map[string]string {
+	viewport := make(map[string]string)
+	for _, part := range strings.FieldsFunc(content, func(r rune) bool { return r == ',' || r == ';' }) {
+		key, value, _ := strings.Cut(part, "=")
+		viewport[strings.ToLower(strings.TrimSpace(key))] = strings.ToLower(strings.TrimSpace(value))
+	}
+	return viewport
+}
+
+func hasDirective(directives []string, name string) bool {
+	for _, directive := range directives {
+		if directive == name {
+			return true
+		}
+	}
+	return false
+}
+
+// parseLinkHeader returns the resolved targets of the Link header entries with the given rel.
+func parseLinkHeader(values []string, rel string, pageURL string) []string {
+	var targets []string
+	for _, value := range values {
+		for _, match := range linkHeaderRegex.FindAllStringSubmatch(value, -1) {
+			params := strings.ToLower(match[2])
+			if strings.Contains(params, `rel="`+rel+`"`) || strings.Contains(params, "rel="+rel) {
+				targets = append(targets, resolveURL(match[1], pageURL))
+			}
+		}
+	}
+	return targets
+}
@@ -0,0 +1,214 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"bytes"
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+)
+
+const (
+	// maxSitemapBytes bounds how much of a sitemap is read, after decompression.
+	maxSitemapBytes = 10 * 1024 * 1024
+	// maxSitemapUrls is the number of URLs the sitemap protocol allows in one file.
+	maxSitemapUrls = 50000
+	// maxIndexedSitemaps bounds how many sitemaps of a sitemap index are fetched.
+	maxIndexedSitemaps = 10
+)
+
+// sitemapDocument covers the urlset and sitemapindex documents of the sitemap protocol.
+type sitemapDocument struct {
+	XMLName  xml.Name
+	Urls     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+// sitemapLoc is the location of a page or of another sitemap.
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// fetchSitemaps fetches the sitemaps concurrently and checks whether they list the page.
+func fetchSitemaps(client *http.Client, links []string, pageURL string) []response.SitemapInfo {
+	sitemaps := make([]response.SitemapInfo, len(links))
+	var wg sync.WaitGroup
+	for i, link := range links {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sitemaps[i] = fetchSitemap(client, link, pageURL, true)
+		}()
+	}
+	wg.Wait()
+	return sitemaps
+}
+
+// fetchSitemap requests a sitemap and parses it. The sitemaps of an index are fetched in turn when followIndex is
+// set, the protocol does not allow an index to list other indexes.
+func fetchSitemap(client *http.Client, link, pageURL string, followIndex bool) response.SitemapInfo {
+	sitemap := response.SitemapInfo{Url: link}
+	status, body, err := fetchSitemapBody(client, link)
+	sitemap.Status = status
+	if err != nil {
+		sitemap.Error = err.Error()
+		return sitemap
+	}
+	if status != http.StatusOK {
+		return sitemap
+	}
+	children, err := ParseSitemap(body, pageURL, &sitemap)
+	if err != nil {
+		sitemap.Error = err.Error()
+		return sitemap
+	}
+	sitemap.Valid = true
+
+	if !followIndex {
+		return sitemap
+	}
+	if len(children) > maxIndexedSitemaps {
+		sitemap.Skipped = len(children) - maxIndexedSitemaps
+		children = children[:maxIndexedSitemaps]
+	}
+	for _, child := range children {
+		childSitemap := fetchSitemap(client, child, pageURL, false)
+		sitemap.Urls += childSitemap.Urls
+		sitemap.ListsPage = sitemap.ListsPage || childSitemap.ListsPage
+		sitemap.Sitemaps = append(sitemap.Sitemaps, childSitemap)
+	}
+	return sitemap
+}
+
+// fetchSitemapBody requests a sitemap and returns its status and the first maxSitemapBytes of its body.
+func fetchSitemapBody(client *http.Client, link string) (int, []byte, error) {
+	resp, err := client.Get(link)
+	if err != nil {
+		log.Printf("Failed fetching sitemap: %s | Error: %v", link, err)
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return resp.StatusCode, nil, nil
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxSitemapBytes))
+	return resp.StatusCode, body, err
+}
+
+// decodeSitemap decompresses a gzipped sitemap and decodes its XML.
+func decodeSitemap(body []byte) (*sitemapDocument, error) {
+	if bytes.HasPrefix(body, []byte{0x1f, 0x8b}) {
+		reader, err := gzip.NewReader(bytes.NewReader(body))
+		if err != nil {
+			return nil, err
+		}
+		if body, err = io.ReadAll(io.LimitReader(reader, maxSitemapBytes)); err != nil {
+			return nil, err
+		}
+	}
+	var document sitemapDocument
+	if err := xml.Unmarshal(body, &document); err != nil {
+		return nil, err
+	}
+	return &document, nil
+}
+
+// ParseSitemap parses a urlset or sitemapindex document, gzip compressed or not. It counts the URLs of a urlset,
+// checks whether pageURL is one of them, and returns the locations of the sitemaps of an index.
+func ParseSitemap(body []byte, pageURL string, sitemap *response.SitemapInfo) ([]string, error) {
+	document, err := decodeSitemap(body)
+	if err != nil {
+		return nil, err
+	}
+	switch document.XMLName.Local {
+	case "urlset":
+		sitemap.Type = constant.SITEMAP_URLSET
+		page := NormalizePageURL(pageURL)
+		for _, entry := range document.Urls {
+			if strings.TrimSpace(entry.Loc) == constant.EMPTY {
+				continue
+			}
+			sitemap.Urls++
+			if page != constant.EMPTY && NormalizePageURL(entry.Loc) == page {
+				sitemap.ListsPage = true
+			}
+		}
+		return nil, nil
+	case "sitemapindex":
+		sitemap.Type = constant.SITEMAP_INDEX
+		var children []string
+		for _, entry := range document.Sitemaps {
+			if loc := strings.TrimSpace(entry.Loc); loc != constant.EMPTY {
+				children = append(children, loc)
+			}
+		}
+		return children, nil
+	}
+	return nil, fmt.Errorf("unknown root element <%s>", document.XMLName.Local)
+}
+
+// ListSitemapUrls returns up to limit page URLs listed by the sitemaps, following sitemap indexes one level deep.
+// Sitemaps that cannot be fetched or parsed are skipped.
+func ListSitemapUrls(client *http.Client, links []string, limit int) []string {
+	var urls []string
+	var visit func(link string, followIndex bool)
+	visit = func(link string, followIndex bool) {
+		status, body, err := fetchSitemapBody(client, link)
+		if err != nil || status != http.StatusOK {
+			return
+		}
+		document, err := decodeSitemap(body)
+		if err != nil {
+			return
+		}
+		for _, entry := range document.Urls {
+			if loc := strings.TrimSpace(entry.Loc); loc != constant.EMPTY && len(urls) < limit {
+				urls = append(urls, loc)
+			}
+		}
+		if followIndex && document.XMLName.Local == "sitemapindex" {
+			for i, entry := range document.Sitemaps {
+				if i == maxIndexedSitemaps || len(urls) >= limit {
+					break
+				}
+				visit(strings.TrimSpace(entry.Loc), false)
+			}
+		}
+	}
+	for _, link := range links {
+		if len(urls) < limit {
+			visit(link, true)
+		}
+	}
+	return urls
+}
+
+// NormalizePageURL lowercases the scheme and host of a URL and drops its fragment, so sitemap entries compare
+// equal to the page they list.
+func NormalizePageURL(link string) string {
+	parsedURL, err := url.Parse(strings.TrimSpace(link))
+	if err != nil || parsedURL.Host == constant.EMPTY {
+		return constant.EMPTY
+	}
+	parsedURL.Scheme = strings.ToLower(parsedURL.Scheme)
+	parsedURL.Host = strings.ToLower(parsedURL.Host)
+	parsedURL.Fragment, parsedURL.RawFragment = constant.EMPTY, constant.EMPTY
+	if parsedURL.Path == constant.EMPTY {
+		parsedURL.Path = "/"
+	}
+	return parsedURL.String()
+}
+
+// samePageURL reports whether two URLs normalize to the same page.
+func samePageURL(a, b string) bool {
+	page := NormalizePageURL(a)
+	return page != constant.EMPTY && page == NormalizePageURL(b)
+}
@@ -0,0 +1,254 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const (
+	minShareImageSize       = 200
+	minLargeCardImageWidth  = 300
+	minLargeCardImageHeight = 157
+)
+
+// openGraphRequired lists the properties every Open Graph object must declare.
+var openGraphRequired = []string{"og:title", "og:type", "og:image", "og:url"}
+
+// twitterCardRequired lists the properties each Twitter card type needs. title, description and image
+// fall back to their Open Graph equivalents.
+var twitterCardRequired = map[string][]string{
+	"summary":             {"title"},
+	"summary_large_image": {"title", "image"},
+	"app":                 {"site"},
+	"player":              {"title", "site", "player", "player:width", "player:height", "image"},
+}
+
+// SocialMetadataAnalyzer implements the Analyzer interface for Open Graph, Twitter Card and oEmbed metadata.
+type SocialMetadataAnalyzer struct{}
+
+// NewSocialMetadataAnalyzer creates a new SocialMetadataAnalyzer.
+func NewSocialMetadataAnalyzer() *SocialMetadataAnalyzer {
+	return &SocialMetadataAnalyzer{}
+}
+
+// Analyze extracts the social sharing metadata, validates it per card type and builds the share preview.
+func (a *SocialMetadataAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing social metadata function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("SocialMetadataAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing social metadata",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	pageURL := wc.FinalUrl
+	if pageURL == constant.EMPTY {
+		pageURL = res.ExecutedUrl
+	}
+	social := ExtractSocialMetadata(doc, pageURL)
+	if social.Preview.Image != constant.EMPTY && configs.GetConfig().LinkProbe {
+		social.Image, err = probeImage(probeClient(wc), social.Preview.Image)
+		if err != nil {
+			log.Printf("Failed probing share image: %s | Error: %v", social.Preview.Image, err)
+		}
+		if social.Image.Width > 0 {
+			social.Preview.ImageWidth, social.Preview.ImageHeight = social.Image.Width, social.Image.Height
+		}
+		// an image robots.txt does not allow was not requested, so nothing is known about it
+		if !errors.Is(err, errProbeDisallowed) {
+			social.Findings = append(social.Findings, auditShareImage(social.Image, social.CardType, err)...)
+		}
+	}
+	res.Social = social
+	return nil
+}
+
+// ExtractSocialMetadata reads the og:* and twitter:* properties and the oEmbed discovery links of the document.
+func ExtractSocialMetadata(doc *html.Node, pageURL string) *response.SocialMetadata {
+	social := &response.SocialMetadata{
+		OpenGraph: make(map[string]string),
+		Twitter:   make(map[string]string),
+	}
+	var title, description, canonical string
+
+	for _, node := range findElements(doc, "title", "meta", "link") {
+		switch node.Data {
+		case "title":
+			if title == constant.EMPTY {
+				title = normalizeSpace(nodeText(node))
+			}
+		case "meta":
+			key := strings.ToLower(getAttr(node, "property"))
+			if key == constant.EMPTY {
+				key = strings.ToLower(getAttr(node, "name"))
+			}
+			content := strings.TrimSpace(getAttr(node, "content"))
+			switch {
+			case strings.HasPrefix(key, "og:"):
+				if _, exists := social.OpenGraph[key]; !exists {
+					social.OpenGraph[key] = content
+				}
+			case strings.HasPrefix(key, "twitter:"):
+				if _, exists := social.Twitter[key]; !exists {
+					social.Twitter[key] = content
+				}
+			case key == "description":
+				description = normalizeSpace(content)
+			}
+		case "link":
+			rel := strings.ToLower(getAttr(node, "rel"))
+			linkType := strings.ToLower(getAttr(node, "type"))
+			href := resolveURL(strings.TrimSpace(getAttr(node, constant.H_REF)), pageURL)
+			switch {
+			case rel == "alternate" && strings.HasSuffix(linkType, "+oembed"):
+				social.OEmbed = append(social.OEmbed, response.OEmbedLink{Type: linkType, Url: href, Title: getAttr(node, "title")})
+			case rel == "canonical" && canonical == constant.EMPTY:
+				canonical = href
+			}
+		}
+	}
+
+	social.CardType = social.Twitter["twitter:card"]
+	if social.CardType == constant.EMPTY && len(social.OpenGraph) > 0 {
+		social.CardType = "summary"
+	}
+	social.Preview = buildSharePreview(social, title, description, canonical, pageURL)
+	social.Findings = auditSocialMetadata(social, pageURL)
+	return social
+}
+
+// buildSharePreview resolves what a link unfurler would display, using the same fallbacks as the major platforms.
+func buildSharePreview(social *response.SocialMetadata, title, description, canonical, pageURL string) response.SharePreview {
+	og, twitter := social.OpenGraph, social.Twitter
+	preview := response.SharePreview{
+		Title:       firstNonEmpty(og["og:title"], twitter["twitter:title"], title),
+		Description: firstNonEmpty(og["og:description"], twitter["twitter:description"], description),
+		ImageAlt:    firstNonEmpty(og["og:image:alt"], twitter["twitter:image:alt"]),
+		SiteName:    og["og:site_name"],
+		Url:         firstNonEmpty(og["og:url"], canonical, pageURL),
+		CardType:    social.CardType,
+	}
+	if image := firstNonEmpty(og["og:image"], og["og:image:url"], og["og:image:secure_url"], twitter["twitter:image"]); image != constant.EMPTY {
+		preview.Image = resolveURL(image, pageURL)
+	}
+	preview.ImageWidth, _ = strconv.Atoi(og["og:image:width"])
+	preview.ImageHeight, _ = strconv.Atoi(og["og:image:height"])
+	if parsedURL, err := url.Parse(preview.Url); err == nil {
+		preview.Domain = parsedURL.Hostname()
+	}
+	if preview.SiteName == constant.EMPTY {
+		preview.SiteName = preview.Domain
+	}
+	return preview
+}
+
+// auditSocialMetadata validates the Open Graph object and the required properties of the Twitter card type.
+func auditSocialMetadata(social *response.SocialMetadata, pageURL string) []response.Finding {
+	var findings []response.Finding
+	og, twitter := social.OpenGraph, social.Twitter
+
+	if len(og) == 0 {
+		findings = append(findings, newFinding("og-missing", constant.SEVERITY_ERROR,
+			"Page has no Open Graph metadata, link previews fall back to guesses"))
+	} else {
+		for _, property := range openGraphRequired {
+			if og[property] == constant.EMPTY {
+				findings = append(findings, newFinding("og-missing-property", constant.SEVERITY_WARNING,
+					"Required Open Graph property "+property+" is missing"))
+			}
+		}
+		if og["og:description"] == constant.EMPTY {
+			findings = append(findings, newFinding("og-missing-description", constant.SEVERITY_INFO,
+				"Open Graph property og:description is recommended"))
+		}
+		if image := og["og:image"]; image != constant.EMPTY {
+			if parsedURL, err := url.Parse(image); err == nil && !parsedURL.IsAbs() {
+				findings = append(findings, newFinding("og-image-relative", constant.SEVERITY_WARNING,
+					"og:image must be an absolute URL: "+image))
+			}
+		}
+	}
+
+	cardType := twitter["twitter:card"]
+	required, known := twitterCardRequired[cardType]
+	switch {
+	case cardType == constant.EMPTY:
+		findings = append(findings, newFinding("twitter-card-missing", constant.SEVERITY_INFO,
+			"Page has no twitter:card, X/Twitter falls back to a summary card"))
+	case !known:
+		findings = append(findings, newFinding("twitter-card-invalid", constant.SEVERITY_WARNING,
+			"Unknown twitter:card type: "+cardType))
+	default:
+		for _, property := range required {
+			if twitter["twitter:"+property] == constant.EMPTY && og["og:"+property] == constant.EMPTY {
+				findings = append(findings, newFinding("twitter-missing-property", constant.SEVERITY_WARNING,
+					fmt.Sprintf("twitter:%s is required for the %s card", property, cardType)))
+			}
+		}
+		if cardType == "app" && twitter["twitter:app:id:iphone"] == constant.EMPTY &&
+			twitter["twitter:app:id:ipad"] == constant.EMPTY && twitter["twitter:app:id:googleplay"] == constant.EMPTY {
+			findings = append(findings, newFinding("twitter-missing-property", constant.SEVERITY_WARNING,
+				"The app card needs at least one twitter:app:id:* property"))
+		}
+	}
+
+	if strings.HasPrefix(pageURL, constant.HTTPS_SCHEME+"://") && strings.HasPrefix(social.Preview.Image, constant.HTTP_SCHEME+"://") {
+		findings = append(findings, newFinding("share-image-insecure", constant.SEVERITY_INFO,
+			"Share image is served over plain HTTP and may be blocked by some platforms"))
+	}
+	return findings
+}
+
+// auditShareImage checks that the probed share image is reachable, is an image and is large enough.
+func auditShareImage(image *response.ImageProbe, cardType string, probeErr error) []response.Finding {
+	var findings []response.Finding
+	switch {
+	case probeErr != nil || image.Status != http.StatusOK:
+		return append(findings, newFinding("share-image-unreachable", constant.SEVERITY_ERROR,
+			fmt.Sprintf("Share image %s is not reachable (status %d)", image.Url, image.Status)))
+	case !strings.HasPrefix(image.ContentType, "image/"):
+		return append(findings, newFinding("share-image-content-type", constant.SEVERITY_ERROR,
+			"Share image is served as "+image.ContentType+" instead of an image"))
+	case image.Width == 0:
+		return findings
+	}
+
+	if image.Width < minShareImageSize || image.Height < minShareImageSize {
+		findings = append(findings, newFinding("share-image-small", constant.SEVERITY_WARNING,
+			fmt.Sprintf("Share image is %dx%d, platforms require at least %dx%d", image.Width, image.Height, minShareImageSize, minShareImageSize)))
+	}
+	if cardType == "summary_large_image" && (image.Width < minLargeCardImageWidth || image.Height < minLargeCardImageHeight) {
+		findings = append(findings, newFinding("share-image-large-card", constant.SEVERITY_WARNING,
+			fmt.Sprintf("summary_large_image cards need an image of at least %dx%d", minLargeCardImageWidth, minLargeCardImageHeight)))
+	}
+	return findings
+}
+
+// firstNonEmpty returns the first value that is not empty.
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != constant.EMPTY {
+			return value
+		}
+	}
+	return constant.EMPTY
+}
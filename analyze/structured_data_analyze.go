@@ -0,0 +1,463 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+const jsonLdScriptType = "application/ld+json"
+
+// requiredSchemaProperties lists the properties a rich result needs per schema.org type. Entries joined with
+// "|" are alternatives of which at least one must be present.
+var requiredSchemaProperties = map[string][]string{
+	"Product":        {"name", "offers|review|aggregateRating"},
+	"Article":        {"headline", "author", "datePublished", "image"},
+	"NewsArticle":    {"headline", "author", "datePublished", "image"},
+	"BlogPosting":    {"headline", "author", "datePublished", "image"},
+	"BreadcrumbList": {"itemListElement"},
+	"Organization":   {"name", "url"},
+	"FAQPage":        {"mainEntity"},
+}
+
+// StructuredDataAnalyzer implements the Analyzer interface for JSON-LD, Microdata and RDFa markup.
+type StructuredDataAnalyzer struct{}
+
+// NewStructuredDataAnalyzer creates a new StructuredDataAnalyzer.
+func NewStructuredDataAnalyzer() *StructuredDataAnalyzer {
+	return &StructuredDataAnalyzer{}
+}
+
+// Analyze extracts the structured data entities of the page grouped by type and checks their required properties.
+func (a *StructuredDataAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing structured data function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("StructuredDataAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing structured data",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	data := &response.StructuredData{Entities: make(map[string][]response.StructuredEntity)}
+	entities, jsonLdErrors := ExtractJsonLd(html.NewTokenizer(strings.NewReader(wc.Text())))
+	entities = append(entities, ExtractMicrodata(doc)...)
+	entities = append(entities, ExtractRdfa(doc)...)
+
+	data.JsonLdErrors = jsonLdErrors
+	for _, entity := range entities {
+		data.Entities[entity.Type] = append(data.Entities[entity.Type], entity)
+		data.Findings = append(data.Findings, ValidateSchemaEntity(entity)...)
+	}
+	data.EntityCount = len(entities)
+	for _, jsonLdError := range jsonLdErrors {
+		data.Findings = append(data.Findings, newFinding("json-ld-syntax", constant.SEVERITY_ERROR,
+			fmt.Sprintf("JSON-LD script %d has a syntax error at line %d column %d: %s",
+				jsonLdError.Script, jsonLdError.Line, jsonLdError.Column, jsonLdError.Message)))
+	}
+	res.StructuredData = data
+	return nil
+}
+
+// ExtractJsonLd parses every <script type="application/ld+json"> and reports syntax errors with their page position.
+func ExtractJsonLd(tokenizer *html.Tokenizer) ([]response.StructuredEntity, []response.JsonLdError) {
+	var entities []response.StructuredEntity
+	var jsonLdErrors []response.JsonLdError
+	position := newPositionTracker()
+	inJsonLd := false
+	script := 0
+
+	for {
+		tokenType := tokenizer.Next()
+		start := *position
+		position.advance(tokenizer.Raw())
+
+		switch tokenType {
+		case html.ErrorToken:
+			if tokenizer.Err() != io.EOF {
+				log.Printf("HTML tokenizer error: %v", tokenizer.Err())
+			}
+			return entities, jsonLdErrors
+		case html.StartTagToken:
+			token := tokenizer.Token()
+			inJsonLd = false
+			if token.Data == "script" {
+				for _, attr := range token.Attr {
+					if attr.Key == "type" && strings.EqualFold(strings.TrimSpace(attr.Val), jsonLdScriptType) {
+						inJsonLd = true
+						script++
+					}
+				}
+			}
+		case html.TextToken:
+			if !inJsonLd {
+				continue
+			}
+			inJsonLd = false
+			text := tokenizer.Text()
+
+			var value any
+			if err := json.Unmarshal(text, &value); err != nil {
+				jsonLdErrors = append(jsonLdErrors, jsonLdSyntaxError(script, text, start, err))
+				continue
+			}
+			entities = append(entities, jsonLdEntities(value)...)
+		default:
+			inJsonLd = false
+		}
+	}
+}
+
+// ExtractJsonLdEntities returns the entities of the <script type="application/ld+json"> elements of the document.
+// Scripts with a syntax error are skipped.
+func ExtractJsonLdEntities(doc *html.Node) []response.StructuredEntity {
+	var entities []response.StructuredEntity
+	for _, script := range findElements(doc, "script") {
+		if !strings.EqualFold(strings.TrimSpace(getAttr(script, "type")), jsonLdScriptType) || script.FirstChild == nil {
+			continue
+		}
+		var value any
+		if err := json.Unmarshal([]byte(script.FirstChild.Data), &value); err != nil {
+			continue
+		}
+		entities = append(entities, jsonLdEntities(value)...)
+	}
+	return entities
+}
+
+// jsonLdEntities converts the nodes of a decoded JSON-LD script into entities.
+func jsonLdEntities(value any) []response.StructuredEntity {
+	var entities []response.StructuredEntity
+	for _, node := range flattenJsonLd(value) {
+		entities = append(entities, response.StructuredEntity{
+			Type:       schemaTypeName(node["@type"]),
+			Source:     constant.SOURCE_JSON_LD,
+			Properties: node,
+		})
+	}
+	return entities
+}
+
+// jsonLdSyntaxError converts a JSON decoding error into a page line and column.
+func jsonLdSyntaxError(script int, text []byte, start positionTracker, err error) response.JsonLdError {
+	jsonLdError := response.JsonLdError{Script: script, Message: err.Error()}
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		jsonLdError.Offset = syntaxErr.Offset
+	case errors.As(err, &typeErr):
+		jsonLdError.Offset = typeErr.Offset
+	default:
+		jsonLdError.Offset = int64(len(text))
+	}
+	if jsonLdError.Offset > int64(len(text)) {
+		jsonLdError.Offset = int64(len(text))
+	}
+
+	position := start
+	if jsonLdError.Offset > 0 {
+		position.advance(text[:jsonLdError.Offset-1])
+	}
+	jsonLdError.Line, jsonLdError.Column = position.line, position.column
+	return jsonLdError
+}
+
+// flattenJsonLd returns the top level JSON-LD nodes of a document, expanding arrays and @graph containers.
+func flattenJsonLd(value any) []map[string]any {
+	var nodes []map[string]any
+	switch typed := value.(type) {
+	case []any:
+		for _, item := range typed {
+			nodes = append(nodes, flattenJsonLd(item)...)
+		}
+	case map[string]any:
+		if graph, ok := typed["@graph"]; ok {
+			nodes = append(nodes, flattenJsonLd(graph)...)
+		}
+		if _, ok := typed["@type"]; ok {
+			nodes = append(nodes, typed)
+		}
+	}
+	return nodes
+}
+
+// ExtractMicrodata returns the top level itemscope trees of the document.
+func ExtractMicrodata(doc *html.Node) []response.StructuredEntity {
+	var entities []response.StructuredEntity
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hasAttr(n, "itemscope") && !hasAttr(n, "itemprop") {
+			item := readMicrodataItem(n)
+			entities = append(entities, response.StructuredEntity{
+				Type:       schemaTypeName(item["@type"]),
+				Source:     constant.SOURCE_MICRODATA,
+				Properties: item,
+			})
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return entities
+}
+
+// readMicrodataItem collects the itemprop values below an itemscope element, nesting inner itemscopes.
+func readMicrodataItem(scope *html.Node) map[string]any {
+	item := make(map[string]any)
+	if itemType := strings.Fields(getAttr(scope, "itemtype")); len(itemType) > 0 {
+		item["@type"] = itemType[0]
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			props := strings.Fields(getAttr(child, "itemprop"))
+			if hasAttr(child, "itemscope") {
+				if len(props) > 0 {
+					nested := readMicrodataItem(child)
+					for _, prop := range props {
+						addProperty(item, prop, nested)
+					}
+				}
+				// properties of a nested item belong to that item
+				continue
+			}
+			for _, prop := range props {
+				addProperty(item, prop, elementValue(child, "content"))
+			}
+			walk(child)
+		}
+	}
+	walk(scope)
+	return item
+}
+
+// ExtractRdfa returns the top level typeof trees of the document using the RDFa Lite attributes.
+func ExtractRdfa(doc *html.Node) []response.StructuredEntity {
+	var entities []response.StructuredEntity
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && hasAttr(n, "typeof") && !hasAttr(n, "property") {
+			item := readRdfaItem(n)
+			entities = append(entities, response.StructuredEntity{
+				Type:       schemaTypeName(item["@type"]),
+				Source:     constant.SOURCE_RDFA,
+				Properties: item,
+			})
+			return
+		}
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			walk(child)
+		}
+	}
+	walk(doc)
+	return entities
+}
+
+// readRdfaItem collects the property values below a typeof element, nesting inner typeof elements.
+func readRdfaItem(scope *html.Node) map[string]any {
+	item := make(map[string]any)
+	if itemType := strings.Fields(getAttr(scope, "typeof")); len(itemType) > 0 {
+		item["@type"] = itemType[0]
+	}
+
+	var walk func(*html.Node)
+	walk = func(n *html.Node) {
+		for child := n.FirstChild; child != nil; child = child.NextSibling {
+			if child.Type != html.ElementNode {
+				continue
+			}
+			props := strings.Fields(getAttr(child, "property"))
+			if hasAttr(child, "typeof") {
+				if len(props) > 0 {
+					nested := readRdfaItem(child)
+					for _, prop := range props {
+						addProperty(item, schemaTypeName(prop), nested)
+					}
+				}
+				continue
+			}
+			for _, prop := range props {
+				addProperty(item, schemaTypeName(prop), elementValue(child, "content"))
+			}
+			walk(child)
+		}
+	}
+	walk(scope)
+	return item
+}
+
+// elementValue returns the property value of an element following the Microdata and RDFa value rules.
+func elementValue(n *html.Node, contentAttr string) string {
+	if hasAttr(n, contentAttr) {
+		return getAttr(n, contentAttr)
+	}
+	switch n.Data {
+	case "a", "area", "link":
+		return getAttr(n, constant.H_REF)
+	case "img", "audio", "video", "source", "iframe", "embed", "track":
+		return getAttr(n, constant.SRC)
+	case "object":
+		return getAttr(n, "data")
+	case "time":
+		if hasAttr(n, "datetime") {
+			return getAttr(n, "datetime")
+		}
+	case "data", "meter":
+		return getAttr(n, "value")
+	}
+	return normalizeSpace(nodeText(n))
+}
+
+// addProperty sets a property value, turning repeated properties into a list.
+func addProperty(item map[string]any, name string, value any) {
+	existing, ok := item[name]
+	if !ok {
+		item[name] = value
+		return
+	}
+	if list, isList := existing.([]any); isList {
+		item[name] = append(list, value)
+		return
+	}
+	item[name] = []any{existing, value}
+}
+
+// ValidateSchemaEntity reports the required properties missing from an entity, including nested
+// breadcrumb items and FAQ questions.
+func ValidateSchemaEntity(entity response.StructuredEntity) []response.Finding {
+	var findings []response.Finding
+	missing := func(path string) {
+		findings = append(findings, newFinding("structured-data-missing-property", constant.SEVERITY_WARNING,
+			fmt.Sprintf("%s (%s) is missing required property %s", entity.Type, entity.Source, path)))
+	}
+
+	for _, required := range requiredSchemaProperties[entity.Type] {
+		if !hasAnyProperty(entity.Properties, strings.Split(required, "|")...) {
+			missing(strings.ReplaceAll(required, "|", " or "))
+		}
+	}
+
+	switch entity.Type {
+	case "BreadcrumbList":
+		items := asList(entity.Properties["itemListElement"])
+		for i, element := range items {
+			listItem, _ := element.(map[string]any)
+			for _, prop := range []string{"position", "name"} {
+				if !hasAnyProperty(listItem, prop) && !(prop == "name" && hasNestedName(listItem)) {
+					missing(fmt.Sprintf("itemListElement[%d].%s", i, prop))
+				}
+			}
+			if i < len(items)-1 && !hasAnyProperty(listItem, "item") {
+				missing(fmt.Sprintf("itemListElement[%d].item", i))
+			}
+		}
+	case "FAQPage":
+		for i, element := range asList(entity.Properties["mainEntity"]) {
+			question, _ := element.(map[string]any)
+			if !hasAnyProperty(question, "name") {
+				missing(fmt.Sprintf("mainEntity[%d].name", i))
+			}
+			answer, _ := question["acceptedAnswer"].(map[string]any)
+			if !hasAnyProperty(answer, "text") {
+				missing(fmt.Sprintf("mainEntity[%d].acceptedAnswer.text", i))
+			}
+		}
+	}
+	return findings
+}
+
+// hasNestedName reports whether a breadcrumb item carries its name on the nested item object.
+func hasNestedName(listItem map[string]any) bool {
+	item, ok := listItem["item"].(map[string]any)
+	return ok && hasAnyProperty(item, "name")
+}
+
+// hasAnyProperty reports whether one of the properties is set to a non empty value.
+func hasAnyProperty(properties map[string]any, names ...string) bool {
+	for _, name := range names {
+		switch value := properties[name].(type) {
+		case nil:
+			continue
+		case string:
+			if strings.TrimSpace(value) != constant.EMPTY {
+				return true
+			}
+		case []any:
+			if len(value) > 0 {
+				return true
+			}
+		default:
+			return true
+		}
+	}
+	return false
+}
+
+func asList(value any) []any {
+	switch typed := value.(type) {
+	case nil:
+		return nil
+	case []any:
+		return typed
+	default:
+		return []any{typed}
+	}
+}
+
+// schemaTypeName normalizes a type or property IRI such as https://schema.org/Product or schema:name to its
+// local name. For a list of types the first one is used.
+func schemaTypeName(value any) string {
+	switch typed := value.(type) {
+	case string:
+		name := typed
+		if index := strings.LastIndexAny(name, "/#:"); index >= 0 {
+			name = name[index+1:]
+		}
+		return name
+	case []any:
+		names := make([]string, 0, len(typed))
+		for _, item := range typed {
+			if name := schemaTypeName(item); name != constant.EMPTY {
+				names = append(names, name)
+			}
+		}
+		sort.Strings(names)
+		if len(names) > 0 {
+			return names[0]
+		}
+	}
+	return constant.EMPTY
+}
+
+func hasAttr(n *html.Node, key string) bool {
+	for _, attr := range n.Attr {
+		if attr.Key == key {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,127 @@
+package analyze
+
+import (
+	"api/constant"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/publicsuffix"
+)
+
+// Subresource is a URL the browser fetches automatically while loading the page.
+type Subresource struct {
+	Url string
+	Tag string
+	Rel string
+}
+
+// subresourceAttrs maps the elements that load subresources to the attribute holding the URL.
+var subresourceAttrs = map[string]string{
+	"script": constant.SRC,
+	"img":    constant.SRC,
+	"iframe": constant.SRC,
+	"frame":  constant.SRC,
+	"embed":  constant.SRC,
+	"audio":  constant.SRC,
+	"video":  constant.SRC,
+	"source": constant.SRC,
+	"track":  constant.SRC,
+	"input":  constant.SRC,
+	"object": "data",
+	"link":   constant.H_REF,
+}
+
+// subresourceLinkRels lists the <link> relations that make the browser fetch the target.
+var subresourceLinkRels = map[string]bool{
+	"stylesheet":    true,
+	"icon":          true,
+	"preload":       true,
+	"modulepreload": true,
+	"manifest":      true,
+}
+
+// subresourceURL returns the raw URL an element loads as a subresource and its rel value for <link>.
+func subresourceURL(tag string, attrs []html.Attribute) (string, string, bool) {
+	key, ok := subresourceAttrs[tag]
+	if !ok {
+		return constant.EMPTY, constant.EMPTY, false
+	}
+
+	var rawURL, rel string
+	var found bool
+	for _, attr := range attrs {
+		switch attr.Key {
+		case key:
+			rawURL, found = strings.TrimSpace(attr.Val), true
+		case "rel":
+			rel = strings.ToLower(attr.Val)
+		case "type":
+			// only image inputs load a subresource
+			if tag == "input" && !strings.EqualFold(attr.Val, "image") {
+				return constant.EMPTY, constant.EMPTY, false
+			}
+		}
+	}
+	if !found || rawURL == constant.EMPTY {
+		return constant.EMPTY, constant.EMPTY, false
+	}
+	if tag == "link" {
+		for _, value := range strings.Fields(rel) {
+			if subresourceLinkRels[value] {
+				return rawURL, rel, true
+			}
+		}
+		return constant.EMPTY, constant.EMPTY, false
+	}
+	return rawURL, rel, true
+}
+
+// extractSubresources recursively traverses the DOM tree and collects the resolved subresource URLs.
+func extractSubresources(n *html.Node, base string, resources *[]Subresource) {
+	if n.Type == html.ElementNode {
+		if rawURL, rel, ok := subresourceURL(n.Data, n.Attr); ok {
+			if absURL := resolveURL(rawURL, base); absURL != constant.EMPTY {
+				*resources = append(*resources, Subresource{Url: absURL, Tag: n.Data, Rel: rel})
+			}
+		}
+	}
+	for child := n.FirstChild; child != nil; child = child.NextSibling {
+		extractSubresources(child, base, resources)
+	}
+}
+
+// registrableDomain returns the eTLD+1 of host, falling back to the host itself.
+func registrableDomain(host string) string {
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+	domain, err := publicsuffix.EffectiveTLDPlusOne(host)
+	if err != nil {
+		return host
+	}
+	return domain
+}
+
+// isThirdParty reports whether link belongs to a different site than pageURL.
+func isThirdParty(link, pageURL string) bool {
+	linkURL, err := url.Parse(link)
+	if err != nil || linkURL.Hostname() == constant.EMPTY {
+		return false
+	}
+	page, err := url.Parse(pageURL)
+	if err != nil {
+		return false
+	}
+	return registrableDomain(linkURL.Hostname()) != registrableDomain(page.Hostname())
+}
+
+// parseSrcset returns the candidate URLs of a srcset attribute value.
+func parseSrcset(value string) []string {
+	var urls []string
+	for _, candidate := range strings.Split(value, ",") {
+		fields := strings.Fields(candidate)
+		if len(fields) > 0 {
+			urls = append(urls, fields[0])
+		}
+	}
+	return urls
+}
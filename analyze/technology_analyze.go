@@ -0,0 +1,227 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/html"
+)
+
+// TechnologyAnalyzer implements the Analyzer interface for technology fingerprinting.
+type TechnologyAnalyzer struct{}
+
+// NewTechnologyAnalyzer creates a new TechnologyAnalyzer.
+func NewTechnologyAnalyzer() *TechnologyAnalyzer {
+	return &TechnologyAnalyzer{}
+}
+
+// Analyze detects the CMS, frameworks, analytics, CDNs and servers of the page from the signature file.
+func (a *TechnologyAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing technologies function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("TechnologyAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	doc, err := wc.Document()
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse HTML while analyzing technologies",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	res.Technologies = DetectTechnologies(wc, doc, loadTechnologySignatures())
+	return nil
+}
+
+// pageEvidence is what the signatures are matched against.
+type pageEvidence struct {
+	headers    http.Header
+	cookies    map[string]string
+	meta       map[string][]string
+	scriptSrc  []string
+	scripts    []string
+	rawContent string
+}
+
+// DetectTechnologies matches every signature against the response headers, cookies, meta tags, script URLs,
+// HTML and the global variables of inline scripts, then applies the implies and excludes relations.
+func DetectTechnologies(wc *response.WebContent, doc *html.Node, signatures *TechnologySignatures) *response.TechnologyReport {
+	evidence := collectPageEvidence(wc, doc)
+	detected := make(map[string]*response.Technology)
+
+	for name, signature := range signatures.Technologies {
+		technology := &response.Technology{Name: name, Website: signature.website}
+		hit := func(source string, pattern *techPattern, value string) {
+			if ok, version := pattern.match(value); ok {
+				technology.Confidence += pattern.confidence
+				technology.Evidence = appendUnique(technology.Evidence, source)
+				if len(version) > len(technology.Version) {
+					technology.Version = version
+				}
+			}
+		}
+
+		for header, patterns := range signature.headers {
+			for _, value := range evidence.headers.Values(header) {
+				for _, pattern := range patterns {
+					hit("header "+http.CanonicalHeaderKey(header), pattern, value)
+				}
+			}
+		}
+		for cookie, patterns := range signature.cookies {
+			if value, ok := evidence.cookies[cookie]; ok {
+				for _, pattern := range patterns {
+					hit("cookie "+cookie, pattern, value)
+				}
+			}
+		}
+		for meta, patterns := range signature.meta {
+			for _, value := range evidence.meta[meta] {
+				for _, pattern := range patterns {
+					hit("meta "+meta, pattern, value)
+				}
+			}
+		}
+		for _, pattern := range signature.scriptSrc {
+			for _, src := range evidence.scriptSrc {
+				hit("script "+src, pattern, src)
+			}
+		}
+		for _, pattern := range signature.html {
+			hit("html", pattern, evidence.rawContent)
+		}
+		for variable, regex := range signature.js {
+			for _, script := range evidence.scripts {
+				if regex.MatchString(script) {
+					technology.Confidence += 100
+					technology.Evidence = appendUnique(technology.Evidence, "js "+variable)
+					break
+				}
+			}
+		}
+
+		if technology.Confidence > 0 {
+			detected[name] = technology
+		}
+	}
+
+	applyImplies(detected, signatures)
+	for _, technology := range detected {
+		for _, excluded := range signatures.Technologies[technology.Name].excludes {
+			delete(detected, excluded)
+		}
+	}
+	return buildTechnologyReport(detected, signatures)
+}
+
+// applyImplies adds the technologies implied by the detected ones, following chains of implications.
+func applyImplies(detected map[string]*response.Technology, signatures *TechnologySignatures) {
+	queue := make([]string, 0, len(detected))
+	for name := range detected {
+		queue = append(queue, name)
+	}
+	sort.Strings(queue)
+
+	for len(queue) > 0 {
+		name := queue[0]
+		queue = queue[1:]
+		signature, ok := signatures.Technologies[name]
+		if !ok {
+			continue
+		}
+		for _, implied := range signature.implies {
+			impliedName := implied.value
+			if _, known := signatures.Technologies[impliedName]; !known {
+				continue
+			}
+			confidence := detected[name].Confidence * implied.confidence / 100
+			if technology, exists := detected[impliedName]; exists {
+				technology.Evidence = appendUnique(technology.Evidence, "implied by "+name)
+				continue
+			}
+			detected[impliedName] = &response.Technology{
+				Name:       impliedName,
+				Website:    signatures.Technologies[impliedName].website,
+				Confidence: confidence,
+				Evidence:   []string{"implied by " + name},
+			}
+			queue = append(queue, impliedName)
+		}
+	}
+}
+
+func buildTechnologyReport(detected map[string]*response.Technology, signatures *TechnologySignatures) *response.TechnologyReport {
+	report := &response.TechnologyReport{ByCategory: make(map[string][]string)}
+	for name, technology := range detected {
+		technology.Confidence = min(technology.Confidence, 100)
+		for _, category := range signatures.Technologies[name].cats {
+			categoryName, ok := signatures.Categories[category]
+			if !ok {
+				continue
+			}
+			technology.Categories = append(technology.Categories, categoryName)
+			report.ByCategory[categoryName] = append(report.ByCategory[categoryName], name)
+		}
+		report.Technologies = append(report.Technologies, *technology)
+	}
+
+	sort.Slice(report.Technologies, func(i, j int) bool {
+		return report.Technologies[i].Name < report.Technologies[j].Name
+	})
+	for _, names := range report.ByCategory {
+		sort.Strings(names)
+	}
+	return report
+}
+
+// collectPageEvidence gathers the values the signatures match against.
+func collectPageEvidence(wc *response.WebContent, doc *html.Node) *pageEvidence {
+	evidence := &pageEvidence{
+		headers:    wc.Headers,
+		cookies:    make(map[string]string),
+		meta:       make(map[string][]string),
+		rawContent: wc.Content,
+	}
+	if evidence.headers == nil {
+		evidence.headers = http.Header{}
+	}
+	for _, cookie := range (&http.Response{Header: evidence.headers}).Cookies() {
+		evidence.cookies[cookie.Name] = cookie.Value
+	}
+
+	for _, node := range findElements(doc, "meta", "script") {
+		switch node.Data {
+		case "meta":
+			key := strings.ToLower(firstNonEmpty(getAttr(node, "name"), getAttr(node, "property"), getAttr(node, "http-equiv")))
+			if key != constant.EMPTY {
+				evidence.meta[key] = append(evidence.meta[key], getAttr(node, "content"))
+			}
+		case "script":
+			if src := strings.TrimSpace(getAttr(node, constant.SRC)); src != constant.EMPTY {
+				evidence.scriptSrc = append(evidence.scriptSrc, src)
+			} else if text := nodeText(node); strings.TrimSpace(text) != constant.EMPTY {
+				evidence.scripts = append(evidence.scripts, text)
+			}
+		}
+	}
+	return evidence
+}
+
+// appendUnique appends value unless the list already contains it.
+func appendUnique(list []string, value string) []string {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+	return append(list, value)
+}
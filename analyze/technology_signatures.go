@@ -0,0 +1,259 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// bundledSignatures is the signature file shipped with the service, in the Wappalyzer technology format.
+//
+//go:embed signatures/technologies.json
+var bundledSignatures []byte
+
+// versionTernaryRegex matches the \1?a:b version template, which yields a when group 1 matched and b otherwise.
+var versionTernaryRegex = regexp.MustCompile(`^\\(\d)\?([^:]*):(.*)$`)
+
+var (
+	signaturesMu     sync.Mutex
+	signaturesCache  *TechnologySignatures
+	signaturesSource string
+)
+
+// TechnologySignatures holds the compiled technology fingerprints and the category names.
+type TechnologySignatures struct {
+	Categories   map[int]string
+	Technologies map[string]*technologySignature
+}
+
+type technologySignature struct {
+	name      string
+	cats      []int
+	website   string
+	headers   map[string][]*techPattern
+	cookies   map[string][]*techPattern
+	meta      map[string][]*techPattern
+	scriptSrc []*techPattern
+	html      []*techPattern
+	js        map[string]*regexp.Regexp
+	implies   []*techPattern
+	excludes  []string
+}
+
+// techPattern is a pattern with its \;version: and \;confidence: tags.
+type techPattern struct {
+	value      string
+	regex      *regexp.Regexp
+	version    string
+	confidence int
+}
+
+// signatureFile is the JSON layout of a signature file.
+type signatureFile struct {
+	Categories map[string]struct {
+		Name string `json:"name"`
+	} `json:"categories"`
+	Technologies map[string]struct {
+		Cats      []int                 `json:"cats"`
+		Website   string                `json:"website"`
+		Headers   map[string]stringList `json:"headers"`
+		Cookies   map[string]stringList `json:"cookies"`
+		Meta      map[string]stringList `json:"meta"`
+		ScriptSrc stringList            `json:"scriptSrc"`
+		Html      stringList            `json:"html"`
+		Js        map[string]string     `json:"js"`
+		Implies   stringList            `json:"implies"`
+		Excludes  stringList            `json:"excludes"`
+	} `json:"technologies"`
+}
+
+// stringList accepts a single string or an array of strings, as the signature format allows both.
+type stringList []string
+
+func (l *stringList) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*l = stringList{single}
+		return nil
+	}
+	var list []string
+	if err := json.Unmarshal(data, &list); err != nil {
+		return err
+	}
+	*l = list
+	return nil
+}
+
+// ParseTechnologySignatures compiles a signature file. Patterns that are not valid RE2 expressions are skipped.
+func ParseTechnologySignatures(data []byte) (*TechnologySignatures, error) {
+	var file signatureFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, err
+	}
+
+	signatures := &TechnologySignatures{
+		Categories:   make(map[int]string),
+		Technologies: make(map[string]*technologySignature),
+	}
+	for id, category := range file.Categories {
+		if number, err := strconv.Atoi(id); err == nil {
+			signatures.Categories[number] = category.Name
+		}
+	}
+
+	for name, raw := range file.Technologies {
+		signature := &technologySignature{
+			name:      name,
+			cats:      raw.Cats,
+			website:   raw.Website,
+			headers:   compilePatternMap(name, raw.Headers, true),
+			cookies:   compilePatternMap(name, raw.Cookies, false),
+			meta:      compilePatternMap(name, raw.Meta, true),
+			scriptSrc: compilePatterns(name, raw.ScriptSrc),
+			html:      compilePatterns(name, raw.Html),
+			js:        make(map[string]*regexp.Regexp),
+			implies:   compilePatterns(name, raw.Implies),
+			excludes:  raw.Excludes,
+		}
+		for variable := range raw.Js {
+			signature.js[variable] = globalVariableRegex(variable)
+		}
+		signatures.Technologies[name] = signature
+	}
+	return signatures, nil
+}
+
+// Merge adds the technologies and categories of other, replacing the ones with the same name.
+func (s *TechnologySignatures) Merge(other *TechnologySignatures) {
+	for id, name := range other.Categories {
+		s.Categories[id] = name
+	}
+	for name, signature := range other.Technologies {
+		s.Technologies[name] = signature
+	}
+}
+
+// loadTechnologySignatures returns the bundled signatures merged with the signature file configured in
+// TECH_SIGNATURES_PATH. The result is cached until the configured path changes.
+func loadTechnologySignatures() *TechnologySignatures {
+	path := configs.GetConfig().TechSignatures
+	signaturesMu.Lock()
+	defer signaturesMu.Unlock()
+	if signaturesCache != nil && signaturesSource == path {
+		return signaturesCache
+	}
+
+	signatures, err := ParseTechnologySignatures(bundledSignatures)
+	if err != nil {
+		log.Printf("Failed parsing bundled technology signatures: %v", err)
+		signatures = &TechnologySignatures{Categories: map[int]string{}, Technologies: map[string]*technologySignature{}}
+	}
+	if path != constant.EMPTY {
+		if custom, err := readTechnologySignatures(path); err != nil {
+			log.Printf("Failed loading technology signatures from %s: %v", path, err)
+		} else {
+			signatures.Merge(custom)
+		}
+	}
+	signaturesCache, signaturesSource = signatures, path
+	return signatures
+}
+
+func readTechnologySignatures(path string) (*TechnologySignatures, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return ParseTechnologySignatures(data)
+}
+
+func compilePatternMap(technology string, raw map[string]stringList, lowerKeys bool) map[string][]*techPattern {
+	patterns := make(map[string][]*techPattern, len(raw))
+	for key, values := range raw {
+		if lowerKeys {
+			key = strings.ToLower(key)
+		}
+		patterns[key] = compilePatterns(technology, values)
+	}
+	return patterns
+}
+
+func compilePatterns(technology string, values []string) []*techPattern {
+	var patterns []*techPattern
+	for _, value := range values {
+		pattern, err := parseTechPattern(value)
+		if err != nil {
+			log.Printf("Skipping technology pattern of %s: %q | Error: %v", technology, value, err)
+			continue
+		}
+		patterns = append(patterns, pattern)
+	}
+	return patterns
+}
+
+// parseTechPattern compiles a case insensitive pattern followed by its optional \;version: and \;confidence: tags.
+func parseTechPattern(value string) (*techPattern, error) {
+	parts := strings.Split(value, `\;`)
+	pattern := &techPattern{value: parts[0], confidence: 100}
+	regex, err := regexp.Compile("(?i)" + parts[0])
+	if err != nil {
+		return nil, err
+	}
+	pattern.regex = regex
+
+	for _, tag := range parts[1:] {
+		key, tagValue, ok := strings.Cut(tag, ":")
+		if !ok {
+			continue
+		}
+		switch key {
+		case "version":
+			pattern.version = tagValue
+		case "confidence":
+			if confidence, err := strconv.Atoi(tagValue); err == nil {
+				pattern.confidence = confidence
+			}
+		}
+	}
+	return pattern, nil
+}
+
+// match reports whether the pattern matches value and resolves the version template against the groups.
+func (p *techPattern) match(value string) (bool, string) {
+	groups := p.regex.FindStringSubmatch(value)
+	if groups == nil {
+		return false, constant.EMPTY
+	}
+	if p.version == constant.EMPTY {
+		return true, constant.EMPTY
+	}
+
+	version := p.version
+	if ternary := versionTernaryRegex.FindStringSubmatch(version); ternary != nil {
+		index, _ := strconv.Atoi(ternary[1])
+		if index < len(groups) && groups[index] != constant.EMPTY {
+			version = ternary[2]
+		} else {
+			version = ternary[3]
+		}
+	}
+	for index := len(groups) - 1; index > 0; index-- {
+		version = strings.ReplaceAll(version, fmt.Sprintf(`\%d`, index), groups[index])
+	}
+	return true, strings.TrimSpace(version)
+}
+
+// globalVariableRegex matches the assignment, declaration or use of a global JavaScript variable in a script.
+func globalVariableRegex(variable string) *regexp.Regexp {
+	name := regexp.QuoteMeta(variable)
+	return regexp.MustCompile(`(?:window\.|self\.|globalThis\.)` + name + `\b|\b(?:var|let|const|function)\s+` + name +
+		`\b|(?:^|[^\w$.])` + name + `\s*(?:=[^=]|\(|\.|\[)`)
+}
@@ -0,0 +1,124 @@
+package analyze
+
+import (
+	"api/constant"
+	"api/response"
+	"crypto/tls"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// TlsSecurityAnalyzer implements the Analyzer interface for the transport security of the page.
+type TlsSecurityAnalyzer struct{}
+
+// NewTlsSecurityAnalyzer creates a new TlsSecurityAnalyzer.
+func NewTlsSecurityAnalyzer() *TlsSecurityAnalyzer {
+	return &TlsSecurityAnalyzer{}
+}
+
+// Analyze records the TLS connection details and the HTTP to HTTPS redirect behavior of the page.
+func (a *TlsSecurityAnalyzer) Analyze(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	log.Println("Analyzing TLS security function is executed...")
+	startTime := time.Now()
+
+	defer func(start time.Time) {
+		log.Printf("TlsSecurityAnalyzer.Analyze succesfully completed in %d ms", time.Since(start).Milliseconds())
+	}(startTime)
+
+	finalUrl := wc.FinalUrl
+	if finalUrl == constant.EMPTY {
+		finalUrl = res.ExecutedUrl
+	}
+	parsedURL, err := url.Parse(finalUrl)
+	if err != nil {
+		return &response.ErrorResponse{
+			Message:  "Failed to parse page url while analyzing TLS security",
+			ErrorMsg: err.Error(),
+			Code:     http.StatusBadRequest,
+		}
+	}
+
+	info := ExtractTlsInfo(parsedURL.Hostname(), wc.TLS, startTime)
+	info.HttpsRedirect = checkHttpsRedirect(probeClient(wc), res.ExecutedUrl, parsedURL)
+	res.Tls = info
+	return nil
+}
+
+// ExtractTlsInfo builds the TLS report for host from the connection state of the page response.
+func ExtractTlsInfo(host string, state *tls.ConnectionState, now time.Time) *response.TlsInfo {
+	info := &response.TlsInfo{Host: host}
+	if state == nil {
+		return info
+	}
+
+	info.Enabled = true
+	info.Version = tls.VersionName(state.Version)
+	info.CipherSuite = tls.CipherSuiteName(state.CipherSuite)
+	info.OcspStapled = len(state.OCSPResponse) > 0
+
+	if len(state.PeerCertificates) == 0 {
+		return info
+	}
+
+	leaf := state.PeerCertificates[0]
+	info.Subject = leaf.Subject.String()
+	info.SANs = append(info.SANs, leaf.DNSNames...)
+	for _, ip := range leaf.IPAddresses {
+		info.SANs = append(info.SANs, ip.String())
+	}
+	for _, cert := range state.PeerCertificates {
+		info.IssuerChain = append(info.IssuerChain, cert.Issuer.String())
+	}
+	info.NotBefore = leaf.NotBefore
+	info.NotAfter = leaf.NotAfter
+	info.DaysRemaining = int(leaf.NotAfter.Sub(now).Hours() / 24)
+	info.Expired = now.After(leaf.NotAfter)
+	info.HostnameMatch = leaf.VerifyHostname(host) == nil
+	return info
+}
+
+// checkHttpsRedirect reports whether the page is upgraded from HTTP to HTTPS.
+// When the page was requested over HTTPS, the plain HTTP variant is probed without following redirects.
+func checkHttpsRedirect(pageClient *http.Client, executedUrl string, finalURL *url.URL) *response.HttpsRedirect {
+	redirect := &response.HttpsRedirect{}
+	requestedURL, err := url.Parse(executedUrl)
+	if err != nil {
+		return redirect
+	}
+
+	redirect.RequestedScheme = requestedURL.Scheme
+	if requestedURL.Scheme == constant.HTTP_SCHEME {
+		redirect.UpgradedToHttps = finalURL.Scheme == constant.HTTPS_SCHEME
+		redirect.RedirectsToHttps = redirect.UpgradedToHttps
+		return redirect
+	}
+	if requestedURL.Scheme != constant.HTTPS_SCHEME {
+		return redirect
+	}
+
+	probeURL := *requestedURL
+	probeURL.Scheme = constant.HTTP_SCHEME
+	if probeURL.Port() == "443" {
+		probeURL.Host = probeURL.Hostname()
+	}
+	redirect.HttpProbeUrl = probeURL.String()
+
+	client := *pageClient
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		return http.ErrUseLastResponse
+	}
+	resp, err := probeGet(&client, redirect.HttpProbeUrl)
+	if err != nil {
+		log.Printf("HTTP probe failed for %s: %v", redirect.HttpProbeUrl, err)
+		return redirect
+	}
+	defer resp.Body.Close()
+
+	redirect.HttpProbeStatus = resp.StatusCode
+	if location, err := resp.Location(); err == nil {
+		redirect.RedirectsToHttps = location.Scheme == constant.HTTPS_SCHEME
+	}
+	return redirect
+}
@@ -0,0 +1,139 @@
+package analyze
+
+import (
+	"api/configs"
+	"api/constant"
+	_ "embed"
+	"encoding/json"
+	"log"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+)
+
+// bundledTrackers is the tracker and consent platform list shipped with the service.
+//
+//go:embed signatures/trackers.json
+var bundledTrackers []byte
+
+var (
+	trackersMu     sync.Mutex
+	trackersCache  *TrackerList
+	trackersSource string
+)
+
+// TrackerList is the list of known tracker domains and consent-management platforms. Domains may carry a path,
+// such as facebook.com/tr, to match only part of a host.
+type TrackerList struct {
+	Trackers         []TrackerEntry    `json:"trackers"`
+	ConsentPlatforms []ConsentPlatform `json:"consentPlatforms"`
+}
+
+// TrackerEntry is a tracker with its category: analytics, advertising, social or session_replay.
+type TrackerEntry struct {
+	Name     string   `json:"name"`
+	Company  string   `json:"company"`
+	Category string   `json:"category"`
+	Domains  []string `json:"domains"`
+}
+
+// ConsentPlatform is a consent-management platform recognized by the hosts it loads from or markers in the page.
+type ConsentPlatform struct {
+	Name    string   `json:"name"`
+	Domains []string `json:"domains"`
+	Markers []string `json:"markers"`
+}
+
+// ParseTrackerList decodes a tracker list.
+func ParseTrackerList(data []byte) (*TrackerList, error) {
+	var list TrackerList
+	if err := json.Unmarshal(data, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// Merge adds the trackers and consent platforms of other, replacing the entries with the same name.
+func (l *TrackerList) Merge(other *TrackerList) {
+	for _, tracker := range other.Trackers {
+		replaced := false
+		for i := range l.Trackers {
+			if l.Trackers[i].Name == tracker.Name {
+				l.Trackers[i], replaced = tracker, true
+			}
+		}
+		if !replaced {
+			l.Trackers = append(l.Trackers, tracker)
+		}
+	}
+	for _, platform := range other.ConsentPlatforms {
+		replaced := false
+		for i := range l.ConsentPlatforms {
+			if l.ConsentPlatforms[i].Name == platform.Name {
+				l.ConsentPlatforms[i], replaced = platform, true
+			}
+		}
+		if !replaced {
+			l.ConsentPlatforms = append(l.ConsentPlatforms, platform)
+		}
+	}
+}
+
+// MatchTracker returns the tracker a link belongs to, or nil.
+func (l *TrackerList) MatchTracker(link string) *TrackerEntry {
+	for i := range l.Trackers {
+		if matchesAnyDomain(link, l.Trackers[i].Domains) {
+			return &l.Trackers[i]
+		}
+	}
+	return nil
+}
+
+// loadTrackerList returns the bundled tracker list merged with the list configured in TRACKER_LIST_PATH.
+// The result is cached until the configured path changes.
+func loadTrackerList() *TrackerList {
+	path := configs.GetConfig().TrackerList
+	trackersMu.Lock()
+	defer trackersMu.Unlock()
+	if trackersCache != nil && trackersSource == path {
+		return trackersCache
+	}
+
+	list, err := ParseTrackerList(bundledTrackers)
+	if err != nil {
+		log.Printf("Failed parsing bundled tracker list: %v", err)
+		list = &TrackerList{}
+	}
+	if path != constant.EMPTY {
+		if data, err := os.ReadFile(path); err != nil {
+			log.Printf("Failed loading tracker list from %s: %v", path, err)
+		} else if custom, err := ParseTrackerList(data); err != nil {
+			log.Printf("Failed parsing tracker list %s: %v", path, err)
+		} else {
+			list.Merge(custom)
+		}
+	}
+	trackersCache, trackersSource = list, path
+	return list
+}
+
+// matchesAnyDomain reports whether the link host is one of the domains or a subdomain of them, and its path
+// starts with the path of the domain entry when it has one.
+func matchesAnyDomain(link string, domains []string) bool {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return false
+	}
+	host := strings.ToLower(parsedURL.Hostname())
+	for _, domain := range domains {
+		domainHost, domainPath, _ := strings.Cut(strings.ToLower(domain), "/")
+		if host != domainHost && !strings.HasSuffix(host, "."+domainHost) {
+			continue
+		}
+		if domainPath == constant.EMPTY || strings.HasPrefix(strings.TrimPrefix(parsedURL.Path, "/"), domainPath) {
+			return true
+		}
+	}
+	return false
+}
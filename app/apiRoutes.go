@@ -19,6 +19,21 @@ func ApiRoutes(port string) {
 
 	// Register route handlers
 	apiGroup.GET("/analyze", handler.WebPageExecutorHandler)
+	apiGroup.GET("/crawl", handler.SiteCrawlHandler)
+	apiGroup.GET("/compare", handler.CompareHandler)
+	apiGroup.POST("/compare", handler.CompareReportsHandler)
+	apiGroup.GET("/reports", handler.ReportListHandler)
+	apiGroup.GET("/reports/:id", handler.ReportHandler)
+	apiGroup.GET("/monitors", handler.MonitorListHandler)
+	apiGroup.POST("/monitors", handler.MonitorCreateHandler)
+	apiGroup.GET("/monitors/:id", handler.MonitorHandler)
+	apiGroup.PUT("/monitors/:id", handler.MonitorUpdateHandler)
+	apiGroup.DELETE("/monitors/:id", handler.MonitorDeleteHandler)
+	apiGroup.GET("/monitors/:id/runs", handler.MonitorRunsHandler)
+	apiGroup.POST("/monitors/:id/run", handler.MonitorRunHandler)
+
+	// Run the registered monitors on their schedules
+	handler.StartMonitors()
 
 	// Start the server
 	if err := router.Run(port); err != nil {
@@ -0,0 +1,97 @@
+package handler
+
+import (
+	"api/analyze"
+	"api/constant"
+	"api/response"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CompareRequest holds two reports returned by the analyze endpoint, such as reports saved by a client.
+type CompareRequest struct {
+	Base   *response.SuccessResponse `json:"base"`
+	Target *response.SuccessResponse `json:"target"`
+}
+
+// CompareHandler returns the differences of the target report from the base report. Each report is a saved report
+// given by id or the analysis of a URL, URLs are analyzed concurrently.
+func CompareHandler(c *gin.Context) {
+	startTime := time.Now()
+	baseLink, targetLink := c.Query(constant.BASE), c.Query(constant.TARGET)
+	baseId, targetId := c.Query(constant.BASE_ID), c.Query(constant.TARGET_ID)
+	log.Println("compared web page reports :", baseLink+baseId, targetLink+targetId)
+
+	if (baseLink == constant.EMPTY && baseId == constant.EMPTY) || (targetLink == constant.EMPTY && targetId == constant.EMPTY) {
+		c.JSON(http.StatusBadRequest, gin.H{
+			constant.RESPONSE: response.ErrorResponseMsg("Both base and target URLs are required, or the ids of saved reports", nil, http.StatusBadRequest),
+		})
+		return
+	}
+
+	var base, target *response.SuccessResponse
+	var baseErr, targetErr *response.ErrorResponse
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		base, baseErr = compareInput(baseLink, baseId)
+	}()
+	go func() {
+		defer wg.Done()
+		target, targetErr = compareInput(targetLink, targetId)
+	}()
+	wg.Wait()
+
+	for _, errResp := range []*response.ErrorResponse{baseErr, targetErr} {
+		if errResp != nil {
+			c.JSON(errResp.Code, gin.H{
+				constant.RESPONSE: errResp,
+			})
+			return
+		}
+	}
+
+	report := analyze.CompareReports(base, target)
+	log.Printf("Comparison of %s and %s completed in %d ms", report.BaseUrl, report.TargetUrl, time.Since(startTime).Milliseconds())
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: report,
+	})
+}
+
+// compareInput loads the saved report when an id is given, and analyzes the link otherwise.
+func compareInput(link, id string) (*response.SuccessResponse, *response.ErrorResponse) {
+	if id == constant.EMPTY {
+		return AnalyzePage(link)
+	}
+	report, errResp := LoadReport(id)
+	if errResp != nil {
+		return nil, errResp
+	}
+	return report.Result, nil
+}
+
+// CompareReportsHandler returns the differences of two posted reports.
+func CompareReportsHandler(c *gin.Context) {
+	var request CompareRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			constant.RESPONSE: response.ErrorResponseMsg("Invalid comparison request", err.Error(), http.StatusBadRequest),
+		})
+		return
+	}
+	if request.Base == nil || request.Target == nil {
+		c.JSON(http.StatusBadRequest, gin.H{
+			constant.RESPONSE: response.ErrorResponseMsg("Both base and target reports are required", nil, http.StatusBadRequest),
+		})
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: analyze.CompareReports(request.Base, request.Target),
+	})
+}
@@ -0,0 +1,165 @@
+package handler
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/monitor"
+	"api/response"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"sync"
+
+	"github.com/gin-gonic/gin"
+)
+
+var (
+	monitorScheduler *monitor.Scheduler
+	monitorOnce      sync.Once
+)
+
+// GetMonitorScheduler returns the scheduler of the configured state file, which analyzes pages with AnalyzePage.
+// When the state file cannot be read the monitors are kept in memory only.
+func GetMonitorScheduler() *monitor.Scheduler {
+	monitorOnce.Do(func() {
+		cfg := configs.GetConfig()
+		var err error
+		monitorScheduler, err = monitor.NewScheduler(cfg.MonitorStatePath, cfg.MonitorRunHistory, cfg.MonitorCertExpiryDays, AnalyzePage)
+		if err != nil {
+			log.Println("Monitor state cannot be loaded, monitors are kept in memory only", err)
+			monitorScheduler, _ = monitor.NewScheduler(constant.EMPTY, cfg.MonitorRunHistory, cfg.MonitorCertExpiryDays, AnalyzePage)
+		}
+	})
+	return monitorScheduler
+}
+
+// SetMonitorScheduler replaces the monitor scheduler and returns the previous one.
+func SetMonitorScheduler(scheduler *monitor.Scheduler) *monitor.Scheduler {
+	previous := GetMonitorScheduler()
+	monitorScheduler = scheduler
+	return previous
+}
+
+// StartMonitors starts running the monitors on their schedules.
+func StartMonitors() {
+	GetMonitorScheduler().Start()
+}
+
+// MonitorListHandler lists the monitors with their last run.
+func MonitorListHandler(c *gin.Context) {
+	monitors := GetMonitorScheduler().List()
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: response.MonitorList{Count: len(monitors), Monitors: monitors},
+	})
+}
+
+// MonitorCreateHandler registers a monitor.
+func MonitorCreateHandler(c *gin.Context) {
+	var input monitor.MonitorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		monitorError(c, "Invalid monitor request", fmt.Errorf("%w: %v", monitor.ErrInvalidMonitor, err))
+		return
+	}
+	created, err := GetMonitorScheduler().Create(input)
+	if err != nil {
+		monitorError(c, "Monitor cannot be created", err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{
+		constant.RESPONSE: created,
+	})
+}
+
+// MonitorHandler returns a monitor with its runs.
+func MonitorHandler(c *gin.Context) {
+	found, err := GetMonitorScheduler().Get(c.Param(constant.ID))
+	if err != nil {
+		monitorError(c, "Monitor cannot be read", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: found,
+	})
+}
+
+// MonitorUpdateHandler replaces the definition of a monitor.
+func MonitorUpdateHandler(c *gin.Context) {
+	var input monitor.MonitorInput
+	if err := c.ShouldBindJSON(&input); err != nil {
+		monitorError(c, "Invalid monitor request", fmt.Errorf("%w: %v", monitor.ErrInvalidMonitor, err))
+		return
+	}
+	updated, err := GetMonitorScheduler().Update(c.Param(constant.ID), input)
+	if err != nil {
+		monitorError(c, "Monitor cannot be updated", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: updated,
+	})
+}
+
+// MonitorDeleteHandler removes a monitor and its runs.
+func MonitorDeleteHandler(c *gin.Context) {
+	deleted, err := GetMonitorScheduler().Delete(c.Param(constant.ID))
+	if err != nil {
+		monitorError(c, "Monitor cannot be deleted", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: deleted,
+	})
+}
+
+// MonitorRunsHandler returns the last runs of a monitor, newest first, up to the limit parameter.
+func MonitorRunsHandler(c *gin.Context) {
+	found, err := GetMonitorScheduler().Get(c.Param(constant.ID))
+	if err != nil {
+		monitorError(c, "Monitor cannot be read", err)
+		return
+	}
+	runs := found.Runs
+	if value := c.Query(constant.LIMIT); value != constant.EMPTY {
+		limit, err := strconv.Atoi(value)
+		if err != nil || limit < 1 {
+			c.JSON(http.StatusBadRequest, gin.H{
+				constant.RESPONSE: response.ErrorResponseMsg("limit must be a positive number", value, http.StatusBadRequest),
+			})
+			return
+		}
+		runs = runs[:min(limit, len(runs))]
+	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: runs,
+	})
+}
+
+// MonitorRunHandler runs a monitor now and returns the run.
+func MonitorRunHandler(c *gin.Context) {
+	run, err := GetMonitorScheduler().Run(c.Param(constant.ID))
+	if err != nil {
+		monitorError(c, "Monitor cannot be run", err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: run,
+	})
+}
+
+// monitorError writes the error response of a monitor request, with the status of the error.
+func monitorError(c *gin.Context, message string, err error) {
+	code := http.StatusInternalServerError
+	switch {
+	case errors.Is(err, monitor.ErrInvalidMonitor):
+		code = http.StatusBadRequest
+	case errors.Is(err, monitor.ErrMonitorNotFound):
+		code = http.StatusNotFound
+	case errors.Is(err, monitor.ErrMonitorBusy):
+		code = http.StatusConflict
+	}
+	c.JSON(code, gin.H{
+		constant.RESPONSE: response.ErrorResponseMsg(message, err.Error(), code),
+	})
+}
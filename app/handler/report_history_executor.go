@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"api/store"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultReportLimit is the number of reports listed when limit is not given.
+	defaultReportLimit = 50
+	// maxReportLimit bounds the limit parameter.
+	maxReportLimit = 500
+	// reportDateLayout is the date only form accepted by the from and to parameters.
+	reportDateLayout = "2006-01-02"
+)
+
+// ReportListHandler lists the saved reports, newest first, filtered by URL, host and date.
+func ReportListHandler(c *gin.Context) {
+	reports := store.GetReportStore()
+	if reports == nil {
+		errResp := reportHistoryDisabled()
+		c.JSON(errResp.Code, gin.H{
+			constant.RESPONSE: errResp,
+		})
+		return
+	}
+
+	filter, filterErr := ParseReportFilter(c)
+	if filterErr != nil {
+		c.JSON(filterErr.Code, gin.H{
+			constant.RESPONSE: filterErr,
+		})
+		return
+	}
+
+	summaries := reports.List(filter)
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: response.ReportHistory{Count: len(summaries), Reports: summaries},
+	})
+}
+
+// ReportHandler returns a saved report with its full result.
+func ReportHandler(c *gin.Context) {
+	report, errResp := LoadReport(c.Param(constant.ID))
+	if errResp != nil {
+		c.JSON(errResp.Code, gin.H{
+			constant.RESPONSE: errResp,
+		})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: report,
+	})
+}
+
+// ParseReportFilter reads the report filter from the query. Dates are RFC 3339 times or dates, a to date includes
+// the whole day.
+func ParseReportFilter(c *gin.Context) (store.ReportFilter, *response.ErrorResponse) {
+	filter := store.ReportFilter{
+		Url:   c.Query(constant.URL),
+		Host:  c.Query(constant.HOST),
+		Limit: defaultReportLimit,
+	}
+	var err error
+
+	if value := c.Query(constant.FROM); value != constant.EMPTY {
+		if filter.From, _, err = parseReportTime(value); err != nil {
+			return filter, reportFilterError("from must be a date or an RFC 3339 time", value)
+		}
+	}
+	if value := c.Query(constant.TO); value != constant.EMPTY {
+		var dateOnly bool
+		if filter.To, dateOnly, err = parseReportTime(value); err != nil {
+			return filter, reportFilterError("to must be a date or an RFC 3339 time", value)
+		}
+		if dateOnly {
+			filter.To = filter.To.AddDate(0, 0, 1)
+		}
+	}
+	if value := c.Query(constant.LIMIT); value != constant.EMPTY {
+		if filter.Limit, err = strconv.Atoi(value); err != nil || filter.Limit < 1 || filter.Limit > maxReportLimit {
+			return filter, reportFilterError("limit must be a number from 1 to "+strconv.Itoa(maxReportLimit), value)
+		}
+	}
+	return filter, nil
+}
+
+// LoadReport reads a saved report by id.
+func LoadReport(id string) (*response.StoredReport, *response.ErrorResponse) {
+	reports := store.GetReportStore()
+	if reports == nil {
+		return nil, reportHistoryDisabled()
+	}
+	report, err := reports.Get(id)
+	if errors.Is(err, store.ErrReportNotFound) {
+		errResp := response.ErrorResponseMsg("Report not found", id, http.StatusNotFound)
+		return nil, &errResp
+	}
+	if err != nil {
+		log.Println("Error occurred while reading saved report", err)
+		errResp := response.ErrorResponseMsg("Error occurred while reading report", err.Error(), http.StatusInternalServerError)
+		return nil, &errResp
+	}
+	return report, nil
+}
+
+// SaveReport keeps the result of an analysis of link in the report history, when it is enabled, and sets the id of
+// the saved report on the result. A failure is logged and does not fail the analysis.
+func SaveReport(link string, res *response.SuccessResponse) {
+	reports := store.GetReportStore()
+	if reports == nil {
+		return
+	}
+	report, err := reports.Save(link, analysisOptions(), res)
+	if err != nil {
+		log.Println("Error occurred while saving report", err)
+		return
+	}
+	res.ReportId = report.Id
+}
+
+// analysisOptions are the settings an analysis ran with, saved with its report.
+func analysisOptions() map[string]string {
+	cfg := configs.GetConfig()
+	return map[string]string{
+		"linkProbe":   strconv.FormatBool(cfg.LinkProbe),
+		"honorRobots": strconv.FormatBool(cfg.HonorRobots),
+		"userAgent":   cfg.UserAgent,
+	}
+}
+
+// parseReportTime parses an RFC 3339 time or a date, and reports whether it was a date.
+func parseReportTime(value string) (time.Time, bool, error) {
+	if parsed, err := time.Parse(time.RFC3339, value); err == nil {
+		return parsed, false, nil
+	}
+	parsed, err := time.Parse(reportDateLayout, value)
+	return parsed, true, err
+}
+
+// reportHistoryDisabled creates the response of a report history request when no store is configured.
+func reportHistoryDisabled() *response.ErrorResponse {
+	errResp := response.ErrorResponseMsg("Report history is not enabled", nil, http.StatusNotFound)
+	return &errResp
+}
+
+// reportFilterError creates the bad request response of an invalid report filter.
+func reportFilterError(message, detail string) *response.ErrorResponse {
+	errResp := response.ErrorResponseMsg(message, detail, http.StatusBadRequest)
+	return &errResp
+}
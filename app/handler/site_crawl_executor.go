@@ -0,0 +1,99 @@
+package handler
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"log"
+	"net/http"
+	"regexp"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// defaultCrawlDepth is the link depth followed when maxDepth is not given.
+	defaultCrawlDepth = 2
+	// maxCrawlDepth bounds the maxDepth parameter.
+	maxCrawlDepth = 10
+	// defaultCrawlPages is the number of pages crawled when maxPages is not given.
+	defaultCrawlPages = 20
+)
+
+func SiteCrawlHandler(c *gin.Context) {
+	startTime := time.Now()
+	link := c.Query(constant.URL)
+	log.Println("crawled site seed url :", link)
+
+	if ValidateWebUrl(link, c) {
+		return
+	}
+
+	options, optionsErr := ParseCrawlOptions(c)
+	if optionsErr != nil {
+		c.JSON(optionsErr.Code, gin.H{
+			constant.RESPONSE: optionsErr,
+		})
+		return
+	}
+
+	if CheckRobotsTxt(link, c) {
+		return
+	}
+
+	report := NewSiteCrawler(options).Crawl(link)
+	report.CrawlTime = time.Since(startTime).Milliseconds()
+	log.Printf("Site crawl of %d pages completed in %d ms", report.PageCount, report.CrawlTime)
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: report,
+	})
+}
+
+// ParseCrawlOptions reads the crawl limits and URL patterns from the query. maxPages is capped by the configured
+// maximum.
+func ParseCrawlOptions(c *gin.Context) (CrawlOptions, *response.ErrorResponse) {
+	options := CrawlOptions{MaxDepth: defaultCrawlDepth, MaxPages: defaultCrawlPages, UseSitemap: true}
+	var err error
+
+	if value := c.Query(constant.MAX_DEPTH); value != constant.EMPTY {
+		options.MaxDepth, err = strconv.Atoi(value)
+		if err != nil || options.MaxDepth < 0 || options.MaxDepth > maxCrawlDepth {
+			return options, crawlOptionError("maxDepth must be a number from 0 to "+strconv.Itoa(maxCrawlDepth), value)
+		}
+	}
+	if value := c.Query(constant.MAX_PAGES); value != constant.EMPTY {
+		if options.MaxPages, err = strconv.Atoi(value); err != nil || options.MaxPages < 1 {
+			return options, crawlOptionError("maxPages must be a positive number", value)
+		}
+	}
+	options.MaxPages = min(options.MaxPages, configs.GetConfig().CrawlMaxPages)
+
+	if value := c.Query(constant.SITEMAP); value != constant.EMPTY {
+		if options.UseSitemap, err = strconv.ParseBool(value); err != nil {
+			return options, crawlOptionError("sitemap must be true or false", value)
+		}
+	}
+	for _, pattern := range c.QueryArray(constant.INCLUDE) {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return options, crawlOptionError("Invalid include pattern", err.Error())
+		}
+		options.Include = append(options.Include, compiled)
+	}
+	for _, pattern := range c.QueryArray(constant.EXCLUDE) {
+		compiled, err := regexp.Compile(pattern)
+		if err != nil {
+			return options, crawlOptionError("Invalid exclude pattern", err.Error())
+		}
+		options.Exclude = append(options.Exclude, compiled)
+	}
+	return options, nil
+}
+
+// crawlOptionError creates the bad request response of an invalid crawl parameter.
+func crawlOptionError(message, detail string) *response.ErrorResponse {
+	errResp := response.ErrorResponseMsg(message, detail, http.StatusBadRequest)
+	return &errResp
+}
@@ -0,0 +1,426 @@
+package handler
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// crawlConcurrency bounds the number of pages of a crawl requested and analyzed at the same time.
+	crawlConcurrency = 4
+	// maxCrawlDelay bounds the crawl delay asked for by robots.txt, longer delays would outlast the request.
+	maxCrawlDelay = 10 * time.Second
+	// maxSitemapSeeds bounds how many sitemap URLs are read to seed the crawl and find orphan pages.
+	maxSitemapSeeds = 1000
+)
+
+// CrawlOptions bounds a site crawl and selects the pages it follows.
+type CrawlOptions struct {
+	MaxDepth   int
+	MaxPages   int
+	Include    []*regexp.Regexp
+	Exclude    []*regexp.Regexp
+	UseSitemap bool
+}
+
+// crawlTarget is a page waiting to be crawled with the page it was found on.
+type crawlTarget struct {
+	url      string
+	depth    int
+	referrer string
+}
+
+// crawlResult is a crawled page with the internal links and redirects found on it.
+type crawlResult struct {
+	page      response.CrawledPage
+	links     []string
+	redirects []response.Redirect
+	finalUrl  string
+}
+
+// SiteCrawler crawls the pages of one host breadth first and runs every page through the analyzers.
+type SiteCrawler struct {
+	options  CrawlOptions
+	client   *http.Client
+	host     string
+	throttle *hostThrottle
+	seen     map[string]bool
+	// linkedFrom maps every internal link found to the pages linking to it
+	linkedFrom map[string][]string
+	report     *response.SiteReport
+	// pageClient requests the pages and the probes of the analyzers, spacing those to the crawled host by the
+	// politeness delay
+	pageClient *http.Client
+}
+
+// NewSiteCrawler creates a new SiteCrawler with the given options.
+func NewSiteCrawler(options CrawlOptions) *SiteCrawler {
+	s := &SiteCrawler{
+		options:    options,
+		client:     configs.GetConfig().Client,
+		throttle:   &hostThrottle{next: make(map[string]time.Time)},
+		seen:       make(map[string]bool),
+		linkedFrom: make(map[string][]string),
+	}
+	pageClient := *s.client
+	pageClient.Transport = &throttledTransport{crawler: s, base: s.client.Transport}
+	s.pageClient = &pageClient
+	return s
+}
+
+// Crawl starts from the seed URL, follows the internal links and the sitemap up to the depth and page limits, and
+// returns the site report.
+func (s *SiteCrawler) Crawl(seed string) *response.SiteReport {
+	seed = analyze.NormalizePageURL(seed)
+	s.report = &response.SiteReport{SeedUrl: seed, MaxDepth: s.options.MaxDepth, MaxPages: s.options.MaxPages}
+	seedURL, err := url.Parse(seed)
+	if err != nil || seed == constant.EMPTY {
+		return s.report
+	}
+	s.host = strings.ToLower(seedURL.Host)
+	s.seen[seed] = true
+
+	var sitemapPages []string
+	if s.options.UseSitemap {
+		sitemapPages = s.sitemapPages(seed)
+	}
+
+	level := []crawlTarget{{url: seed}}
+	var results []crawlResult
+	for depth := 0; len(level) > 0; depth++ {
+		remaining := s.options.MaxPages - len(results)
+		if remaining <= 0 {
+			s.report.Truncated = true
+			break
+		}
+		if len(level) > remaining {
+			level, s.report.Truncated = level[:remaining], true
+		}
+		log.Printf("Crawling %d pages at depth %d", len(level), depth)
+		crawled := s.crawlLevel(level)
+
+		var next []crawlTarget
+		if depth == 0 && depth < s.options.MaxDepth {
+			for _, page := range sitemapPages {
+				if s.enqueue(page) {
+					next = append(next, crawlTarget{url: page, depth: depth + 1})
+				}
+			}
+		}
+		for _, result := range crawled {
+			for _, link := range result.links {
+				s.linkedFrom[link] = append(s.linkedFrom[link], result.page.Url)
+				if depth < s.options.MaxDepth && s.enqueue(link) {
+					next = append(next, crawlTarget{url: link, depth: depth + 1, referrer: result.page.Url})
+				}
+			}
+		}
+		results = append(results, crawled...)
+		level = next
+	}
+
+	for _, result := range results {
+		s.report.Pages = append(s.report.Pages, result.page)
+	}
+	s.report.PageCount = len(s.report.Pages)
+	s.auditSite(results, seed, sitemapPages)
+	return s.report
+}
+
+// crawlLevel crawls the pages of one depth concurrently and returns them in the order they were found.
+func (s *SiteCrawler) crawlLevel(level []crawlTarget) []crawlResult {
+	results := make([]crawlResult, len(level))
+	var wg sync.WaitGroup
+	limit := make(chan struct{}, crawlConcurrency)
+	for i, target := range level {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			limit <- struct{}{}
+			defer func() { <-limit }()
+			results[i] = s.crawlPage(target)
+		}()
+	}
+	wg.Wait()
+
+	// a page reached through a redirect is not crawled again under its final URL
+	for _, result := range results {
+		if result.finalUrl != constant.EMPTY {
+			s.seen[result.finalUrl] = true
+		}
+	}
+	return results
+}
+
+// enqueue reports whether link is a new page of the crawled host that passes the include and exclude patterns
+// and robots.txt. Disallowed pages are recorded in the report.
+func (s *SiteCrawler) enqueue(link string) bool {
+	link = analyze.NormalizePageURL(link)
+	if link == constant.EMPTY || s.seen[link] {
+		return false
+	}
+	linkURL, err := url.Parse(link)
+	if err != nil || !strings.EqualFold(linkURL.Host, s.host) {
+		return false
+	}
+	if len(s.options.Include) > 0 && !slices.ContainsFunc(s.options.Include, func(pattern *regexp.Regexp) bool {
+		return pattern.MatchString(link)
+	}) {
+		return false
+	}
+	if slices.ContainsFunc(s.options.Exclude, func(pattern *regexp.Regexp) bool { return pattern.MatchString(link) }) {
+		return false
+	}
+	s.seen[link] = true
+	if allowed, _ := analyze.CrawlAllowed(link); !allowed {
+		s.report.Disallowed = append(s.report.Disallowed, link)
+		return false
+	}
+	return true
+}
+
+// crawlPage requests a page and runs HTML pages through the analyzers. The page and the links and resources the
+// analyzers probe on the crawled host wait for the politeness delay.
+func (s *SiteCrawler) crawlPage(target crawlTarget) crawlResult {
+	result := crawlResult{page: response.CrawledPage{Url: target.url, Depth: target.depth, Referrer: target.referrer}}
+
+	startTime := time.Now()
+	resp, err := s.pageClient.Get(target.url)
+	if err != nil {
+		log.Printf("Failed crawling page: %s | Error: %v", target.url, err)
+		result.page.Error = err.Error()
+		return result
+	}
+	defer resp.Body.Close()
+
+	result.page.Status = resp.StatusCode
+	result.page.ContentType, _, _ = mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	result.redirects = collectRedirects(resp)
+	if resp.Request != nil {
+		result.finalUrl = analyze.NormalizePageURL(resp.Request.URL.String())
+	}
+	if resp.StatusCode != http.StatusOK || !analyze.IsHTMLMediaType(result.page.ContentType) {
+		return result
+	}
+
+	res, wc, analysisErr := analyzeResponse(target.url, resp, s.pageClient, startTime)
+	if analysisErr != nil {
+		result.page.Error = analysisErr.Message
+		return result
+	}
+	result.page.Result = res
+
+	if doc, err := wc.Document(); err == nil {
+		result.links = analyze.InternalPageLinks(doc, wc.FinalUrl)
+	}
+	return result
+}
+
+// crawlDelay returns the configured delay between requests, or the longer crawl delay robots.txt asks for when it
+// is honored.
+func (s *SiteCrawler) crawlDelay(link string) time.Duration {
+	cfg := configs.GetConfig()
+	delay := cfg.CrawlDelay
+	if cfg.HonorRobots {
+		robotsDelay := time.Duration(analyze.LoadRobots(s.client, link).CrawlDelay(cfg.UserAgent) * float64(time.Second))
+		delay = max(delay, min(robotsDelay, maxCrawlDelay))
+	}
+	return delay
+}
+
+// sitemapPages lists the pages of the sitemaps declared in robots.txt, or of /sitemap.xml when none is declared.
+func (s *SiteCrawler) sitemapPages(seed string) []string {
+	var sitemaps []string
+	if robots := analyze.LoadRobots(s.client, seed); robots != nil {
+		sitemaps = robots.Sitemaps
+	}
+	if len(sitemaps) == 0 {
+		seedURL, _ := url.Parse(seed)
+		sitemaps = []string{fmt.Sprintf("%s://%s/sitemap.xml", seedURL.Scheme, seedURL.Host)}
+	}
+	var pages []string
+	for _, link := range analyze.ListSitemapUrls(s.pageClient, sitemaps, maxSitemapSeeds) {
+		if page := analyze.NormalizePageURL(link); page != constant.EMPTY {
+			pages = append(pages, page)
+		}
+	}
+	return pages
+}
+
+// auditSite reports the duplicate titles and descriptions, the sitemap pages no crawled page links to, the
+// broken internal links with the pages linking to them, and the redirect chains.
+func (s *SiteCrawler) auditSite(results []crawlResult, seed string, sitemapPages []string) {
+	report := s.report
+	var titles, descriptions [][2]string
+	for _, result := range results {
+		if res := result.page.Result; res != nil {
+			titles = append(titles, [2]string{res.Title, result.page.Url})
+			if res.Seo != nil {
+				descriptions = append(descriptions, [2]string{res.Seo.Description, result.page.Url})
+			}
+		}
+	}
+	report.DuplicateTitles = duplicateGroups(titles)
+	for _, group := range report.DuplicateTitles {
+		report.Findings = append(report.Findings, newSiteFinding("duplicate-title", constant.SEVERITY_WARNING,
+			fmt.Sprintf("%d pages share the title %q", len(group.Urls), group.Value)))
+	}
+	report.DuplicateDescriptions = duplicateGroups(descriptions)
+	for _, group := range report.DuplicateDescriptions {
+		report.Findings = append(report.Findings, newSiteFinding("duplicate-description", constant.SEVERITY_WARNING,
+			fmt.Sprintf("%d pages share the meta description %q", len(group.Urls), group.Value)))
+	}
+
+	for _, page := range sitemapPages {
+		if _, linked := s.linkedFrom[page]; !linked && page != seed && !slices.Contains(report.OrphanPages, page) {
+			report.OrphanPages = append(report.OrphanPages, page)
+		}
+	}
+	if len(report.OrphanPages) > 0 {
+		report.Findings = append(report.Findings, newSiteFinding("orphan-pages", constant.SEVERITY_INFO,
+			fmt.Sprintf("%d pages listed in the sitemap are not linked from any crawled page", len(report.OrphanPages))))
+	}
+
+	report.BrokenLinks = s.brokenLinks(results)
+	for _, link := range report.BrokenLinks {
+		report.Findings = append(report.Findings, newSiteFinding("broken-internal-link", constant.SEVERITY_ERROR,
+			fmt.Sprintf("%s returned status %d and is linked from %d pages", link.Url, link.Status, len(link.Referrers))))
+	}
+
+	for _, result := range results {
+		if len(result.redirects) == 0 {
+			continue
+		}
+		chain := response.RedirectChain{Url: result.page.Url, FinalUrl: result.finalUrl, Hops: len(result.redirects)}
+		for _, redirect := range result.redirects {
+			chain.Chain = append(chain.Chain, redirect.Url)
+		}
+		chain.Chain = append(chain.Chain, result.finalUrl)
+		report.RedirectChains = append(report.RedirectChains, chain)
+		if chain.Hops > 1 {
+			report.Findings = append(report.Findings, newSiteFinding("redirect-chain", constant.SEVERITY_WARNING,
+				fmt.Sprintf("%s redirects %d times before reaching %s", chain.Url, chain.Hops, chain.FinalUrl)))
+		}
+	}
+
+	if report.Truncated {
+		report.Findings = append(report.Findings, newSiteFinding("crawl-truncated", constant.SEVERITY_INFO,
+			fmt.Sprintf("The crawl stopped at %d pages, site-level results cover the crawled pages only", report.MaxPages)))
+	}
+}
+
+// brokenLinks lists the crawled pages and the probed internal links that returned an error status, with the pages
+// linking to them, ordered by URL.
+func (s *SiteCrawler) brokenLinks(results []crawlResult) []response.BrokenLink {
+	broken := make(map[string]*response.BrokenLink)
+	add := func(link string, status int, referrers ...string) {
+		entry, ok := broken[link]
+		if !ok {
+			entry = &response.BrokenLink{Url: link, Status: status}
+			broken[link] = entry
+		}
+		for _, referrer := range referrers {
+			if !slices.Contains(entry.Referrers, referrer) {
+				entry.Referrers = append(entry.Referrers, referrer)
+			}
+		}
+	}
+
+	for _, result := range results {
+		if result.page.Status >= http.StatusBadRequest {
+			add(result.page.Url, result.page.Status, s.linkedFrom[result.page.Url]...)
+		}
+		if result.page.Result == nil {
+			continue
+		}
+		for _, link := range result.page.Result.Urls {
+			linkURL, err := url.Parse(link.Url)
+			if err != nil || !strings.EqualFold(linkURL.Host, s.host) || link.Status < http.StatusBadRequest {
+				continue
+			}
+			add(analyze.NormalizePageURL(link.Url), link.Status, result.page.Url)
+		}
+	}
+
+	var links []response.BrokenLink
+	for _, link := range broken {
+		slices.Sort(link.Referrers)
+		links = append(links, *link)
+	}
+	slices.SortFunc(links, func(a, b response.BrokenLink) int { return strings.Compare(a.Url, b.Url) })
+	return links
+}
+
+// duplicateGroups groups the URLs by their non-empty value and returns the values shared by more than one URL in
+// the order they were first seen.
+func duplicateGroups(values [][2]string) []response.DuplicateGroup {
+	var groups []response.DuplicateGroup
+	index := make(map[string]int)
+	for _, value := range values {
+		text := strings.Join(strings.Fields(value[0]), " ")
+		if text == constant.EMPTY {
+			continue
+		}
+		if i, ok := index[text]; ok {
+			groups[i].Urls = append(groups[i].Urls, value[1])
+			continue
+		}
+		index[text] = len(groups)
+		groups = append(groups, response.DuplicateGroup{Value: text, Urls: []string{value[1]}})
+	}
+	return slices.DeleteFunc(groups, func(group response.DuplicateGroup) bool { return len(group.Urls) < 2 })
+}
+
+// newSiteFinding creates a site-level finding.
+func newSiteFinding(rule, severity, message string) response.Finding {
+	return response.Finding{Rule: rule, Severity: severity, Message: message}
+}
+
+// hostThrottle spaces the requests to each host by the politeness delay.
+type hostThrottle struct {
+	mu   sync.Mutex
+	next map[string]time.Time
+}
+
+// wait blocks until a request to host may be sent and reserves the next slot delay later.
+func (t *hostThrottle) wait(host string, delay time.Duration) {
+	t.mu.Lock()
+	at := t.next[host]
+	if now := time.Now(); at.Before(now) {
+		at = now
+	}
+	t.next[host] = at.Add(delay)
+	t.mu.Unlock()
+	time.Sleep(time.Until(at))
+}
+
+// throttledTransport waits for the politeness delay of the crawl before each request to the crawled host, so the
+// probes of the analyzers are spaced like the page requests.
+type throttledTransport struct {
+	crawler *SiteCrawler
+	base    http.RoundTripper
+}
+
+// RoundTrip sends the request with the base transport, or the default one when it is nil.
+func (t *throttledTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if strings.EqualFold(req.URL.Host, t.crawler.host) {
+		t.crawler.throttle.wait(t.crawler.host, t.crawler.crawlDelay(req.URL.String()))
+	}
+	base := t.base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return base.RoundTrip(req)
+}
@@ -18,41 +18,112 @@ import (
 )
 
 func WebPageExecutorHandler(c *gin.Context) {
-	startTime := time.Now()
 	link := c.Query(constant.URL)
 	log.Println("executed web page url :", link)
 
-	res, notValid := ValidateWebUrl(link, c)
-	if notValid {
+	if ValidateWebUrl(link, c) {
 		return
 	}
 
-	resp, webUrlError := CallWebUrl(link, c)
-	if webUrlError {
+	res, errResp := AnalyzePage(link)
+	if errResp != nil {
+		c.JSON(errResp.Code, gin.H{
+			constant.RESPONSE: errResp,
+		})
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{
+		constant.RESPONSE: res,
+	})
+}
 
-	body, err := HandleResponseBodyRead(resp, c)
-	if err {
-		return
+// AnalyzePage requests the page, runs it through the analyzers and saves the report. It is the analysis of the
+// analyze endpoint, shared with the handlers that work on several reports.
+func AnalyzePage(link string) (*response.SuccessResponse, *response.ErrorResponse) {
+	startTime := time.Now()
+	if allowed, rule := analyze.CrawlAllowed(link); !allowed {
+		log.Println("Web page url is disallowed by robots.txt", link, rule)
+		errResp := response.ErrorResponseMsg("URL is disallowed by robots.txt", rule, http.StatusForbidden)
+		return nil, &errResp
+	}
+	client := configs.GetConfig().Client
+	resp, err := client.Get(link)
+	if err != nil {
+		log.Println("Error occurred while call web page url", err)
+		errResp := response.ErrorResponseMsg("Error occurred while call web page url", err.Error(), http.StatusBadRequest)
+		return nil, &errResp
 	}
+	defer resp.Body.Close()
 
-	resTime := time.Since(startTime).Milliseconds()
-	res.WebPageExtractTime = resTime
-	log.Printf("Web page analysis success with time: %d ms", resTime)
+	res, _, errResp := analyzeResponse(link, resp, client, startTime)
+	if errResp != nil {
+		return nil, errResp
+	}
+	SaveReport(link, res)
+	return res, nil
+}
 
-	wc := &response.WebContent{
-		Content: string(body),
+// analyzeResponse reads the page of the response and runs it through the analyzers, which probe the links and
+// resources of the page with client. It returns the report with the web content it was built from.
+func analyzeResponse(link string, resp *http.Response, client *http.Client, startTime time.Time) (*response.SuccessResponse, *response.WebContent, *response.ErrorResponse) {
+	res := &response.SuccessResponse{ExecutedUrl: link}
+	if parsedURL, err := url.Parse(link); err == nil {
+		res.BasePath = fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		log.Println("Error occurred while reading response body", err)
+		errResp := response.ErrorResponseMsg("Error occurred while reading body", err.Error(), http.StatusBadRequest)
+		return nil, nil, &errResp
 	}
+	res.WebPageExtractTime = time.Since(startTime).Milliseconds()
+	log.Printf("Web page analysis success with time: %d ms", res.WebPageExtractTime)
+
+	wc := BuildWebContent(body, resp)
+	wc.Client = client
+	res.Redirects = wc.Redirects
+	res.PageStatus = resp.StatusCode
+	if firstErr := RunAnalyzers(wc, res); firstErr != nil {
+		return nil, nil, firstErr
+	}
+	res.AppExecuteTotalTime = time.Since(startTime).Milliseconds()
+	return res, wc, nil
+}
 
-	// Create a list of analyzers
-	analyzers := []analyze.Analyzer{
+// NewAnalyzers creates the list of analyzers every page runs through.
+func NewAnalyzers() []analyze.Analyzer {
+	return []analyze.Analyzer{
 		analyze.NewHtmlVersionAnalyzer(),
 		analyze.NewHtmlTitleAnalyzer(),
 		analyze.NewHtmlLoginFormAnalyzer(),
+		analyze.NewHtmlFormAnalyzer(),
 		analyze.NewHtmlHeadingAnalyzer(),
 		analyze.NewHtmlUrlLinkAnalyzer(),
+		analyze.NewTlsSecurityAnalyzer(),
+		analyze.NewSecurityHeadersAnalyzer(),
+		analyze.NewCookieAnalyzer(),
+		analyze.NewMixedContentAnalyzer(),
+		analyze.NewSeoMetadataAnalyzer(),
+		analyze.NewSocialMetadataAnalyzer(),
+		analyze.NewStructuredDataAnalyzer(),
+		analyze.NewAccessibilityAnalyzer(),
+		analyze.NewImageAnalyzer(),
+		analyze.NewResourceAnalyzer(),
+		analyze.NewTechnologyAnalyzer(),
+		analyze.NewPrivacyAnalyzer(),
+		analyze.NewConformanceAnalyzer(),
+		analyze.NewContentAnalyzer(),
+		analyze.NewContactAnalyzer(),
+		analyze.NewDiscoveryAnalyzer(),
+		analyze.NewRobotsAnalyzer(),
 	}
+}
+
+// RunAnalyzers executes the analyzers concurrently on the page and returns the first error, which cancels the
+// analyzers that have not started yet.
+func RunAnalyzers(wc *response.WebContent, res *response.SuccessResponse) *response.ErrorResponse {
+	analyzers := NewAnalyzers()
 
 	// Execute analyzers concurrently
 	var wg sync.WaitGroup
@@ -94,62 +165,64 @@ func WebPageExecutorHandler(c *gin.Context) {
 	if firstErr := <-errChan; firstErr != nil {
 		// An error occurred in one of the analyzers
 		log.Printf("First error received, terminating analysis. Error: %s", firstErr.Message)
-		c.JSON(firstErr.Code, gin.H{
-			constant.RESPONSE: firstErr,
-		})
-		return
+		return firstErr
 	}
-
-	// If we reach here, all analyzers completed successfully or were cancelled
-	// but no error was sent to errChan before it was closed by wg.Wait().
-	appExecuteTotalTime := time.Since(startTime).Milliseconds()
-	res.AppExecuteTotalTime = appExecuteTotalTime
-	c.JSON(http.StatusOK, gin.H{
-		constant.RESPONSE: res,
-	})
+	return nil
 }
 
-// HandleResponseBodyRead reads the body of an HTTP response.
-func HandleResponseBodyRead(resp *http.Response, c *gin.Context) ([]byte, bool) {
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		log.Println("Error occurred while reading response body", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			constant.RESPONSE: response.ErrorResponseMsg("Error occurred while reading body", err.Error(), http.StatusBadRequest),
-		})
-		return nil, true
+// BuildWebContent collects the page body and the response metadata shared by the analyzers.
+func BuildWebContent(body []byte, resp *http.Response) *response.WebContent {
+	wc := &response.WebContent{
+		Content:   string(body),
+		Headers:   resp.Header,
+		TLS:       resp.TLS,
+		Redirects: collectRedirects(resp),
 	}
-	return body, false
+	if resp.Request != nil {
+		wc.FinalUrl = resp.Request.URL.String()
+	}
+	return wc
 }
 
-// CallWebUrl makes an HTTP GET request to the given link.
-func CallWebUrl(link string, c *gin.Context) (*http.Response, bool) {
-	resp, err := configs.GetConfig().Client.Get(link)
-	if err != nil {
-		log.Println("Error occurred while call web page url", err)
-		c.JSON(http.StatusBadRequest, gin.H{
-			constant.RESPONSE: response.ErrorResponseMsg("Error occurred while call web page url", err.Error(), http.StatusBadRequest),
-		})
-		return nil, true
+// collectRedirects walks back through the redirect responses that led to the final response.
+func collectRedirects(resp *http.Response) []response.Redirect {
+	var redirects []response.Redirect
+	for req := resp.Request; req != nil && req.Response != nil; req = req.Response.Request {
+		prev := req.Response
+		redirect := response.Redirect{
+			Status:   prev.StatusCode,
+			Location: prev.Header.Get(constant.HEADER_LOCATION),
+			Headers:  prev.Header,
+		}
+		if prev.Request != nil {
+			redirect.Url = prev.Request.URL.String()
+		}
+		redirects = append([]response.Redirect{redirect}, redirects...)
 	}
-	return resp, false
+	return redirects
 }
 
-// ValidateWebUrl checks if the provided URL string is valid and prepares a SuccessResponse.
-func ValidateWebUrl(link string, c *gin.Context) (*response.SuccessResponse, bool) {
-	res := &response.SuccessResponse{
-		ExecutedUrl: link,
+// CheckRobotsTxt refuses to request the link when robots.txt is honored and disallows it.
+func CheckRobotsTxt(link string, c *gin.Context) bool {
+	allowed, rule := analyze.CrawlAllowed(link)
+	if allowed {
+		return false
 	}
-	parsedURL, _ := url.Parse(link)
-	baseUrl := fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host)
-	res.BasePath = baseUrl
+	log.Println("Web page url is disallowed by robots.txt", link, rule)
+	c.JSON(http.StatusForbidden, gin.H{
+		constant.RESPONSE: response.ErrorResponseMsg("URL is disallowed by robots.txt", rule, http.StatusForbidden),
+	})
+	return true
+}
 
+// ValidateWebUrl reports whether the URL is missing, in which case the request is answered with 400.
+func ValidateWebUrl(link string, c *gin.Context) bool {
 	if link == constant.EMPTY {
 		log.Println("No URL is exist")
 		c.JSON(http.StatusBadRequest, gin.H{
 			constant.RESPONSE: response.ErrorResponseMsg("URL is not exist", nil, http.StatusBadRequest),
 		})
-		return nil, true
+		return true
 	}
-	return res, false
+	return false
 }
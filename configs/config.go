@@ -17,6 +17,39 @@ type AppConfig struct {
 	ApiVersion string
 	Timeout    time.Duration
 	Client     *http.Client
+	LinkProbe  bool
+	// ImageSizeBudget is the largest acceptable image transfer size in bytes
+	ImageSizeBudget int64
+	// ImageEagerLimit is the number of leading images expected above the fold, later images should lazy load
+	ImageEagerLimit int
+	// TechSignatures is the path of a signature file whose technologies are added to the bundled ones
+	TechSignatures string
+	// TrackerList is the path of a tracker list whose entries are added to the bundled ones
+	TrackerList string
+	// ContactEmails enables the extraction of email addresses by the contact analyzer
+	ContactEmails bool
+	// UserAgent is sent with every request and matched against the robots.txt groups
+	UserAgent string
+	// HonorRobots stops the analyzed page and the probed links disallowed by robots.txt from being requested
+	HonorRobots bool
+	// RobotsRetry is how long a robots.txt file that could not be fetched is reused before it is requested again
+	RobotsRetry time.Duration
+	// CrawlMaxPages is the most pages a site crawl may request
+	CrawlMaxPages int
+	// CrawlDelay is the least time between two requests of a crawl to the crawled host, page requests and probes alike
+	CrawlDelay time.Duration
+	// ReportStorePath is the directory analysis reports are saved in, report history is disabled when it is empty
+	ReportStorePath string
+	// ReportRetention is how long saved reports are kept, zero keeps them until the count limit removes them
+	ReportRetention time.Duration
+	// ReportMaxCount is the most reports kept, the oldest are deleted first
+	ReportMaxCount int
+	// MonitorStatePath is the file monitors and their runs are kept in, they are kept in memory only when it is empty
+	MonitorStatePath string
+	// MonitorRunHistory is the number of runs kept per monitor
+	MonitorRunHistory int
+	// MonitorCertExpiryDays is the default number of days before certificate expiry a monitor alerts
+	MonitorCertExpiryDays int
 }
 
 var (
@@ -43,12 +76,27 @@ func loadConfig() *AppConfig {
 		viper.SetConfigFile(constant.ENV_FILE_PATH)
 	}
 
+	viper.SetDefault(constant.LINK_PROBE, true)
+	viper.SetDefault(constant.IMAGE_BUDGET, 200)
+	viper.SetDefault(constant.IMAGE_EAGER, 3)
+	viper.SetDefault(constant.CONTACT_EMAIL, true)
+	viper.SetDefault(constant.USER_AGENT, "WebPageAnalyzer/1.0")
+	viper.SetDefault(constant.ROBOTS_HONOR, true)
+	viper.SetDefault(constant.ROBOTS_RETRY, 300)
+	viper.SetDefault(constant.CRAWL_PAGES, 50)
+	viper.SetDefault(constant.CRAWL_DELAY, 500)
+	viper.SetDefault(constant.REPORT_DAYS, 30)
+	viper.SetDefault(constant.REPORT_COUNT, 1000)
+	viper.SetDefault(constant.MONITOR_RUNS, 20)
+	viper.SetDefault(constant.MONITOR_CERT, 14)
+
 	err := viper.ReadInConfig()
 	if err != nil {
 		log.Fatal("Error while reading .env file ", err)
 	}
 
 	timeout := viper.GetDuration(constant.TIMEOUT_IN_MS) * time.Second
+	userAgent := viper.GetString(constant.USER_AGENT)
 
 	return &AppConfig{
 		ServerPort: viper.GetString(constant.PORT),
@@ -56,7 +104,42 @@ func loadConfig() *AppConfig {
 		ApiVersion: viper.GetString(constant.API_VERSION),
 		Timeout:    timeout,
 		Client: &http.Client{
-			Timeout: timeout,
+			Timeout:   timeout,
+			Transport: &userAgentTransport{agent: userAgent, base: http.DefaultTransport},
 		},
+		LinkProbe:             viper.GetBool(constant.LINK_PROBE),
+		ImageSizeBudget:       viper.GetInt64(constant.IMAGE_BUDGET) * 1024,
+		ImageEagerLimit:       viper.GetInt(constant.IMAGE_EAGER),
+		TechSignatures:        viper.GetString(constant.TECH_RULES),
+		TrackerList:           viper.GetString(constant.TRACKER_LIST),
+		ContactEmails:         viper.GetBool(constant.CONTACT_EMAIL),
+		UserAgent:             userAgent,
+		HonorRobots:           viper.GetBool(constant.ROBOTS_HONOR),
+		RobotsRetry:           viper.GetDuration(constant.ROBOTS_RETRY) * time.Second,
+		CrawlMaxPages:         viper.GetInt(constant.CRAWL_PAGES),
+		CrawlDelay:            viper.GetDuration(constant.CRAWL_DELAY) * time.Millisecond,
+		ReportStorePath:       viper.GetString(constant.REPORT_STORE),
+		ReportRetention:       viper.GetDuration(constant.REPORT_DAYS) * 24 * time.Hour,
+		ReportMaxCount:        viper.GetInt(constant.REPORT_COUNT),
+		MonitorStatePath:      viper.GetString(constant.MONITOR_STATE),
+		MonitorRunHistory:     viper.GetInt(constant.MONITOR_RUNS),
+		MonitorCertExpiryDays: viper.GetInt(constant.MONITOR_CERT),
+	}
+}
+
+// userAgentTransport sets the configured User-Agent on requests that do not set their own.
+type userAgentTransport struct {
+	agent string
+	base  http.RoundTripper
+}
+
+// RoundTrip sends the request with the User-Agent header added.
+func (t *userAgentTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.agent == constant.EMPTY || req.Header.Get("User-Agent") != constant.EMPTY {
+		return t.base.RoundTrip(req)
 	}
+	// a RoundTripper must not modify the request it was given
+	clone := req.Clone(req.Context())
+	clone.Header.Set("User-Agent", t.agent)
+	return t.base.RoundTrip(clone)
 }
@@ -8,22 +8,151 @@ const (
 	BASE_PATH     = "BASE_PATH"
 	API_VERSION   = "API_VERSION"
 	TIMEOUT_IN_MS = "TIME_OUT_HTTP_MS"
+	LINK_PROBE    = "LINK_PROBE_ENABLED"
+	IMAGE_BUDGET  = "IMAGE_SIZE_BUDGET_KB"
+	IMAGE_EAGER   = "IMAGE_EAGER_LIMIT"
+	TECH_RULES    = "TECH_SIGNATURES_PATH"
+	TRACKER_LIST  = "TRACKER_LIST_PATH"
+	CONTACT_EMAIL = "CONTACT_EMAILS_ENABLED"
+	USER_AGENT    = "USER_AGENT"
+	ROBOTS_HONOR  = "ROBOTS_TXT_HONOR"
+	ROBOTS_RETRY  = "ROBOTS_TXT_RETRY_SECONDS"
+	CRAWL_PAGES   = "CRAWL_MAX_PAGES"
+	CRAWL_DELAY   = "CRAWL_DELAY_MS"
+	REPORT_STORE  = "REPORT_STORE_PATH"
+	REPORT_DAYS   = "REPORT_RETENTION_DAYS"
+	REPORT_COUNT  = "REPORT_MAX_COUNT"
+	MONITOR_STATE = "MONITOR_STATE_PATH"
+	MONITOR_RUNS  = "MONITOR_RUN_HISTORY"
+	MONITOR_CERT  = "MONITOR_CERT_EXPIRY_DAYS"
 )
 
 // program const
 const (
-	TAG_TITLE                      = "title"
-	FORM_TAG_EXP                   = "//form"
-	LOGIN_INPUT_VALIDATION         = "//input[@type='text' or @type='email']"
-	LOGIN_PASSWORD_VALIDATION      = "//input[@type='password']"
-	LOGIN_SUBMIT_BUTTON_VALIDATION = "//button[@type='submit'] | //input[@type='submit']"
-	EMPTY                          = ""
-	H_REF                          = "href"
-	SRC                            = "src"
-	HASH_CODE                      = "#"
-	INTERNAL                       = "INTERNAL"
-	EXTERNAL                       = "EXTERNAL"
-	URL                            = "url"
-	RESPONSE                       = "response"
-	TEST_ENV                       = "TEST_ENV"
+	TAG_TITLE       = "title"
+	FORM_TAG_EXP    = "//form"
+	INPUT_TAG_EXP   = "//input"
+	EMPTY           = ""
+	H_REF           = "href"
+	SRC             = "src"
+	HASH_CODE       = "#"
+	INTERNAL        = "INTERNAL"
+	EXTERNAL        = "EXTERNAL"
+	URL             = "url"
+	RESPONSE        = "response"
+	TEST_ENV        = "TEST_ENV"
+	HTTP_SCHEME     = "http"
+	HTTPS_SCHEME    = "https"
+	HEADER_LOCATION = "Location"
+)
+
+// crawl, compare, report history and monitor query parameters
+const (
+	MAX_DEPTH = "maxDepth"
+	MAX_PAGES = "maxPages"
+	INCLUDE   = "include"
+	EXCLUDE   = "exclude"
+	SITEMAP   = "sitemap"
+	BASE      = "base"
+	TARGET    = "target"
+	BASE_ID   = "baseId"
+	TARGET_ID = "targetId"
+	HOST      = "host"
+	FROM      = "from"
+	TO        = "to"
+	LIMIT     = "limit"
+	ID        = "id"
+)
+
+// finding severities
+const (
+	SEVERITY_INFO    = "INFO"
+	SEVERITY_WARNING = "WARNING"
+	SEVERITY_ERROR   = "ERROR"
+)
+
+// auth form types
+const (
+	AUTH_LOGIN          = "LOGIN"
+	AUTH_LOGIN_STEP     = "LOGIN_STEP"
+	AUTH_SIGNUP         = "SIGNUP"
+	AUTH_PASSWORD_RESET = "PASSWORD_RESET"
+)
+
+// canonical url status
+const (
+	CANONICAL_MISSING      = "MISSING"
+	CANONICAL_SELF         = "SELF"
+	CANONICAL_SAME_DOMAIN  = "SAME_DOMAIN"
+	CANONICAL_CROSS_DOMAIN = "CROSS_DOMAIN"
+	CANONICAL_CONFLICTING  = "CONFLICTING"
+)
+
+// structured data sources
+const (
+	SOURCE_JSON_LD   = "JSON-LD"
+	SOURCE_MICRODATA = "MICRODATA"
+	SOURCE_RDFA      = "RDFA"
+)
+
+// image sources
+const (
+	IMAGE_SOURCE_IMG        = "IMG"
+	IMAGE_SOURCE_SRCSET     = "SRCSET"
+	IMAGE_SOURCE_PICTURE    = "PICTURE_SOURCE"
+	IMAGE_SOURCE_BACKGROUND = "CSS_BACKGROUND"
+)
+
+// privacy ratings
+const (
+	PRIVACY_CLEAN   = "CLEAN"
+	PRIVACY_GATED   = "GATED"
+	PRIVACY_EXPOSED = "EXPOSED"
+)
+
+// document rendering modes
+const (
+	MODE_QUIRKS         = "QUIRKS"
+	MODE_LIMITED_QUIRKS = "LIMITED_QUIRKS"
+	MODE_NO_QUIRKS      = "NO_QUIRKS"
+)
+
+// where a contact detail was found
+const (
+	CONTACT_SOURCE_MAILTO  = "MAILTO"
+	CONTACT_SOURCE_TEL     = "TEL"
+	CONTACT_SOURCE_TEXT    = "TEXT"
+	CONTACT_SOURCE_ADDRESS = "ADDRESS_ELEMENT"
+	CONTACT_SOURCE_LINK    = "LINK"
+)
+
+// syndication feed formats
+const (
+	FEED_RSS  = "RSS"
+	FEED_RDF  = "RDF"
+	FEED_ATOM = "ATOM"
+	FEED_JSON = "JSON_FEED"
+)
+
+// sitemap document types
+const (
+	SITEMAP_URLSET = "URLSET"
+	SITEMAP_INDEX  = "SITEMAP_INDEX"
+)
+
+// report comparison change kinds
+const (
+	CHANGE_ADDED   = "ADDED"
+	CHANGE_REMOVED = "REMOVED"
+	CHANGE_CHANGED = "CHANGED"
+)
+
+// monitor alert rules
+const (
+	ALERT_UNREACHABLE   = "page-unreachable"
+	ALERT_RECOVERED     = "page-recovered"
+	ALERT_BROKEN_LINKS  = "new-broken-links"
+	ALERT_TITLE_CHANGED = "title-changed"
+	ALERT_LOGIN_LOST    = "login-form-disappeared"
+	ALERT_CERT_EXPIRING = "cert-expiring"
 )
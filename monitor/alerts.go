@@ -0,0 +1,120 @@
+package monitor
+
+import (
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+const (
+	// maxAlertLinks is the number of new broken links named in an alert message.
+	maxAlertLinks = 5
+	// deliveryLog and deliveryWebhook name where the alerts of a run were sent.
+	deliveryLog     = "log"
+	deliveryWebhook = "webhook"
+)
+
+// EvaluateRun returns the alerts of a run. State changes are alerted once: the page becoming unreachable is compared
+// with the previous run, and content changes with baseline, the last run that reached the page. A certificate near
+// expiry is alerted when it crosses certExpiryDays.
+func EvaluateRun(run, previous, baseline *response.MonitorRun, certExpiryDays int) []response.Finding {
+	alerts := []response.Finding{}
+	if !run.Reachable {
+		if previous == nil || previous.Reachable {
+			alerts = append(alerts, newAlert(constant.ALERT_UNREACHABLE, constant.SEVERITY_ERROR,
+				"Page is unreachable: "+run.Error))
+		}
+		return alerts
+	}
+	if previous != nil && !previous.Reachable {
+		alerts = append(alerts, newAlert(constant.ALERT_RECOVERED, constant.SEVERITY_INFO, "Page is reachable again"))
+	}
+
+	if baseline != nil {
+		var newBroken []string
+		for _, link := range run.BrokenLinks {
+			if !slices.Contains(baseline.BrokenLinks, link) {
+				newBroken = append(newBroken, link)
+			}
+		}
+		if len(newBroken) > 0 {
+			named := newBroken[:min(len(newBroken), maxAlertLinks)]
+			message := fmt.Sprintf("%d new broken links: %s", len(newBroken), strings.Join(named, ", "))
+			if len(newBroken) == 1 {
+				message = "1 new broken link: " + newBroken[0]
+			}
+			alerts = append(alerts, newAlert(constant.ALERT_BROKEN_LINKS, constant.SEVERITY_WARNING, message))
+		}
+		if run.Title != baseline.Title {
+			alerts = append(alerts, newAlert(constant.ALERT_TITLE_CHANGED, constant.SEVERITY_WARNING,
+				fmt.Sprintf("Title changed from %q to %q", baseline.Title, run.Title)))
+		}
+		if baseline.HasLogin && !run.HasLogin {
+			alerts = append(alerts, newAlert(constant.ALERT_LOGIN_LOST, constant.SEVERITY_ERROR, "Login form disappeared"))
+		}
+	}
+
+	if run.CertDaysLeft != nil && *run.CertDaysLeft <= certExpiryDays {
+		alerted := baseline != nil && baseline.CertDaysLeft != nil && *baseline.CertDaysLeft <= certExpiryDays
+		if !alerted {
+			message := fmt.Sprintf("Certificate expires in %d days", *run.CertDaysLeft)
+			if *run.CertDaysLeft < 0 {
+				message = "Certificate has expired"
+			}
+			alerts = append(alerts, newAlert(constant.ALERT_CERT_EXPIRING, constant.SEVERITY_WARNING, message))
+		}
+	}
+	return alerts
+}
+
+// deliverAlerts posts the alert to the webhook, or writes it to the log when the monitor has none or the webhook
+// fails. It returns where the alert went and the webhook error.
+func deliverAlerts(webhook string, alert response.MonitorAlert) (string, string) {
+	if webhook == constant.EMPTY {
+		logAlerts(alert)
+		return deliveryLog, constant.EMPTY
+	}
+	err := postWebhook(webhook, alert)
+	if err == nil {
+		return deliveryWebhook, constant.EMPTY
+	}
+	log.Printf("Failed delivering monitor alert: %s | Error: %v", webhook, err)
+	logAlerts(alert)
+	return deliveryLog, err.Error()
+}
+
+// postWebhook sends the alert as JSON and expects a 2xx answer.
+func postWebhook(webhook string, alert response.MonitorAlert) error {
+	payload, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	resp, err := configs.GetConfig().Client.Post(webhook, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// logAlerts writes each alert to the log sink.
+func logAlerts(alert response.MonitorAlert) {
+	for _, finding := range alert.Alerts {
+		log.Printf("Monitor alert %s %s [%s] %s: %s", alert.MonitorId, alert.Url, finding.Severity, finding.Rule, finding.Message)
+	}
+}
+
+// newAlert creates an alert of a monitor run.
+func newAlert(rule, severity, message string) response.Finding {
+	return response.Finding{Rule: rule, Severity: severity, Message: message}
+}
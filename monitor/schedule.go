@@ -0,0 +1,167 @@
+package monitor
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minInterval is the shortest time between two runs of a monitor.
+	minInterval = time.Minute
+	// maxScheduleSearch bounds how far ahead the next time of a cron expression is searched.
+	maxScheduleSearch = 5 * 366 * 24 * time.Hour
+)
+
+// Schedule tells when a monitor runs next.
+type Schedule interface {
+	// Next returns the first run time after the given time, or the zero time when there is none.
+	Next(after time.Time) time.Time
+}
+
+// scheduleMacros are the cron shorthands accepted besides @every.
+var scheduleMacros = map[string]string{
+	"@hourly":  "0 * * * *",
+	"@daily":   "0 0 * * *",
+	"@weekly":  "0 0 * * 0",
+	"@monthly": "0 0 1 * *",
+}
+
+// ParseSchedule parses "@every <duration>", one of @hourly, @daily, @weekly and @monthly, or a five field cron
+// expression of minute, hour, day of month, month and day of week. Cron fields take *, numbers, ranges, lists and
+// steps, such as "*/15 8-18 * * 1-5".
+func ParseSchedule(spec string) (Schedule, error) {
+	spec = strings.TrimSpace(spec)
+	if interval, ok := strings.CutPrefix(spec, "@every "); ok {
+		duration, err := time.ParseDuration(strings.TrimSpace(interval))
+		if err != nil {
+			return nil, fmt.Errorf("invalid interval %q", interval)
+		}
+		if duration < minInterval {
+			return nil, fmt.Errorf("interval must be at least %s", minInterval)
+		}
+		return everySchedule{interval: duration}, nil
+	}
+	if expression, ok := scheduleMacros[spec]; ok {
+		spec = expression
+	}
+
+	fields := strings.Fields(spec)
+	if len(fields) != 5 {
+		return nil, fmt.Errorf("expected 5 cron fields, got %d", len(fields))
+	}
+	bounds := [5][2]int{{0, 59}, {0, 23}, {1, 31}, {1, 12}, {0, 7}}
+	var sets [5]uint64
+	for i, field := range fields {
+		set, err := parseCronField(field, bounds[i][0], bounds[i][1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid cron field %q: %v", field, err)
+		}
+		sets[i] = set
+	}
+	// Sunday is 0 or 7
+	if sets[4]&(1<<7) != 0 {
+		sets[4] |= 1
+	}
+	return cronSchedule{
+		minutes:    sets[0],
+		hours:      sets[1],
+		days:       sets[2],
+		months:     sets[3],
+		weekdays:   sets[4],
+		anyDay:     fields[2] == "*",
+		anyWeekday: fields[4] == "*",
+	}, nil
+}
+
+// parseCronField parses a comma separated list of *, n, n-m, with an optional /step, into a bit set.
+func parseCronField(field string, low, high int) (uint64, error) {
+	var set uint64
+	for _, part := range strings.Split(field, ",") {
+		valueRange, stepText, hasStep := strings.Cut(part, "/")
+		step := 1
+		if hasStep {
+			var err error
+			if step, err = strconv.Atoi(stepText); err != nil || step < 1 {
+				return 0, fmt.Errorf("invalid step %q", stepText)
+			}
+		}
+
+		start, end := low, high
+		if valueRange != "*" {
+			startText, endText, isRange := strings.Cut(valueRange, "-")
+			var err error
+			if start, err = strconv.Atoi(startText); err != nil {
+				return 0, fmt.Errorf("invalid value %q", startText)
+			}
+			end = start
+			if isRange {
+				if end, err = strconv.Atoi(endText); err != nil {
+					return 0, fmt.Errorf("invalid value %q", endText)
+				}
+			} else if hasStep {
+				end = high
+			}
+		}
+		if start < low || end > high || start > end {
+			return 0, fmt.Errorf("values must be from %d to %d", low, high)
+		}
+		for value := start; value <= end; value += step {
+			set |= 1 << value
+		}
+	}
+	return set, nil
+}
+
+// everySchedule runs at a fixed interval.
+type everySchedule struct {
+	interval time.Duration
+}
+
+// Next returns the time one interval after the given time.
+func (s everySchedule) Next(after time.Time) time.Time {
+	return after.Add(s.interval)
+}
+
+// cronSchedule runs at the minutes matching a cron expression, in the location of the given time.
+type cronSchedule struct {
+	minutes, hours, days, months, weekdays uint64
+	anyDay, anyWeekday                     bool
+}
+
+// Next returns the first minute after the given time that matches the expression.
+func (s cronSchedule) Next(after time.Time) time.Time {
+	next := after.Truncate(time.Minute).Add(time.Minute)
+	limit := after.Add(maxScheduleSearch)
+	for next.Before(limit) {
+		switch {
+		case s.months&(1<<uint(next.Month())) == 0:
+			next = time.Date(next.Year(), next.Month()+1, 1, 0, 0, 0, 0, next.Location())
+		case !s.dayMatches(next):
+			next = time.Date(next.Year(), next.Month(), next.Day()+1, 0, 0, 0, 0, next.Location())
+		case s.hours&(1<<uint(next.Hour())) == 0:
+			next = time.Date(next.Year(), next.Month(), next.Day(), next.Hour()+1, 0, 0, 0, next.Location())
+		case s.minutes&(1<<uint(next.Minute())) == 0:
+			next = next.Add(time.Minute)
+		default:
+			return next
+		}
+	}
+	return time.Time{}
+}
+
+// dayMatches applies the cron rule that a day matches either restricted day field when both are restricted.
+func (s cronSchedule) dayMatches(t time.Time) bool {
+	day := s.days&(1<<uint(t.Day())) != 0
+	weekday := s.weekdays&(1<<uint(t.Weekday())) != 0
+	switch {
+	case s.anyDay && s.anyWeekday:
+		return true
+	case s.anyDay:
+		return weekday
+	case s.anyWeekday:
+		return day
+	}
+	return day || weekday
+}
@@ -0,0 +1,450 @@
+package monitor
+
+import (
+	"api/constant"
+	"api/response"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// tickInterval is how often the scheduler looks for monitors that are due.
+	tickInterval = time.Second
+	// maxConcurrentRuns bounds the monitors analyzed at the same time.
+	maxConcurrentRuns = 4
+)
+
+var (
+	// ErrMonitorNotFound is returned for an id no monitor has.
+	ErrMonitorNotFound = errors.New("monitor not found")
+	// ErrMonitorBusy is returned when a run is requested while the monitor is running.
+	ErrMonitorBusy = errors.New("monitor is running")
+	// ErrInvalidMonitor wraps the validation errors of a monitor definition.
+	ErrInvalidMonitor = errors.New("invalid monitor")
+)
+
+// AnalyzeFunc analyzes a page the way the analyze endpoint does.
+type AnalyzeFunc func(link string) (*response.SuccessResponse, *response.ErrorResponse)
+
+// MonitorInput is the definition of a monitor given to the API. A zero CertExpiryDays takes the configured default.
+type MonitorInput struct {
+	Url            string `json:"url"`
+	Schedule       string `json:"schedule"`
+	Webhook        string `json:"webhook"`
+	CertExpiryDays int    `json:"certExpiryDays"`
+	Paused         bool   `json:"paused"`
+}
+
+// Scheduler runs the monitors on their schedules and keeps the monitors with their last runs in a state file.
+type Scheduler struct {
+	analyze        AnalyzeFunc
+	statePath      string
+	runHistory     int
+	certExpiryDays int
+
+	mu        sync.Mutex
+	monitors  map[string]*response.Monitor
+	schedules map[string]Schedule
+	running   map[string]bool
+	limit     chan struct{}
+	stop      chan struct{}
+}
+
+// NewScheduler creates a scheduler and loads the monitors of the state file, when it is set and exists. Monitors
+// keep runHistory runs, and alert certExpiryDays before certificate expiry unless they set their own limit.
+func NewScheduler(statePath string, runHistory, certExpiryDays int, analyze AnalyzeFunc) (*Scheduler, error) {
+	scheduler := &Scheduler{
+		analyze:        analyze,
+		statePath:      statePath,
+		runHistory:     max(runHistory, 1),
+		certExpiryDays: certExpiryDays,
+		monitors:       make(map[string]*response.Monitor),
+		schedules:      make(map[string]Schedule),
+		running:        make(map[string]bool),
+		limit:          make(chan struct{}, maxConcurrentRuns),
+	}
+	if statePath == constant.EMPTY {
+		return scheduler, nil
+	}
+
+	data, err := os.ReadFile(statePath)
+	if errors.Is(err, os.ErrNotExist) {
+		return scheduler, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var monitors []*response.Monitor
+	if err := json.Unmarshal(data, &monitors); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, monitor := range monitors {
+		schedule, err := ParseSchedule(monitor.Schedule)
+		if err != nil {
+			log.Printf("Skipping monitor with invalid schedule: %s | Error: %v", monitor.Id, err)
+			continue
+		}
+		scheduler.monitors[monitor.Id] = monitor
+		scheduler.schedules[monitor.Id] = schedule
+		if !monitor.Paused && monitor.NextRun == nil {
+			scheduler.setNextRun(monitor, now)
+		}
+	}
+	return scheduler, nil
+}
+
+// Start runs the due monitors in the background until Stop is called.
+func (s *Scheduler) Start() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		return
+	}
+	s.stop = make(chan struct{})
+	go s.loop(s.stop)
+	log.Printf("Monitor scheduler started with %d monitors", len(s.monitors))
+}
+
+// Stop ends the background runs, runs in progress complete.
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stop != nil {
+		close(s.stop)
+		s.stop = nil
+	}
+}
+
+// loop starts the runs of the monitors that are due on every tick.
+func (s *Scheduler) loop(stop chan struct{}) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case now := <-ticker.C:
+			for _, id := range s.dueMonitors(now) {
+				go func() {
+					s.limit <- struct{}{}
+					defer func() { <-s.limit }()
+					if _, err := s.Run(id); err != nil && !errors.Is(err, ErrMonitorBusy) {
+						log.Printf("Monitor run failed: %s | Error: %v", id, err)
+					}
+				}()
+			}
+		}
+	}
+}
+
+// dueMonitors returns the monitors whose next run has come, and moves their next run on.
+func (s *Scheduler) dueMonitors(now time.Time) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var due []string
+	for id, monitor := range s.monitors {
+		if monitor.Paused || monitor.NextRun == nil || monitor.NextRun.After(now) || s.running[id] {
+			continue
+		}
+		s.setNextRun(monitor, now)
+		due = append(due, id)
+	}
+	return due
+}
+
+// List returns the monitors, oldest first, with their last run.
+func (s *Scheduler) List() []response.Monitor {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitors := make([]response.Monitor, 0, len(s.monitors))
+	for _, monitor := range s.monitors {
+		monitors = append(monitors, copyMonitor(monitor, 1))
+	}
+	sort.Slice(monitors, func(i, j int) bool {
+		if !monitors[i].CreatedAt.Equal(monitors[j].CreatedAt) {
+			return monitors[i].CreatedAt.Before(monitors[j].CreatedAt)
+		}
+		return monitors[i].Id < monitors[j].Id
+	})
+	return monitors
+}
+
+// Get returns the monitor with its runs, newest first.
+func (s *Scheduler) Get(id string) (response.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitor, ok := s.monitors[id]
+	if !ok {
+		return response.Monitor{}, ErrMonitorNotFound
+	}
+	return copyMonitor(monitor, len(monitor.Runs)), nil
+}
+
+// Create adds a monitor and schedules its first run.
+func (s *Scheduler) Create(input MonitorInput) (response.Monitor, error) {
+	schedule, err := s.validate(&input)
+	if err != nil {
+		return response.Monitor{}, err
+	}
+	id, err := newMonitorId()
+	if err != nil {
+		return response.Monitor{}, err
+	}
+	monitor := &response.Monitor{
+		Id:        id,
+		CreatedAt: time.Now().UTC(),
+		Runs:      []response.MonitorRun{},
+	}
+	applyInput(monitor, input)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.monitors[id] = monitor
+	s.schedules[id] = schedule
+	s.setNextRun(monitor, time.Now())
+	s.saveState()
+	return copyMonitor(monitor, 0), nil
+}
+
+// Update replaces the definition of a monitor and reschedules it. Its runs are kept.
+func (s *Scheduler) Update(id string, input MonitorInput) (response.Monitor, error) {
+	schedule, err := s.validate(&input)
+	if err != nil {
+		return response.Monitor{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitor, ok := s.monitors[id]
+	if !ok {
+		return response.Monitor{}, ErrMonitorNotFound
+	}
+	applyInput(monitor, input)
+	s.schedules[id] = schedule
+	s.setNextRun(monitor, time.Now())
+	s.saveState()
+	return copyMonitor(monitor, len(monitor.Runs)), nil
+}
+
+// Delete removes a monitor and its runs.
+func (s *Scheduler) Delete(id string) (response.Monitor, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	monitor, ok := s.monitors[id]
+	if !ok {
+		return response.Monitor{}, ErrMonitorNotFound
+	}
+	delete(s.monitors, id)
+	delete(s.schedules, id)
+	s.saveState()
+	return copyMonitor(monitor, 0), nil
+}
+
+// Run analyzes the page of the monitor now, alerts on the degradations found and records the run.
+func (s *Scheduler) Run(id string) (response.MonitorRun, error) {
+	s.mu.Lock()
+	monitor, ok := s.monitors[id]
+	if !ok {
+		s.mu.Unlock()
+		return response.MonitorRun{}, ErrMonitorNotFound
+	}
+	if s.running[id] {
+		s.mu.Unlock()
+		return response.MonitorRun{}, ErrMonitorBusy
+	}
+	s.running[id] = true
+	link, webhook, certExpiryDays := monitor.Url, monitor.Webhook, monitor.CertExpiryDays
+	// copies, the runs may be trimmed while the page is analyzed
+	var previous, baseline *response.MonitorRun
+	if len(monitor.Runs) > 0 {
+		previousRun := monitor.Runs[0]
+		previous = &previousRun
+	}
+	if index := slices.IndexFunc(monitor.Runs, func(run response.MonitorRun) bool { return run.Reachable }); index >= 0 {
+		baselineRun := monitor.Runs[index]
+		baseline = &baselineRun
+	}
+	s.mu.Unlock()
+
+	log.Println("Monitor run started :", id, link)
+	run := s.execute(link)
+	run.Alerts = EvaluateRun(&run, previous, baseline, certExpiryDays)
+	if len(run.Alerts) > 0 {
+		run.AlertDelivery, run.AlertError = deliverAlerts(webhook, response.MonitorAlert{
+			MonitorId: id,
+			Url:       link,
+			RunAt:     run.StartedAt,
+			ReportId:  run.ReportId,
+			Alerts:    run.Alerts,
+		})
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.running, id)
+	// the monitor may have been deleted during the run
+	if monitor, ok = s.monitors[id]; ok {
+		monitor.Runs = append([]response.MonitorRun{run}, monitor.Runs...)
+		if len(monitor.Runs) > s.runHistory {
+			monitor.Runs = monitor.Runs[:s.runHistory]
+		}
+		s.saveState()
+	}
+	return run, nil
+}
+
+// execute analyzes the page and records the facts the alerts are evaluated on.
+func (s *Scheduler) execute(link string) response.MonitorRun {
+	startTime := time.Now()
+	run := response.MonitorRun{StartedAt: startTime.UTC(), BrokenLinks: []string{}}
+	res, errResp := s.analyze(link)
+	run.Duration = time.Since(startTime).Milliseconds()
+
+	if errResp != nil {
+		run.Error = errResp.Message
+		if detail, ok := errResp.ErrorMsg.(string); ok && detail != constant.EMPTY {
+			run.Error += ": " + detail
+		}
+		return run
+	}
+	run.PageStatus = res.PageStatus
+	run.ReportId = res.ReportId
+	if res.PageStatus >= http.StatusBadRequest {
+		run.Error = fmt.Sprintf("page returned status %d", res.PageStatus)
+		return run
+	}
+
+	run.Reachable = true
+	run.Title = res.Title
+	run.HasLogin = res.HasLogin
+	for _, link := range res.Urls {
+		if link.Status >= http.StatusBadRequest && !slices.Contains(run.BrokenLinks, link.Url) {
+			run.BrokenLinks = append(run.BrokenLinks, link.Url)
+		}
+	}
+	if res.Tls != nil && res.Tls.Enabled {
+		daysLeft := res.Tls.DaysRemaining
+		run.CertDaysLeft = &daysLeft
+	}
+	return run
+}
+
+// validate checks a monitor definition, fills in the default certificate expiry limit and parses the schedule.
+func (s *Scheduler) validate(input *MonitorInput) (Schedule, error) {
+	if !isHTTPURL(input.Url) {
+		return nil, fmt.Errorf("%w: url must be an http or https URL", ErrInvalidMonitor)
+	}
+	if input.Webhook != constant.EMPTY && !isHTTPURL(input.Webhook) {
+		return nil, fmt.Errorf("%w: webhook must be an http or https URL", ErrInvalidMonitor)
+	}
+	if input.CertExpiryDays < 0 {
+		return nil, fmt.Errorf("%w: certExpiryDays must not be negative", ErrInvalidMonitor)
+	}
+	if input.CertExpiryDays == 0 {
+		input.CertExpiryDays = s.certExpiryDays
+	}
+	schedule, err := ParseSchedule(input.Schedule)
+	if err != nil {
+		return nil, fmt.Errorf("%w: schedule: %v", ErrInvalidMonitor, err)
+	}
+	return schedule, nil
+}
+
+// setNextRun sets the next run time of a monitor after now, none while it is paused. The caller holds the lock.
+func (s *Scheduler) setNextRun(monitor *response.Monitor, now time.Time) {
+	monitor.NextRun = nil
+	if monitor.Paused {
+		return
+	}
+	if next := s.schedules[monitor.Id].Next(now); !next.IsZero() {
+		monitor.NextRun = &next
+	}
+}
+
+// saveState writes the monitors to the state file through a temporary file. A failure is logged, the monitors stay
+// in memory. The caller holds the lock.
+func (s *Scheduler) saveState() {
+	if s.statePath == constant.EMPTY {
+		return
+	}
+	monitors := make([]*response.Monitor, 0, len(s.monitors))
+	for _, monitor := range s.monitors {
+		monitors = append(monitors, monitor)
+	}
+	sort.Slice(monitors, func(i, j int) bool { return monitors[i].Id < monitors[j].Id })
+
+	err := func() error {
+		data, err := json.MarshalIndent(monitors, constant.EMPTY, "  ")
+		if err != nil {
+			return err
+		}
+		temp, err := os.CreateTemp(filepath.Dir(s.statePath), filepath.Base(s.statePath)+"-*.tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(temp.Name())
+		if _, err := temp.Write(data); err != nil {
+			temp.Close()
+			return err
+		}
+		if err := temp.Close(); err != nil {
+			return err
+		}
+		return os.Rename(temp.Name(), s.statePath)
+	}()
+	if err != nil {
+		log.Printf("Failed saving monitor state: %s | Error: %v", s.statePath, err)
+	}
+}
+
+// applyInput copies a validated definition onto a monitor.
+func applyInput(monitor *response.Monitor, input MonitorInput) {
+	monitor.Url = input.Url
+	monitor.Schedule = input.Schedule
+	monitor.Webhook = input.Webhook
+	monitor.CertExpiryDays = input.CertExpiryDays
+	monitor.Paused = input.Paused
+}
+
+// copyMonitor copies a monitor with at most runs of its runs, so it can be returned outside the lock.
+func copyMonitor(monitor *response.Monitor, runs int) response.Monitor {
+	copied := *monitor
+	copied.Runs = slices.Clone(monitor.Runs[:min(runs, len(monitor.Runs))])
+	if copied.Runs == nil {
+		copied.Runs = []response.MonitorRun{}
+	}
+	if monitor.NextRun != nil {
+		next := *monitor.NextRun
+		copied.NextRun = &next
+	}
+	return copied
+}
+
+// isHTTPURL reports whether link is an absolute http or https URL.
+func isHTTPURL(link string) bool {
+	parsedURL, err := url.Parse(link)
+	return err == nil && parsedURL.Host != constant.EMPTY &&
+		(parsedURL.Scheme == constant.HTTP_SCHEME || parsedURL.Scheme == constant.HTTPS_SCHEME)
+}
+
+// newMonitorId creates a random monitor id.
+func newMonitorId() (string, error) {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return constant.EMPTY, err
+	}
+	return hex.EncodeToString(id), nil
+}
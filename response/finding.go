@@ -0,0 +1,8 @@
+package response
+
+// Finding describes a single issue or observation raised by an analyzer.
+type Finding struct {
+	Rule     string `json:"rule"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
@@ -1,21 +1,60 @@
 package response
 
+import (
+	"net/http"
+	"time"
+)
+
 type SuccessResponse struct {
-	HtmlVersion         string    `json:"htmlVersion"`
-	Title               string    `json:"title"`
-	ServiceTime         int64     `json:"serviceTime"`
-	WebPageExtractTime  int64     `json:"webPageExtractTime"`
-	Headings            []Heading `json:"headings"`
-	Urls                []Url     `json:"urls"`
-	HasLogin            bool      `json:"hasLogin"`
-	ExecutedUrl         string    `json:"executedUrl"`
-	BasePath            string    `json:"basePath"`
-	AppExecuteTotalTime int64     `json:"appExecuteTotalTime"`
+	HtmlVersion         string               `json:"htmlVersion"`
+	Doctype             *DoctypeInfo         `json:"doctype"`
+	Title               string               `json:"title"`
+	PageStatus          int                  `json:"pageStatus"`
+	ServiceTime         int64                `json:"serviceTime"`
+	WebPageExtractTime  int64                `json:"webPageExtractTime"`
+	Headings            []Heading            `json:"headings"`
+	HeadingCounts       map[string]int       `json:"headingCounts"`
+	HeadingOutline      []*HeadingNode       `json:"headingOutline"`
+	HeadingFindings     []Finding            `json:"headingFindings"`
+	Urls                []Url                `json:"urls"`
+	HasLogin            bool                 `json:"hasLogin"`
+	AuthForms           []AuthForm           `json:"authForms"`
+	Forms               []FormInfo           `json:"forms"`
+	Seo                 *SeoMetadata         `json:"seo"`
+	Social              *SocialMetadata      `json:"social"`
+	StructuredData      *StructuredData      `json:"structuredData"`
+	Accessibility       *AccessibilityReport `json:"accessibility"`
+	Images              *ImageReport         `json:"images"`
+	Resources           *ResourceReport      `json:"resources"`
+	Technologies        *TechnologyReport    `json:"technologies"`
+	Privacy             *PrivacyReport       `json:"privacy"`
+	Conformance         *ConformanceReport   `json:"conformance"`
+	Content             *ContentReport       `json:"content"`
+	Contacts            *ContactReport       `json:"contacts"`
+	Discovery           *DiscoveryReport     `json:"discovery"`
+	Robots              *RobotsReport        `json:"robots"`
+	ExecutedUrl         string               `json:"executedUrl"`
+	BasePath            string               `json:"basePath"`
+	Redirects           []Redirect           `json:"redirects"`
+	Tls                 *TlsInfo             `json:"tls"`
+	SecurityHeaders     *SecurityHeaders     `json:"securityHeaders"`
+	Cookies             *CookieReport        `json:"cookies"`
+	MixedContent        *MixedContentReport  `json:"mixedContent"`
+	AppExecuteTotalTime int64                `json:"appExecuteTotalTime"`
+	ReportId            string               `json:"reportId,omitempty"`
 }
 
 type Heading struct {
-	Tag  string `json:"tag"`
-	Text string `json:"text"`
+	Tag   string `json:"tag"`
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+}
+
+type HeadingNode struct {
+	Tag      string         `json:"tag"`
+	Level    int            `json:"level"`
+	Text     string         `json:"text"`
+	Children []*HeadingNode `json:"children"`
 }
 
 type Url struct {
@@ -24,4 +63,701 @@ type Url struct {
 	Type             string `json:"type"`
 	Status           int    `json:"status"`
 	UrlExecutionTime int64  `json:"urlExecutionTime"`
+	Disallowed       bool   `json:"disallowed,omitempty"`
+}
+
+type Redirect struct {
+	Url      string      `json:"url"`
+	Status   int         `json:"status"`
+	Location string      `json:"location"`
+	Headers  http.Header `json:"-"`
+}
+
+type TlsInfo struct {
+	Host          string         `json:"host"`
+	Enabled       bool           `json:"enabled"`
+	Version       string         `json:"version"`
+	CipherSuite   string         `json:"cipherSuite"`
+	Subject       string         `json:"subject"`
+	SANs          []string       `json:"sans"`
+	IssuerChain   []string       `json:"issuerChain"`
+	NotBefore     time.Time      `json:"notBefore"`
+	NotAfter      time.Time      `json:"notAfter"`
+	DaysRemaining int            `json:"daysRemaining"`
+	Expired       bool           `json:"expired"`
+	HostnameMatch bool           `json:"hostnameMatch"`
+	OcspStapled   bool           `json:"ocspStapled"`
+	HttpsRedirect *HttpsRedirect `json:"httpsRedirect"`
+}
+
+type HttpsRedirect struct {
+	RequestedScheme  string `json:"requestedScheme"`
+	UpgradedToHttps  bool   `json:"upgradedToHttps"`
+	HttpProbeUrl     string `json:"httpProbeUrl"`
+	HttpProbeStatus  int    `json:"httpProbeStatus"`
+	RedirectsToHttps bool   `json:"redirectsToHttps"`
+}
+
+type SecurityHeaders struct {
+	Grade    string           `json:"grade"`
+	Score    int              `json:"score"`
+	MaxScore int              `json:"maxScore"`
+	Headers  []HeaderFinding  `json:"headers"`
+	Csp      *ContentSecurity `json:"csp"`
+}
+
+type HeaderFinding struct {
+	Header   string    `json:"header"`
+	Present  bool      `json:"present"`
+	Value    string    `json:"value"`
+	Score    int       `json:"score"`
+	MaxScore int       `json:"maxScore"`
+	Findings []Finding `json:"findings"`
+}
+
+type ContentSecurity struct {
+	Directives      []CspDirective `json:"directives"`
+	UnsafeInline    bool           `json:"unsafeInline"`
+	UnsafeEval      bool           `json:"unsafeEval"`
+	WildcardSources []string       `json:"wildcardSources"`
+	ReportOnly      bool           `json:"reportOnly"`
+}
+
+type CspDirective struct {
+	Name    string   `json:"name"`
+	Sources []string `json:"sources"`
+}
+
+type CookieReport struct {
+	Cookies          []CookieInfo `json:"cookies"`
+	ThirdPartyProbed bool         `json:"thirdPartyProbed"`
+	ThirdPartyCount  int          `json:"thirdPartyCount"`
+	FlaggedCount     int          `json:"flaggedCount"`
+}
+
+type CookieInfo struct {
+	Name       string     `json:"name"`
+	Domain     string     `json:"domain"`
+	Path       string     `json:"path"`
+	Expires    *time.Time `json:"expires"`
+	MaxAge     int        `json:"maxAge"`
+	Session    bool       `json:"session"`
+	Secure     bool       `json:"secure"`
+	HttpOnly   bool       `json:"httpOnly"`
+	SameSite   string     `json:"sameSite"`
+	SourceUrl  string     `json:"sourceUrl"`
+	ThirdParty bool       `json:"thirdParty"`
+	Findings   []Finding  `json:"findings"`
+}
+
+type MixedContentReport struct {
+	HttpsPage    bool           `json:"httpsPage"`
+	ActiveCount  int            `json:"activeCount"`
+	PassiveCount int            `json:"passiveCount"`
+	Active       []MixedContent `json:"active"`
+	Passive      []MixedContent `json:"passive"`
+}
+
+type MixedContent struct {
+	Url       string `json:"url"`
+	Tag       string `json:"tag"`
+	Attribute string `json:"attribute"`
+	Line      int    `json:"line"`
+	Column    int    `json:"column"`
+}
+
+type AuthForm struct {
+	Type           string   `json:"type"`
+	Confidence     int      `json:"confidence"`
+	Action         string   `json:"action"`
+	Method         string   `json:"method"`
+	Fields         []string `json:"fields"`
+	InForm         bool     `json:"inForm"`
+	HasSso         bool     `json:"hasSso"`
+	SsoProviders   []string `json:"ssoProviders"`
+	InsecureSubmit bool     `json:"insecureSubmit"`
+}
+
+type FormInfo struct {
+	Action           string      `json:"action"`
+	Method           string      `json:"method"`
+	Enctype          string      `json:"enctype"`
+	Fields           []FormField `json:"fields"`
+	CsrfToken        string      `json:"csrfToken"`
+	Captchas         []string    `json:"captchas"`
+	FileUploads      []string    `json:"fileUploads"`
+	ThirdPartyAction bool        `json:"thirdPartyAction"`
+	InsecureAction   bool        `json:"insecureAction"`
+	Findings         []Finding   `json:"findings"`
+}
+
+type FormField struct {
+	Name         string `json:"name"`
+	Tag          string `json:"tag"`
+	Type         string `json:"type"`
+	Required     bool   `json:"required"`
+	Autocomplete string `json:"autocomplete"`
+	Label        string `json:"label"`
+}
+
+type SeoMetadata struct {
+	Title                 string         `json:"title"`
+	TitleLength           int            `json:"titleLength"`
+	TitlePixelWidth       int            `json:"titlePixelWidth"`
+	Description           string         `json:"description"`
+	DescriptionLength     int            `json:"descriptionLength"`
+	DescriptionPixelWidth int            `json:"descriptionPixelWidth"`
+	Robots                []string       `json:"robots"`
+	Googlebot             []string       `json:"googlebot"`
+	XRobotsTag            []string       `json:"xRobotsTag"`
+	Indexable             bool           `json:"indexable"`
+	Followable            bool           `json:"followable"`
+	Canonical             string         `json:"canonical"`
+	CanonicalStatus       string         `json:"canonicalStatus"`
+	Hreflang              []HreflangLink `json:"hreflang"`
+	Prev                  string         `json:"prev"`
+	Next                  string         `json:"next"`
+	Viewport              string         `json:"viewport"`
+	Findings              []Finding      `json:"findings"`
+}
+
+type HreflangLink struct {
+	Lang       string `json:"lang"`
+	Url        string `json:"url"`
+	Reciprocal *bool  `json:"reciprocal"`
+}
+
+type SocialMetadata struct {
+	OpenGraph map[string]string `json:"openGraph"`
+	Twitter   map[string]string `json:"twitter"`
+	CardType  string            `json:"cardType"`
+	OEmbed    []OEmbedLink      `json:"oEmbed"`
+	Image     *ImageProbe       `json:"image"`
+	Preview   SharePreview      `json:"preview"`
+	Findings  []Finding         `json:"findings"`
+}
+
+type OEmbedLink struct {
+	Type  string `json:"type"`
+	Url   string `json:"url"`
+	Title string `json:"title"`
+}
+
+type ImageProbe struct {
+	Url           string `json:"url"`
+	Status        int    `json:"status"`
+	ContentType   string `json:"contentType"`
+	ContentLength int64  `json:"contentLength"`
+	Format        string `json:"format"`
+	Width         int    `json:"width"`
+	Height        int    `json:"height"`
+}
+
+type SharePreview struct {
+	Title       string `json:"title"`
+	Description string `json:"description"`
+	Image       string `json:"image"`
+	ImageAlt    string `json:"imageAlt"`
+	ImageWidth  int    `json:"imageWidth"`
+	ImageHeight int    `json:"imageHeight"`
+	SiteName    string `json:"siteName"`
+	Url         string `json:"url"`
+	Domain      string `json:"domain"`
+	CardType    string `json:"cardType"`
+}
+
+type StructuredData struct {
+	Entities     map[string][]StructuredEntity `json:"entities"`
+	EntityCount  int                           `json:"entityCount"`
+	JsonLdErrors []JsonLdError                 `json:"jsonLdErrors"`
+	Findings     []Finding                     `json:"findings"`
+}
+
+type StructuredEntity struct {
+	Type       string         `json:"type"`
+	Source     string         `json:"source"`
+	Properties map[string]any `json:"properties"`
+}
+
+type JsonLdError struct {
+	Script  int    `json:"script"`
+	Offset  int64  `json:"offset"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Message string `json:"message"`
+}
+
+type AccessibilityReport struct {
+	Findings     []AccessibilityFinding `json:"findings"`
+	Counts       map[string]int         `json:"counts"`
+	Criteria     map[string]int         `json:"criteria"`
+	RulesChecked int                    `json:"rulesChecked"`
+}
+
+type AccessibilityFinding struct {
+	Finding
+	Criterion string `json:"criterion"`
+	Selector  string `json:"selector"`
+	Line      int    `json:"line"`
+}
+
+type ImageReport struct {
+	Images     []ImageInfo `json:"images"`
+	Count      int         `json:"count"`
+	TotalBytes int64       `json:"totalBytes"`
+	Findings   []Finding   `json:"findings"`
+}
+
+type ImageInfo struct {
+	Url      string      `json:"url"`
+	Source   string      `json:"source"`
+	Alt      string      `json:"alt,omitempty"`
+	Width    string      `json:"width,omitempty"`
+	Height   string      `json:"height,omitempty"`
+	Loading  string      `json:"loading,omitempty"`
+	Format   string      `json:"format"`
+	Probe    *ImageProbe `json:"probe,omitempty"`
+	Findings []Finding   `json:"findings"`
+}
+
+type ResourceReport struct {
+	Scripts        []ResourceInfo    `json:"scripts"`
+	Stylesheets    []ResourceInfo    `json:"stylesheets"`
+	ThirdParties   []ThirdPartyGroup `json:"thirdParties"`
+	RenderBlocking int               `json:"renderBlocking"`
+	TotalBytes     int64             `json:"totalBytes"`
+	Findings       []Finding         `json:"findings"`
+}
+
+type ResourceInfo struct {
+	Url            string `json:"url,omitempty"`
+	Inline         bool   `json:"inline"`
+	InlineSize     int    `json:"inlineSize,omitempty"`
+	Async          bool   `json:"async"`
+	Defer          bool   `json:"defer"`
+	Module         bool   `json:"module"`
+	Media          string `json:"media,omitempty"`
+	InHead         bool   `json:"inHead"`
+	RenderBlocking bool   `json:"renderBlocking"`
+	Integrity      string `json:"integrity,omitempty"`
+	CrossOrigin    string `json:"crossOrigin,omitempty"`
+	ThirdParty     bool   `json:"thirdParty"`
+	Status         int    `json:"status,omitempty"`
+	TransferSize   int64  `json:"transferSize,omitempty"`
+}
+
+type ThirdPartyGroup struct {
+	Host         string   `json:"host"`
+	Domain       string   `json:"domain"`
+	Scripts      int      `json:"scripts"`
+	Stylesheets  int      `json:"stylesheets"`
+	TransferSize int64    `json:"transferSize"`
+	Urls         []string `json:"urls"`
+}
+
+type TechnologyReport struct {
+	Technologies []Technology        `json:"technologies"`
+	ByCategory   map[string][]string `json:"byCategory"`
+}
+
+type Technology struct {
+	Name       string   `json:"name"`
+	Version    string   `json:"version,omitempty"`
+	Categories []string `json:"categories"`
+	Confidence int      `json:"confidence"`
+	Website    string   `json:"website,omitempty"`
+	Evidence   []string `json:"evidence"`
+}
+
+type PrivacyReport struct {
+	Trackers         []TrackerInfo  `json:"trackers"`
+	ConsentPlatforms []string       `json:"consentPlatforms"`
+	ConsentMode      bool           `json:"consentMode"`
+	Summary          PrivacySummary `json:"summary"`
+	Findings         []Finding      `json:"findings"`
+}
+
+type TrackerInfo struct {
+	Name     string   `json:"name"`
+	Company  string   `json:"company"`
+	Category string   `json:"category"`
+	Hosts    []string `json:"hosts"`
+	Urls     []string `json:"urls"`
+	Tags     []string `json:"tags"`
+	Gated    bool     `json:"gated"`
+}
+
+type PrivacySummary struct {
+	TrackerCount    int            `json:"trackerCount"`
+	UngatedCount    int            `json:"ungatedCount"`
+	ThirdPartyHosts int            `json:"thirdPartyHosts"`
+	Companies       []string       `json:"companies"`
+	Categories      map[string]int `json:"categories"`
+	Rating          string         `json:"rating"`
+}
+
+type DoctypeInfo struct {
+	Present     bool      `json:"present"`
+	Raw         string    `json:"raw,omitempty"`
+	Name        string    `json:"name,omitempty"`
+	PublicId    string    `json:"publicId,omitempty"`
+	SystemId    string    `json:"systemId,omitempty"`
+	Line        int       `json:"line,omitempty"`
+	Version     string    `json:"version"`
+	Mode        string    `json:"mode"`
+	ContentType string    `json:"contentType,omitempty"`
+	ServedAsXml bool      `json:"servedAsXml"`
+	XhtmlXmlns  bool      `json:"xhtmlXmlns"`
+	Findings    []Finding `json:"findings"`
+}
+
+type ConformanceReport struct {
+	Issues    []ConformanceIssue   `json:"issues"`
+	Summary   []ConformanceSummary `json:"summary"`
+	Total     int                  `json:"total"`
+	Truncated bool                 `json:"truncated"`
+}
+
+type ConformanceIssue struct {
+	Finding
+	Tag    string `json:"tag"`
+	Line   int    `json:"line"`
+	Column int    `json:"column"`
+}
+
+type ConformanceSummary struct {
+	Rule      string `json:"rule"`
+	Severity  string `json:"severity"`
+	Count     int    `json:"count"`
+	FirstLine int    `json:"firstLine"`
+}
+
+type ContentReport struct {
+	Excerpt            string       `json:"excerpt"`
+	WordCount          int          `json:"wordCount"`
+	PageWordCount      int          `json:"pageWordCount"`
+	SentenceCount      int          `json:"sentenceCount"`
+	ParagraphCount     int          `json:"paragraphCount"`
+	TextHtmlRatio      float64      `json:"textHtmlRatio"`
+	ReadingTimeMinutes int          `json:"readingTimeMinutes"`
+	Readability        *Readability `json:"readability"`
+	Keywords           []Keyword    `json:"keywords"`
+	Phrases            []Keyword    `json:"phrases"`
+	Language           string       `json:"language"`
+	DeclaredLanguage   string       `json:"declaredLanguage"`
+	LanguageMatch      bool         `json:"languageMatch"`
+	Charset            string       `json:"charset"`
+	Findings           []Finding    `json:"findings"`
+}
+
+type Readability struct {
+	FleschReadingEase  float64 `json:"fleschReadingEase"`
+	FleschKincaidGrade float64 `json:"fleschKincaidGrade"`
+	Level              string  `json:"level"`
+}
+
+type Keyword struct {
+	Term    string  `json:"term"`
+	Count   int     `json:"count"`
+	Density float64 `json:"density"`
+}
+
+type ContactReport struct {
+	Emails         []ContactEmail  `json:"emails"`
+	EmailsDisabled bool            `json:"emailsDisabled"`
+	Phones         []ContactPhone  `json:"phones"`
+	Addresses      []PostalAddress `json:"addresses"`
+	SocialProfiles []SocialProfile `json:"socialProfiles"`
+}
+
+type ContactSource struct {
+	Type    string `json:"type"`
+	Section string `json:"section"`
+}
+
+type ContactEmail struct {
+	Address string          `json:"address"`
+	Sources []ContactSource `json:"sources"`
+}
+
+type ContactPhone struct {
+	Number  string          `json:"number"`
+	Raw     string          `json:"raw"`
+	Sources []ContactSource `json:"sources"`
+}
+
+type PostalAddress struct {
+	Text          string          `json:"text"`
+	StreetAddress string          `json:"streetAddress,omitempty"`
+	Locality      string          `json:"locality,omitempty"`
+	Region        string          `json:"region,omitempty"`
+	PostalCode    string          `json:"postalCode,omitempty"`
+	Country       string          `json:"country,omitempty"`
+	Sources       []ContactSource `json:"sources"`
+}
+
+type SocialProfile struct {
+	Platform string          `json:"platform"`
+	Handle   string          `json:"handle"`
+	Url      string          `json:"url"`
+	Sources  []ContactSource `json:"sources"`
+}
+
+type DiscoveryReport struct {
+	Feeds         []FeedInfo        `json:"feeds"`
+	Manifest      *ManifestInfo     `json:"manifest"`
+	Icons         []IconLink        `json:"icons"`
+	Me            []string          `json:"me"`
+	Search        *OpenSearchLink   `json:"search"`
+	AmpUrl        string            `json:"ampUrl,omitempty"`
+	IsAmp         bool              `json:"isAmp"`
+	ServiceWorker ServiceWorkerHint `json:"serviceWorker"`
+	Pwa           PwaReport         `json:"pwa"`
+	Findings      []Finding         `json:"findings"`
+}
+
+type FeedInfo struct {
+	Url         string     `json:"url"`
+	Title       string     `json:"title,omitempty"`
+	Type        string     `json:"type"`
+	Status      int        `json:"status,omitempty"`
+	Format      string     `json:"format,omitempty"`
+	FeedTitle   string     `json:"feedTitle,omitempty"`
+	Items       int        `json:"items"`
+	LastUpdated *time.Time `json:"lastUpdated,omitempty"`
+	Valid       bool       `json:"valid"`
+	Error       string     `json:"error,omitempty"`
+}
+
+type ManifestInfo struct {
+	Url             string         `json:"url"`
+	Status          int            `json:"status,omitempty"`
+	Valid           bool           `json:"valid"`
+	Error           string         `json:"error,omitempty"`
+	Name            string         `json:"name,omitempty"`
+	ShortName       string         `json:"shortName,omitempty"`
+	StartUrl        string         `json:"startUrl,omitempty"`
+	Scope           string         `json:"scope,omitempty"`
+	Display         string         `json:"display,omitempty"`
+	ThemeColor      string         `json:"themeColor,omitempty"`
+	BackgroundColor string         `json:"backgroundColor,omitempty"`
+	Icons           []ManifestIcon `json:"icons"`
+}
+
+type ManifestIcon struct {
+	Src     string `json:"src"`
+	Sizes   string `json:"sizes,omitempty"`
+	Type    string `json:"type,omitempty"`
+	Purpose string `json:"purpose,omitempty"`
+}
+
+type IconLink struct {
+	Url   string `json:"url"`
+	Rel   string `json:"rel"`
+	Sizes string `json:"sizes,omitempty"`
+	Type  string `json:"type,omitempty"`
+}
+
+type OpenSearchLink struct {
+	Url   string `json:"url"`
+	Title string `json:"title,omitempty"`
+}
+
+type ServiceWorkerHint struct {
+	Registered bool     `json:"registered"`
+	ScriptUrl  string   `json:"scriptUrl,omitempty"`
+	Hints      []string `json:"hints"`
+}
+
+type PwaReport struct {
+	Ready  bool       `json:"ready"`
+	Checks []PwaCheck `json:"checks"`
+}
+
+type PwaCheck struct {
+	Name     string `json:"name"`
+	Passed   bool   `json:"passed"`
+	Required bool   `json:"required"`
+	Detail   string `json:"detail,omitempty"`
+}
+
+type RobotsReport struct {
+	Url           string        `json:"url"`
+	Status        int           `json:"status,omitempty"`
+	Found         bool          `json:"found"`
+	Error         string        `json:"error,omitempty"`
+	UserAgent     string        `json:"userAgent"`
+	Honored       bool          `json:"honored"`
+	Disallowed    bool          `json:"disallowed"`
+	MatchedRule   string        `json:"matchedRule,omitempty"`
+	CrawlDelay    float64       `json:"crawlDelay,omitempty"`
+	Sitemaps      []SitemapInfo `json:"sitemaps"`
+	PageInSitemap bool          `json:"pageInSitemap"`
+	Findings      []Finding     `json:"findings"`
+}
+
+type SitemapInfo struct {
+	Url       string        `json:"url"`
+	Status    int           `json:"status,omitempty"`
+	Type      string        `json:"type,omitempty"`
+	Urls      int           `json:"urls"`
+	Valid     bool          `json:"valid"`
+	Error     string        `json:"error,omitempty"`
+	ListsPage bool          `json:"listsPage"`
+	Sitemaps  []SitemapInfo `json:"sitemaps,omitempty"`
+	Skipped   int           `json:"skipped,omitempty"`
+}
+
+type SiteReport struct {
+	SeedUrl               string           `json:"seedUrl"`
+	MaxDepth              int              `json:"maxDepth"`
+	MaxPages              int              `json:"maxPages"`
+	PageCount             int              `json:"pageCount"`
+	Truncated             bool             `json:"truncated"`
+	Pages                 []CrawledPage    `json:"pages"`
+	DuplicateTitles       []DuplicateGroup `json:"duplicateTitles"`
+	DuplicateDescriptions []DuplicateGroup `json:"duplicateDescriptions"`
+	OrphanPages           []string         `json:"orphanPages"`
+	BrokenLinks           []BrokenLink     `json:"brokenLinks"`
+	RedirectChains        []RedirectChain  `json:"redirectChains"`
+	Disallowed            []string         `json:"disallowed"`
+	Findings              []Finding        `json:"findings"`
+	CrawlTime             int64            `json:"crawlTime"`
+}
+
+type CrawledPage struct {
+	Url         string           `json:"url"`
+	Depth       int              `json:"depth"`
+	Referrer    string           `json:"referrer,omitempty"`
+	Status      int              `json:"status,omitempty"`
+	ContentType string           `json:"contentType,omitempty"`
+	Error       string           `json:"error,omitempty"`
+	Result      *SuccessResponse `json:"result,omitempty"`
+}
+
+type DuplicateGroup struct {
+	Value string   `json:"value"`
+	Urls  []string `json:"urls"`
+}
+
+type BrokenLink struct {
+	Url       string   `json:"url"`
+	Status    int      `json:"status"`
+	Referrers []string `json:"referrers"`
+}
+
+type RedirectChain struct {
+	Url      string   `json:"url"`
+	FinalUrl string   `json:"finalUrl"`
+	Hops     int      `json:"hops"`
+	Chain    []string `json:"chain"`
+}
+
+type ComparisonReport struct {
+	BaseUrl   string        `json:"baseUrl"`
+	TargetUrl string        `json:"targetUrl"`
+	Changes   []ValueChange `json:"changes"`
+	Headings  HeadingDiff   `json:"headings"`
+	Links     LinkDiff      `json:"links"`
+	Sections  []SectionDiff `json:"sections"`
+	Summary   []string      `json:"summary"`
+}
+
+type ValueChange struct {
+	Path   string `json:"path"`
+	Kind   string `json:"kind"`
+	Base   any    `json:"base"`
+	Target any    `json:"target"`
+}
+
+type HeadingDiff struct {
+	Added   []Heading       `json:"added"`
+	Removed []Heading       `json:"removed"`
+	Changed []HeadingChange `json:"changed"`
+}
+
+type HeadingChange struct {
+	Tag    string `json:"tag"`
+	Base   string `json:"base"`
+	Target string `json:"target"`
+}
+
+type LinkDiff struct {
+	Added         []string           `json:"added"`
+	Removed       []string           `json:"removed"`
+	StatusChanged []LinkStatusChange `json:"statusChanged"`
+	NewlyBroken   []string           `json:"newlyBroken"`
+	Fixed         []string           `json:"fixed"`
+}
+
+type LinkStatusChange struct {
+	Url          string `json:"url"`
+	BaseStatus   int    `json:"baseStatus"`
+	TargetStatus int    `json:"targetStatus"`
+}
+
+type SectionDiff struct {
+	Section   string        `json:"section"`
+	Changes   []ValueChange `json:"changes"`
+	Truncated bool          `json:"truncated"`
+}
+
+type ReportSummary struct {
+	Id        string            `json:"id"`
+	Url       string            `json:"url"`
+	Host      string            `json:"host"`
+	CreatedAt time.Time         `json:"createdAt"`
+	Title     string            `json:"title"`
+	Options   map[string]string `json:"options"`
+}
+
+type StoredReport struct {
+	ReportSummary
+	Result *SuccessResponse `json:"result"`
+}
+
+type ReportHistory struct {
+	Count   int             `json:"count"`
+	Reports []ReportSummary `json:"reports"`
+}
+
+type Monitor struct {
+	Id             string       `json:"id"`
+	Url            string       `json:"url"`
+	Schedule       string       `json:"schedule"`
+	Webhook        string       `json:"webhook,omitempty"`
+	CertExpiryDays int          `json:"certExpiryDays"`
+	Paused         bool         `json:"paused"`
+	CreatedAt      time.Time    `json:"createdAt"`
+	NextRun        *time.Time   `json:"nextRun,omitempty"`
+	Runs           []MonitorRun `json:"runs"`
+}
+
+type MonitorRun struct {
+	StartedAt     time.Time `json:"startedAt"`
+	Duration      int64     `json:"duration"`
+	Reachable     bool      `json:"reachable"`
+	Error         string    `json:"error,omitempty"`
+	PageStatus    int       `json:"pageStatus"`
+	ReportId      string    `json:"reportId,omitempty"`
+	Title         string    `json:"title"`
+	HasLogin      bool      `json:"hasLogin"`
+	BrokenLinks   []string  `json:"brokenLinks"`
+	CertDaysLeft  *int      `json:"certDaysLeft,omitempty"`
+	Alerts        []Finding `json:"alerts"`
+	AlertDelivery string    `json:"alertDelivery,omitempty"`
+	AlertError    string    `json:"alertError,omitempty"`
+}
+
+type MonitorList struct {
+	Count    int       `json:"count"`
+	Monitors []Monitor `json:"monitors"`
+}
+
+type MonitorAlert struct {
+	MonitorId string    `json:"monitorId"`
+	Url       string    `json:"url"`
+	RunAt     time.Time `json:"runAt"`
+	ReportId  string    `json:"reportId,omitempty"`
+	Alerts    []Finding `json:"alerts"`
 }
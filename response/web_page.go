@@ -1,5 +1,61 @@
 package response
 
+import (
+	"crypto/tls"
+	"net/http"
+	"strings"
+	"sync"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/charset"
+)
+
+// WebContent holds the fetched page body together with the response metadata analyzers need.
 type WebContent struct {
-	Content string
+	Content   string
+	FinalUrl  string
+	Headers   http.Header
+	TLS       *tls.ConnectionState
+	Redirects []Redirect
+	// Client sends the requests the analyzers make for the links and resources of the page, the configured client
+	// is used when it is nil
+	Client *http.Client
+
+	parseOnce sync.Once
+	text      string
+	document  *html.Node
+	charset   string
+	parseErr  error
+}
+
+// Document returns the page parsed once and shared by the analyzers, which must not modify it. The body is decoded
+// with the charset of the Content-Type header, the byte order mark or the <meta charset> of the page.
+func (wc *WebContent) Document() (*html.Node, error) {
+	wc.parseOnce.Do(func() {
+		var contentType string
+		if wc.Headers != nil {
+			contentType = wc.Headers.Get("Content-Type")
+		}
+		encoding, name, _ := charset.DetermineEncoding([]byte(wc.Content), contentType)
+		wc.charset = name
+		decoded, err := encoding.NewDecoder().String(wc.Content)
+		if err != nil {
+			decoded = wc.Content
+		}
+		wc.text = decoded
+		wc.document, wc.parseErr = html.Parse(strings.NewReader(decoded))
+	})
+	return wc.document, wc.parseErr
+}
+
+// Text returns the page body decoded the way Document decodes it, for analyzers that tokenize the page.
+func (wc *WebContent) Text() string {
+	wc.Document()
+	return wc.text
+}
+
+// Charset returns the name of the encoding the page was decoded with by Document.
+func (wc *WebContent) Charset() string {
+	wc.Document()
+	return wc.charset
 }
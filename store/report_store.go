@@ -0,0 +1,275 @@
+package store
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// reportFileExt is the extension of the report files, one per saved analysis.
+const reportFileExt = ".json"
+
+// ErrReportNotFound is returned for an id no saved report has.
+var ErrReportNotFound = errors.New("report not found")
+
+// reportIdPattern matches the ids created by newReportId.
+var reportIdPattern = regexp.MustCompile(`^[0-9a-f]{24}$`)
+
+// ReportStore saves analysis reports as JSON files in a directory, and keeps an index of them in memory. Reports
+// older than the retention period, and the oldest reports beyond the maximum count, are deleted on every save.
+type ReportStore struct {
+	dir        string
+	retention  time.Duration
+	maxReports int
+	mu         sync.RWMutex
+	index      map[string]response.ReportSummary
+}
+
+// ReportFilter selects saved reports. Empty fields match every report.
+type ReportFilter struct {
+	Url   string
+	Host  string
+	From  time.Time
+	To    time.Time
+	Limit int
+}
+
+var (
+	reportStore *ReportStore
+	storeOnce   sync.Once
+)
+
+// GetReportStore returns the report store of the configured directory, or nil when report history is disabled or
+// the directory cannot be used.
+func GetReportStore() *ReportStore {
+	storeOnce.Do(func() {
+		cfg := configs.GetConfig()
+		if cfg.ReportStorePath == constant.EMPTY {
+			return
+		}
+		var err error
+		if reportStore, err = NewReportStore(cfg.ReportStorePath, cfg.ReportRetention, cfg.ReportMaxCount); err != nil {
+			log.Println("Report history is disabled, the store cannot be opened", err)
+		}
+	})
+	return reportStore
+}
+
+// SetReportStore replaces the report store and returns the previous one. A nil store disables report history.
+func SetReportStore(reports *ReportStore) *ReportStore {
+	previous := GetReportStore()
+	reportStore = reports
+	return previous
+}
+
+// NewReportStore opens the store in dir, creating the directory when needed, and indexes the saved reports. A zero
+// retention or maxReports keeps reports without that limit.
+func NewReportStore(dir string, retention time.Duration, maxReports int) (*ReportStore, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	reports := &ReportStore{
+		dir:        dir,
+		retention:  retention,
+		maxReports: maxReports,
+		index:      make(map[string]response.ReportSummary),
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*"+reportFileExt))
+	if err != nil {
+		return nil, err
+	}
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+		if err != nil {
+			log.Printf("Failed reading saved report: %s | Error: %v", file, err)
+			continue
+		}
+		// the summary fields are read, the result is skipped
+		var summary response.ReportSummary
+		if err := json.Unmarshal(data, &summary); err != nil || !reportIdPattern.MatchString(summary.Id) {
+			log.Printf("Skipping invalid saved report: %s | Error: %v", file, err)
+			continue
+		}
+		reports.index[summary.Id] = summary
+	}
+
+	reports.mu.Lock()
+	defer reports.mu.Unlock()
+	reports.prune(time.Now())
+	return reports, nil
+}
+
+// Save stores the result of an analysis of link and returns the saved report.
+func (s *ReportStore) Save(link string, options map[string]string, result *response.SuccessResponse) (*response.StoredReport, error) {
+	id, err := newReportId()
+	if err != nil {
+		return nil, err
+	}
+	report := &response.StoredReport{
+		ReportSummary: response.ReportSummary{
+			Id:        id,
+			Url:       link,
+			Host:      reportHost(link),
+			CreatedAt: time.Now().UTC(),
+			Title:     result.Title,
+			Options:   options,
+		},
+		Result: result,
+	}
+	data, err := json.Marshal(report)
+	if err != nil {
+		return nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.writeFile(id, data); err != nil {
+		return nil, err
+	}
+	s.index[id] = report.ReportSummary
+	s.prune(report.CreatedAt)
+	return report, nil
+}
+
+// Get reads the saved report with the id.
+func (s *ReportStore) Get(id string) (*response.StoredReport, error) {
+	s.mu.RLock()
+	_, ok := s.index[id]
+	s.mu.RUnlock()
+	if !ok {
+		return nil, ErrReportNotFound
+	}
+
+	data, err := os.ReadFile(s.reportFile(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrReportNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	var report response.StoredReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// List returns the summaries of the reports matching the filter, newest first.
+func (s *ReportStore) List(filter ReportFilter) []response.ReportSummary {
+	link := analyzeURLKey(filter.Url)
+	summaries := []response.ReportSummary{}
+
+	s.mu.RLock()
+	for _, summary := range s.index {
+		switch {
+		case link != constant.EMPTY && analyzeURLKey(summary.Url) != link:
+		case filter.Host != constant.EMPTY && !strings.EqualFold(summary.Host, filter.Host):
+		case !filter.From.IsZero() && summary.CreatedAt.Before(filter.From):
+		case !filter.To.IsZero() && !summary.CreatedAt.Before(filter.To):
+		default:
+			summaries = append(summaries, summary)
+		}
+	}
+	s.mu.RUnlock()
+
+	sortNewestFirst(summaries)
+	if filter.Limit > 0 && len(summaries) > filter.Limit {
+		summaries = summaries[:filter.Limit]
+	}
+	return summaries
+}
+
+// prune deletes the reports past the retention period and the oldest reports beyond the maximum count. The caller
+// holds the write lock.
+func (s *ReportStore) prune(now time.Time) {
+	summaries := make([]response.ReportSummary, 0, len(s.index))
+	for _, summary := range s.index {
+		summaries = append(summaries, summary)
+	}
+	sortNewestFirst(summaries)
+
+	for i, summary := range summaries {
+		expired := s.retention > 0 && now.Sub(summary.CreatedAt) > s.retention
+		if !expired && (s.maxReports <= 0 || i < s.maxReports) {
+			continue
+		}
+		if err := os.Remove(s.reportFile(summary.Id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("Failed deleting saved report: %s | Error: %v", summary.Id, err)
+			continue
+		}
+		delete(s.index, summary.Id)
+	}
+}
+
+// writeFile writes the report file through a temporary file, so a report is never read half written.
+func (s *ReportStore) writeFile(id string, data []byte) error {
+	temp, err := os.CreateTemp(s.dir, id+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	if _, err := temp.Write(data); err != nil {
+		temp.Close()
+		os.Remove(temp.Name())
+		return err
+	}
+	if err := temp.Close(); err != nil {
+		os.Remove(temp.Name())
+		return err
+	}
+	return os.Rename(temp.Name(), s.reportFile(id))
+}
+
+// reportFile is the path of the file of a report.
+func (s *ReportStore) reportFile(id string) string {
+	return filepath.Join(s.dir, id+reportFileExt)
+}
+
+// newReportId creates a random report id.
+func newReportId() (string, error) {
+	id := make([]byte, 12)
+	if _, err := rand.Read(id); err != nil {
+		return constant.EMPTY, err
+	}
+	return hex.EncodeToString(id), nil
+}
+
+// reportHost is the lowercase host of the analyzed URL.
+func reportHost(link string) string {
+	parsedURL, err := url.Parse(link)
+	if err != nil {
+		return constant.EMPTY
+	}
+	return strings.ToLower(parsedURL.Hostname())
+}
+
+// analyzeURLKey normalizes a URL so the reports of a page match however its URL was written.
+func analyzeURLKey(link string) string {
+	if normalized := analyze.NormalizePageURL(link); normalized != constant.EMPTY {
+		return normalized
+	}
+	return strings.TrimSpace(link)
+}
+
+// sortNewestFirst orders summaries by creation time, newest first, and by id for equal times.
+func sortNewestFirst(summaries []response.ReportSummary) {
+	sort.Slice(summaries, func(i, j int) bool {
+		if !summaries[i].CreatedAt.Equal(summaries[j].CreatedAt) {
+			return summaries[i].CreatedAt.After(summaries[j].CreatedAt)
+		}
+		return summaries[i].Id < summaries[j].Id
+	})
+}
@@ -0,0 +1,133 @@
+package test
+
+import (
+	"api/analyze"
+	"api/constant"
+	"api/response"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func findAccessibilityFinding(findings []response.AccessibilityFinding, rule string) *response.AccessibilityFinding {
+	for i := range findings {
+		if findings[i].Rule == rule {
+			return &findings[i]
+		}
+	}
+	return nil
+}
+
+func TestAccessibilityAnalyzer_Analyze_Violations(t *testing.T) {
+	htmlContent := `<!DOCTYPE html>
+<html>
+<head><title>Shop</title></head>
+<body>
+<div id="main">
+  <img src="/logo.png">
+  <img src="/spacer.gif" alt="">
+  <form>
+    <input type="text" name="q">
+    <label for="email">Email</label><input id="email" type="email" name="email">
+    <button type="submit"><svg></svg></button>
+  </form>
+  <a href="/cart"><i class="icon-cart"></i></a>
+  <a href="/empty"></a>
+  <a href="/about">About</a>
+  <span id="main" role="buton" aria-lable="x" tabindex="3">Duplicate</span>
+  <iframe src="https://video.example.com/embed"></iframe>
+  <table><tr><td>1</td></tr></table>
+  <table role="presentation"><tr><td>layout</td></tr></table>
+</div>
+</body>
+</html>`
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewAccessibilityAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	findings := res.Accessibility.Findings
+	for _, rule := range []string{"image-alt", "input-label", "button-name", "link-name", "link-empty", "html-lang",
+		"duplicate-id", "aria-role-invalid", "aria-attribute-invalid", "tabindex-positive", "frame-title", "table-headers"} {
+		assert.NotNil(t, findAccessibilityFinding(findings, rule), rule)
+	}
+	assert.Len(t, findings, 12)
+
+	imageAlt := findAccessibilityFinding(findings, "image-alt")
+	assert.Equal(t, "1.1.1", imageAlt.Criterion)
+	assert.Equal(t, constant.SEVERITY_ERROR, imageAlt.Severity)
+	assert.Equal(t, "html > body > div > img:nth-of-type(1)", imageAlt.Selector)
+	assert.Equal(t, 6, imageAlt.Line)
+
+	inputLabel := findAccessibilityFinding(findings, "input-label")
+	assert.Equal(t, "html > body > div > form > input:nth-of-type(1)", inputLabel.Selector)
+	assert.Equal(t, 9, inputLabel.Line)
+
+	duplicate := findAccessibilityFinding(findings, "duplicate-id")
+	assert.Equal(t, 16, duplicate.Line)
+	assert.Equal(t, "html > body > div > span", duplicate.Selector)
+	assert.Equal(t, 3, res.Accessibility.Counts[constant.SEVERITY_WARNING])
+	assert.Equal(t, 1, res.Accessibility.Criteria["4.1.1"])
+}
+
+func TestAccessibilityAnalyzer_Analyze_AccessiblePage(t *testing.T) {
+	htmlContent := `<html lang="en"><body>
+		<img src="/a.png" alt="Team photo">
+		<span id="search-label">Search</span><input type="search" aria-labelledby="search-label">
+		<label>Name <input type="text" name="name"></label>
+		<button aria-label="Close"><svg></svg></button>
+		<a href="/home"><img src="/home.png" alt="Home"></a>
+		<iframe src="/map" title="Store map"></iframe>
+		<table><tr><th scope="col">Price</th></tr><tr><td>1</td></tr></table>
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewAccessibilityAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Empty(t, res.Accessibility.Findings)
+	assert.Equal(t, len(analyze.AccessibilityRules()), res.Accessibility.RulesChecked)
+}
+
+func TestCheckAccessibility_CustomRule(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<html lang="en"><body><marquee>News</marquee></body></html>`))
+	rule := analyze.AccessibilityRule{
+		Id:        "no-marquee",
+		Criterion: "2.2.2",
+		Severity:  constant.SEVERITY_WARNING,
+		Check: func(ctx *analyze.AccessibilityContext) []analyze.AccessibilityViolation {
+			var violations []analyze.AccessibilityViolation
+			var walk func(*html.Node)
+			walk = func(n *html.Node) {
+				if n.Type == html.ElementNode && n.Data == "marquee" {
+					violations = append(violations, analyze.AccessibilityViolation{Node: n, Message: "Moving content"})
+				}
+				for child := n.FirstChild; child != nil; child = child.NextSibling {
+					walk(child)
+				}
+			}
+			walk(ctx.Doc)
+			return violations
+		},
+	}
+
+	report := analyze.CheckAccessibility(doc, nil, []analyze.AccessibilityRule{rule})
+
+	assert.Len(t, report.Findings, 1)
+	assert.Equal(t, "no-marquee", report.Findings[0].Rule)
+	assert.Equal(t, "html > body > marquee", report.Findings[0].Selector)
+	assert.Equal(t, 1, report.Criteria["2.2.2"])
+}
+
+func TestCssPath_AnchorsAtUniqueId(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<html><body><main id="content"><section id="dup"><p>a</p><p>b</p></section></main><div id="dup"></div></body></html>`))
+	paragraph := doc.FirstChild.LastChild.FirstChild.FirstChild.LastChild
+
+	assert.Equal(t, "main#content > section > p:nth-of-type(2)", analyze.CssPath(paragraph, map[string]int{"content": 1, "dup": 2}))
+}
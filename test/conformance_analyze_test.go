@@ -0,0 +1,155 @@
+package test
+
+import (
+	"api/analyze"
+	"api/constant"
+	"api/response"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findConformanceIssue(issues []response.ConformanceIssue, rule string) *response.ConformanceIssue {
+	for i := range issues {
+		if issues[i].Rule == rule {
+			return &issues[i]
+		}
+	}
+	return nil
+}
+
+func TestConformanceAnalyzer_Analyze_ValidDocument(t *testing.T) {
+	htmlContent := `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Valid</title></head>
+<body>
+<p>First paragraph
+<p>Second <a href="/a"><span>link</span></a>
+<ul><li>One<li>Two</ul>
+<dl><dt>Term<dd>Definition</dl>
+<table><thead><tr><th>Name<th>Value<tbody><tr><td>a<td>1<tr><td>b<td>2</table>
+<select><option>A<option>B</select>
+<svg viewBox="0 0 10 10"><path d="M0 0"/><circle r="4"></circle></svg>
+<br><img src="/a.png" alt="a">
+</body>
+</html>`
+	wc := response.WebContent{Content: htmlContent}
+	res := response.SuccessResponse{}
+	err := analyze.NewConformanceAnalyzer().Analyze(&wc, &res)
+
+	assert.Nil(t, err)
+	assert.NotNil(t, res.Conformance)
+	assert.Empty(t, res.Conformance.Issues)
+	assert.Equal(t, 0, res.Conformance.Total)
+}
+
+func TestConformanceAnalyzer_Analyze_Issues(t *testing.T) {
+	htmlContent := `<!DOCTYPE html>
+<html>
+<body bgcolor="white">
+<div id="main"><span>Unclosed span</div>
+<p><b><i>Misnested</b></i></p>
+<a href="/a">Outer <a href="/b">inner</a></a>
+<form action="/a"><form action="/b"><input name="q"></form></form>
+<span><div>Block in inline</div></span>
+<p class="a" class="b">Duplicate attribute</p>
+<section id="main"><font color="red">Old</font><center>Centered</center></section>
+</p>
+<div/>
+</body>
+</html>`
+	wc := response.WebContent{Content: htmlContent}
+	res := response.SuccessResponse{}
+	analyze.NewConformanceAnalyzer().Analyze(&wc, &res)
+	issues := res.Conformance.Issues
+
+	unclosed := findConformanceIssue(issues, "unclosed-element")
+	assert.NotNil(t, unclosed)
+	assert.Equal(t, "span", unclosed.Tag)
+	assert.Equal(t, 4, unclosed.Line)
+	assert.Equal(t, 16, unclosed.Column)
+	assert.Equal(t, constant.SEVERITY_ERROR, unclosed.Severity)
+
+	misnested := findConformanceIssue(issues, "misnested-element")
+	assert.NotNil(t, misnested)
+	assert.Equal(t, 5, misnested.Line)
+
+	var nesting []string
+	for _, issue := range issues {
+		if issue.Rule == "invalid-nesting" {
+			nesting = append(nesting, issue.Tag)
+		}
+	}
+	assert.Equal(t, []string{"a", "form"}, nesting)
+
+	blockInInline := findConformanceIssue(issues, "block-in-inline")
+	assert.NotNil(t, blockInInline)
+	assert.Equal(t, 8, blockInInline.Line)
+	assert.Equal(t, constant.SEVERITY_WARNING, blockInInline.Severity)
+
+	duplicate := findConformanceIssue(issues, "duplicate-attribute")
+	assert.NotNil(t, duplicate)
+	assert.Contains(t, duplicate.Message, "class")
+
+	duplicateId := findConformanceIssue(issues, "duplicate-id")
+	assert.NotNil(t, duplicateId)
+	assert.Equal(t, 10, duplicateId.Line)
+	assert.Contains(t, duplicateId.Message, "line 4")
+
+	var obsolete []string
+	for _, issue := range issues {
+		if issue.Rule == "obsolete-element" || issue.Rule == "obsolete-attribute" {
+			obsolete = append(obsolete, issue.Tag)
+		}
+	}
+	assert.ElementsMatch(t, []string{"body", "font", "center"}, obsolete)
+
+	var stray []string
+	for _, issue := range issues {
+		if issue.Rule == "stray-end-tag" {
+			stray = append(stray, issue.Tag)
+		}
+	}
+	assert.Equal(t, []string{"a", "form", "p"}, stray)
+
+	selfClosing := findConformanceIssue(issues, "self-closing-element")
+	assert.NotNil(t, selfClosing)
+	assert.Equal(t, 12, selfClosing.Line)
+}
+
+func TestConformanceAnalyzer_Analyze_StrayEndTags(t *testing.T) {
+	report := analyze.CheckConformance("<div>Text</div></div></span></br><p>End</p></p>")
+
+	var tags []string
+	for _, issue := range report.Issues {
+		assert.Equal(t, "stray-end-tag", issue.Rule)
+		tags = append(tags, issue.Tag)
+	}
+	assert.Equal(t, []string{"div", "span", "br", "p"}, tags)
+}
+
+func TestConformanceAnalyzer_Analyze_ReopenedFormatting(t *testing.T) {
+	report := analyze.CheckConformance("<div><b>Bold</div><p>Still bold</b></p>")
+
+	assert.Equal(t, 1, report.Total)
+	assert.Equal(t, "unclosed-element", report.Issues[0].Rule)
+	assert.Equal(t, "b", report.Issues[0].Tag)
+}
+
+func TestConformanceAnalyzer_Analyze_CapAndSummary(t *testing.T) {
+	htmlContent := "<div id=\"x\"></div>" + strings.Repeat("<font id=\"x\">Old</font>\n", 150)
+	report := analyze.CheckConformance(htmlContent)
+
+	assert.Equal(t, 300, report.Total)
+	assert.True(t, report.Truncated)
+	assert.Len(t, report.Issues, 200)
+	assert.Len(t, report.Summary, 2)
+	for _, summary := range report.Summary {
+		assert.Equal(t, 150, summary.Count)
+		assert.Equal(t, 1, summary.FirstLine)
+	}
+	assert.Equal(t, "duplicate-id", report.Summary[0].Rule)
+	assert.Equal(t, constant.SEVERITY_ERROR, report.Summary[0].Severity)
+	assert.Equal(t, "obsolete-element", report.Summary[1].Rule)
+}
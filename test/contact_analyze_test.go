@@ -0,0 +1,183 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+const contactPage = `<!DOCTYPE html>
+<html lang="en-GB">
+<head>
+<title>Contact</title>
+<script type="application/ld+json">
+{"@context": "https://schema.org", "@type": "Organization", "name": "Acme",
+ "email": "mailto:Sales@Acme.co.uk", "telephone": "+44 20 7946 0958",
+ "address": {"@type": "PostalAddress", "streetAddress": "1 High Street", "addressLocality": "London",
+   "postalCode": "EC1A 1BB", "addressCountry": {"@type": "Country", "name": "GB"}},
+ "sameAs": ["https://www.linkedin.com/company/acme-ltd", "https://github.com/acme"]}
+</script>
+<script>var support = "ignored@example.com";</script>
+</head>
+<body>
+<header><a href="mailto:sales@acme.co.uk?subject=Hello">Email sales</a></header>
+<main>
+<p>Write to support@acme.co.uk or call 020 7946 0958. Our office opened on 2021-05-17.</p>
+<p>Order line: <a href="tel:+44-20-7946-0000">020 7946 0000</a>. Logo: logo@2x.png</p>
+<p>Share: <a href="https://twitter.com/intent/tweet?url=x">Tweet</a> <a href="https://www.facebook.com/sharer/sharer.php?u=x">Share</a></p>
+</main>
+<footer>
+<address>Acme Ltd<br>1 High Street<br>London, EC1A 1BB<br>GB<br><a href="mailto:info@acme.co.uk">info@acme.co.uk</a></address>
+<a href="https://x.com/acme">X</a>
+<a href="https://www.linkedin.com/company/acme-ltd/">LinkedIn</a>
+<a href="https://www.youtube.com/@acme/videos">YouTube</a>
+<a href="https://github.com/acme/website">Source</a>
+</footer>
+</body>
+</html>`
+
+func TestContactAnalyzer_Analyze(t *testing.T) {
+	wc := response.WebContent{Content: contactPage, FinalUrl: "https://www.acme.com/contact"}
+	res := response.SuccessResponse{}
+	err := analyze.NewContactAnalyzer().Analyze(&wc, &res)
+	contacts := res.Contacts
+
+	assert.Nil(t, err)
+	var emails []string
+	for _, email := range contacts.Emails {
+		emails = append(emails, email.Address)
+	}
+	assert.Equal(t, []string{"sales@acme.co.uk", "support@acme.co.uk", "info@acme.co.uk"}, emails)
+	assert.Equal(t, []response.ContactSource{
+		{Type: constant.CONTACT_SOURCE_MAILTO, Section: "header"},
+		{Type: constant.SOURCE_JSON_LD},
+	}, contacts.Emails[0].Sources)
+	assert.Equal(t, []response.ContactSource{{Type: constant.CONTACT_SOURCE_MAILTO, Section: "address"}}, contacts.Emails[2].Sources)
+
+	assert.Len(t, contacts.Phones, 2)
+	assert.Equal(t, "+442079460958", contacts.Phones[0].Number)
+	assert.Equal(t, "020 7946 0958", contacts.Phones[0].Raw)
+	assert.Equal(t, []response.ContactSource{
+		{Type: constant.CONTACT_SOURCE_TEXT, Section: "main"},
+		{Type: constant.SOURCE_JSON_LD},
+	}, contacts.Phones[0].Sources)
+	assert.Equal(t, "+442079460000", contacts.Phones[1].Number)
+	assert.Equal(t, []response.ContactSource{{Type: constant.CONTACT_SOURCE_TEL, Section: "main"}}, contacts.Phones[1].Sources)
+
+	assert.Len(t, contacts.Addresses, 2)
+	assert.Equal(t, "Acme Ltd, 1 High Street, London, EC1A 1BB, GB", contacts.Addresses[0].Text)
+	assert.Equal(t, []response.ContactSource{{Type: constant.CONTACT_SOURCE_ADDRESS, Section: "footer"}}, contacts.Addresses[0].Sources)
+	assert.Equal(t, "1 High Street, London, EC1A 1BB, GB", contacts.Addresses[1].Text)
+	assert.Equal(t, "London", contacts.Addresses[1].Locality)
+	assert.Equal(t, "GB", contacts.Addresses[1].Country)
+
+	var profiles []string
+	for _, profile := range contacts.SocialProfiles {
+		profiles = append(profiles, profile.Platform+" "+profile.Handle+" "+profile.Url)
+	}
+	assert.Equal(t, []string{
+		"X acme https://x.com/acme",
+		"LinkedIn company/acme-ltd https://linkedin.com/company/acme-ltd",
+		"YouTube @acme https://youtube.com/@acme",
+		"GitHub acme https://github.com/acme",
+	}, profiles)
+	assert.Equal(t, []response.ContactSource{
+		{Type: constant.CONTACT_SOURCE_LINK, Section: "footer"},
+		{Type: constant.SOURCE_JSON_LD},
+	}, contacts.SocialProfiles[1].Sources)
+}
+
+func TestContactAnalyzer_Analyze_EmailsDisabled(t *testing.T) {
+	configs.GetConfig().ContactEmails = false
+	defer func() { configs.GetConfig().ContactEmails = true }()
+
+	wc := response.WebContent{Content: contactPage, FinalUrl: "https://www.acme.com/contact"}
+	res := response.SuccessResponse{}
+	analyze.NewContactAnalyzer().Analyze(&wc, &res)
+
+	assert.True(t, res.Contacts.EmailsDisabled)
+	assert.Empty(t, res.Contacts.Emails)
+	assert.NotEmpty(t, res.Contacts.Phones)
+}
+
+func TestContactAnalyzer_ExtractContacts_NationalNumbers(t *testing.T) {
+	htmlContent := `<html lang="en"><body><p>Call (415) 555-0134 or 1-415-555-0199, fax 0049 30 123456.</p>
+<p>Invoice 12 34 56 and price 1 000 000.</p></body></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+
+	contacts := analyze.ExtractContacts(doc, "https://shop.example.us/", true)
+	var numbers []string
+	for _, phone := range contacts.Phones {
+		numbers = append(numbers, phone.Number)
+	}
+	// 00 is not the international prefix of the North American numbering plan
+	assert.Equal(t, []string{"+14155550134", "+14155550199"}, numbers)
+
+	contacts = analyze.ExtractContacts(doc, "https://shop.example.com/", true)
+	assert.Len(t, contacts.Phones, 1)
+	assert.Equal(t, "+4930123456", contacts.Phones[0].Number)
+}
+
+func TestContactAnalyzer_ExtractContacts_NumericFigures(t *testing.T) {
+	tests := []struct {
+		text    string
+		pageURL string
+	}{
+		{"Prix : 12 345 678 €", "https://shop.example.fr/"},
+		{"Served from 192.168.10.10", "https://shop.example.uk/"},
+		{"Population 1 234 567", "https://shop.example.de/"},
+	}
+	for _, test := range tests {
+		doc, _ := html.Parse(strings.NewReader("<html><body><p>" + test.text + "</p></body></html>"))
+
+		contacts := analyze.ExtractContacts(doc, test.pageURL, true)
+
+		assert.Empty(t, contacts.Phones, test.text)
+	}
+}
+
+func TestContactAnalyzer_Analyze_JsonLdDecodesCharset(t *testing.T) {
+	// windows-1252 bytes, 0xE8 is è and 0xE9 is é
+	htmlContent := "<html><head><script type=\"application/ld+json\">" +
+		"{\"@type\": \"PostalAddress\", \"streetAddress\": \"3 rue de la Libert\xe9\", \"addressLocality\": \"Gen\xe8ve\"}" +
+		"</script></head><body></body></html>"
+	wc := response.WebContent{Content: htmlContent, FinalUrl: "https://www.acme.ch/",
+		Headers: http.Header{"Content-Type": []string{"text/html; charset=windows-1252"}}}
+	res := response.SuccessResponse{}
+
+	analyze.NewContactAnalyzer().Analyze(&wc, &res)
+
+	assert.Len(t, res.Contacts.Addresses, 1)
+	assert.Equal(t, "3 rue de la Liberté", res.Contacts.Addresses[0].StreetAddress)
+	assert.Equal(t, "Genève", res.Contacts.Addresses[0].Locality)
+}
+
+func TestContactAnalyzer_NormalizePhoneNumber(t *testing.T) {
+	tests := []struct {
+		raw    string
+		region string
+		number string
+	}{
+		{"+1 (415) 555-0134", "", "+14155550134"},
+		{"0044 20 7946 0958", "de", "+442079460958"},
+		{"011 44 20 7946 0958", "us", "+442079460958"},
+		{"030 123456", "de", "+4930123456"},
+		{"06 12 34 56 78", "fr", "+33612345678"},
+		{"02 1234 5678", "it", "+390212345678"},
+		{"020 7946 0958", "", ""},
+		{"(015) 555-0134", "us", ""},
+		{"+0 123 4567", "", ""},
+	}
+	for _, test := range tests {
+		number, ok := analyze.NormalizePhoneNumber(test.raw, test.region)
+		assert.Equal(t, test.number, number, test.raw)
+		assert.Equal(t, test.number != "", ok, test.raw)
+	}
+}
@@ -0,0 +1,120 @@
+package test
+
+import (
+	"api/analyze"
+	"api/response"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+const articleParagraph = "The garden grows tomatoes, beans and herbs in raised beds. Every morning the gardener waters the tomatoes before the sun gets hot. "
+
+func TestContentAnalyzer_Analyze_MainContent(t *testing.T) {
+	htmlContent := `<!DOCTYPE html>
+<html lang="en">
+<head><meta charset="utf-8"><title>Garden</title><style>body { color: red; }</style><script>var tracking = "script words";</script></head>
+<body>
+<header><a href="/">Home</a> <a href="/shop">Shop</a></header>
+<nav><ul><li><a href="/a">Navigation link one</a></li><li><a href="/b">Navigation link two</a></li></ul></nav>
+<div class="cookie-banner">We use cookies to improve the experience of this site.</div>
+<article>
+<h1>Tomato garden</h1>
+<p>` + strings.Repeat(articleParagraph, 10) + `</p>
+<p>` + strings.Repeat(articleParagraph, 10) + `</p>
+<div class="share-buttons">Share on social networks</div>
+</article>
+<aside>Related posts and sponsored content.</aside>
+<footer>Copyright footer text</footer>
+</body>
+</html>`
+	wc := response.WebContent{Content: htmlContent}
+	res := response.SuccessResponse{}
+	err := analyze.NewContentAnalyzer().Analyze(&wc, &res)
+	content := res.Content
+
+	assert.Nil(t, err)
+	assert.Equal(t, 442, content.WordCount)
+	assert.Greater(t, content.PageWordCount, content.WordCount)
+	assert.Equal(t, 3, content.ParagraphCount)
+	assert.Equal(t, 41, content.SentenceCount)
+	assert.Equal(t, 2, content.ReadingTimeMinutes)
+	assert.True(t, strings.HasPrefix(content.Excerpt, "Tomato garden The garden grows tomatoes"))
+	assert.NotContains(t, content.Excerpt, "Navigation")
+	assert.NotContains(t, content.Excerpt, "cookies")
+
+	assert.Equal(t, "en", content.Language)
+	assert.Equal(t, "en", content.DeclaredLanguage)
+	assert.True(t, content.LanguageMatch)
+	assert.Equal(t, "utf-8", content.Charset)
+
+	assert.NotNil(t, content.Readability)
+	assert.Greater(t, content.Readability.FleschReadingEase, 60.0)
+	assert.NotEmpty(t, content.Readability.Level)
+
+	assert.Equal(t, "tomatoes", content.Keywords[0].Term)
+	assert.Equal(t, 40, content.Keywords[0].Count)
+	assert.InDelta(t, 9.05, content.Keywords[0].Density, 0.01)
+	assert.Len(t, content.Phrases, 10)
+	assert.Equal(t, response.Keyword{Term: "beans and herbs", Count: 20, Density: 13.57}, content.Phrases[0])
+	assert.True(t, hasRule(content.Findings, "keyword-stuffing"))
+	assert.False(t, hasRule(content.Findings, "content-thin"))
+	assert.False(t, hasRule(content.Findings, "language-mismatch"))
+}
+
+func TestContentAnalyzer_Analyze_ScoredContent(t *testing.T) {
+	htmlContent := `<html><body>
+<div id="menu"><a href="/a">Menu item with a long enough label</a></div>
+<div class="links"><p><a href="/1">A paragraph made only of a link, which is not content</a></p></div>
+<div class="post"><p>This paragraph, with its commas, is the main content of the page.</p><p>It continues here with a second paragraph of content.</p></div>
+</body></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+	report := analyze.AnalyzeContent(doc, len(htmlContent))
+
+	assert.Equal(t, 2, report.ParagraphCount)
+	assert.True(t, strings.HasPrefix(report.Excerpt, "This paragraph, with its commas"))
+	assert.Equal(t, 21, report.WordCount)
+}
+
+func TestContentAnalyzer_Analyze_LanguageMismatch(t *testing.T) {
+	paragraph := "Der Garten ist groß und die Tomaten wachsen in der Sonne. Wir gießen sie jeden Morgen, damit sie nicht austrocknen und der Boden feucht bleibt. "
+	htmlContent := `<html lang="en-US"><body><main><p>` + strings.Repeat(paragraph, 3) + `</p></main></body></html>`
+	wc := response.WebContent{Content: htmlContent}
+	res := response.SuccessResponse{}
+	analyze.NewContentAnalyzer().Analyze(&wc, &res)
+
+	assert.Equal(t, "de", res.Content.Language)
+	assert.Equal(t, "en-US", res.Content.DeclaredLanguage)
+	assert.False(t, res.Content.LanguageMatch)
+	assert.Nil(t, res.Content.Readability)
+	assert.True(t, hasRule(res.Content.Findings, "language-mismatch"))
+	assert.True(t, hasRule(res.Content.Findings, "content-thin"))
+}
+
+func TestContentAnalyzer_Analyze_DeclaredCharset(t *testing.T) {
+	// "Crème brûlée" encoded in ISO-8859-1
+	htmlContent := "<html><head><title>Dessert</title></head><body><p>Cr\xe8me br\xfbl\xe9e</p></body></html>"
+	headers := http.Header{}
+	headers.Set("Content-Type", "text/html; charset=iso-8859-1")
+	headers.Set("Content-Language", "fr")
+	wc := response.WebContent{Content: htmlContent, Headers: headers}
+	res := response.SuccessResponse{}
+	analyze.NewContentAnalyzer().Analyze(&wc, &res)
+
+	assert.Equal(t, "windows-1252", res.Content.Charset)
+	assert.Equal(t, "Crème brûlée", res.Content.Excerpt)
+	assert.Equal(t, 2, res.Content.WordCount)
+	assert.Equal(t, "fr", res.Content.DeclaredLanguage)
+	assert.Empty(t, res.Content.Language)
+	assert.False(t, hasRule(res.Content.Findings, "language-mismatch"))
+}
+
+func TestContentAnalyzer_DetectLanguage_Scripts(t *testing.T) {
+	assert.Equal(t, "ja", analyze.DetectLanguage("日本語のテキストです", nil))
+	assert.Equal(t, "zh", analyze.DetectLanguage("这是中文文本", nil))
+	assert.Equal(t, "ru", analyze.DetectLanguage("Это русский текст", nil))
+	assert.Empty(t, analyze.DetectLanguage("Too short", []string{"too", "short"}))
+}
@@ -0,0 +1,164 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findCookie(cookies []response.CookieInfo, name string) *response.CookieInfo {
+	for i := range cookies {
+		if cookies[i].Name == name {
+			return &cookies[i]
+		}
+	}
+	return nil
+}
+
+func hasRule(findings []response.Finding, rule string) bool {
+	for _, finding := range findings {
+		if finding.Rule == rule {
+			return true
+		}
+	}
+	return false
+}
+
+func TestReadResponseCookies_Flags(t *testing.T) {
+	headers := http.Header{}
+	headers.Add("Set-Cookie", "SESSIONID=abc; Path=/; Secure")
+	headers.Add("Set-Cookie", "theme=dark; Path=/; Max-Age=3600; HttpOnly; SameSite=Lax")
+	headers.Add("Set-Cookie", "tracker=1; Domain=example.com; SameSite=None")
+
+	cookies := analyze.ReadResponseCookies("https://www.example.com/", headers, false)
+
+	assert.Len(t, cookies, 3)
+
+	session := findCookie(cookies, "SESSIONID")
+	assert.True(t, session.Session)
+	assert.True(t, session.Secure)
+	assert.Equal(t, "www.example.com", session.Domain)
+	assert.True(t, hasRule(session.Findings, "session-cookie-not-httponly"))
+
+	theme := findCookie(cookies, "theme")
+	assert.False(t, theme.Session)
+	assert.Equal(t, 3600, theme.MaxAge)
+	assert.Equal(t, "Lax", theme.SameSite)
+	assert.True(t, hasRule(theme.Findings, "cookie-not-secure"))
+
+	tracker := findCookie(cookies, "tracker")
+	assert.Equal(t, "example.com", tracker.Domain)
+	assert.Equal(t, "None", tracker.SameSite)
+	assert.True(t, hasRule(tracker.Findings, "samesite-none-not-secure"))
+}
+
+func TestCookieAnalyzer_Analyze_RedirectsAndThirdParty(t *testing.T) {
+	thirdParty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "uid", Value: "42", Path: "/"})
+		fmt.Fprint(w, "pixel")
+	}))
+	defer thirdParty.Close()
+	thirdPartyURL := strings.Replace(thirdParty.URL, "127.0.0.1", "localhost", 1)
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = thirdParty.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	redirectHeaders := http.Header{}
+	redirectHeaders.Add("Set-Cookie", "visited=1; Path=/")
+	pageHeaders := http.Header{}
+	pageHeaders.Add("Set-Cookie", "lang=en; Path=/; HttpOnly")
+
+	wc := &response.WebContent{
+		Content:  `<html><body><img src="` + thirdPartyURL + `/pixel.gif"><a href="` + thirdPartyURL + `/page">link</a><img src="/local.png"></body></html>`,
+		FinalUrl: "http://127.0.0.1/home",
+		Headers:  pageHeaders,
+		Redirects: []response.Redirect{
+			{Url: "http://127.0.0.1/", Status: http.StatusFound, Location: "/home", Headers: redirectHeaders},
+		},
+	}
+	res := &response.SuccessResponse{BasePath: "http://127.0.0.1"}
+	analyzer := analyze.NewCookieAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.True(t, res.Cookies.ThirdPartyProbed)
+	assert.Len(t, res.Cookies.Cookies, 3)
+	assert.Equal(t, 1, res.Cookies.ThirdPartyCount)
+	assert.Equal(t, "http://127.0.0.1/", findCookie(res.Cookies.Cookies, "visited").SourceUrl)
+	uid := findCookie(res.Cookies.Cookies, "uid")
+	assert.NotNil(t, uid)
+	assert.True(t, uid.ThirdParty)
+	assert.Equal(t, thirdPartyURL+"/pixel.gif", uid.SourceUrl)
+	assert.True(t, hasRule(uid.Findings, "third-party-cookie"))
+}
+
+func TestCookieAnalyzer_Analyze_BoundedOrderedProbes(t *testing.T) {
+	var inFlight, maxInFlight atomic.Int32
+	thirdParty := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		current := inFlight.Add(1)
+		defer inFlight.Add(-1)
+		for seen := maxInFlight.Load(); current > seen && !maxInFlight.CompareAndSwap(seen, current); seen = maxInFlight.Load() {
+		}
+		time.Sleep(5 * time.Millisecond)
+		http.SetCookie(w, &http.Cookie{Name: strings.TrimPrefix(r.URL.Path, "/"), Value: "1"})
+	}))
+	defer thirdParty.Close()
+	thirdPartyURL := strings.Replace(thirdParty.URL, "127.0.0.1", "localhost", 1)
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = thirdParty.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	var body strings.Builder
+	var names []string
+	for i := 0; i < 40; i++ {
+		names = append(names, fmt.Sprintf("c%02d", i))
+		fmt.Fprintf(&body, `<script src="%s/c%02d"></script>`, thirdPartyURL, i)
+	}
+	wc := &response.WebContent{Content: "<html><body>" + body.String() + "</body></html>", FinalUrl: "http://127.0.0.1/"}
+	res := &response.SuccessResponse{BasePath: "http://127.0.0.1"}
+
+	err := analyze.NewCookieAnalyzer().Analyze(wc, res)
+
+	assert.Nil(t, err)
+	var got []string
+	for _, cookie := range res.Cookies.Cookies {
+		got = append(got, cookie.Name)
+	}
+	assert.Equal(t, names, got, "cookies are listed in the order of the subresources")
+	assert.LessOrEqual(t, maxInFlight.Load(), int32(8))
+}
+
+func TestCookieAnalyzer_Analyze_LinkProbeDisabled(t *testing.T) {
+	configs.GetConfig().LinkProbe = false
+	defer func() { configs.GetConfig().LinkProbe = true }()
+
+	headers := http.Header{}
+	headers.Add("Set-Cookie", "id=1")
+	wc := &response.WebContent{
+		Content:  `<html><body><script src="http://tracker.invalid/t.js"></script></body></html>`,
+		FinalUrl: "https://example.com",
+		Headers:  headers,
+	}
+	res := &response.SuccessResponse{BasePath: "https://example.com"}
+	analyzer := analyze.NewCookieAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.False(t, res.Cookies.ThirdPartyProbed)
+	assert.Len(t, res.Cookies.Cookies, 1)
+	assert.Equal(t, constant.SEVERITY_WARNING, res.Cookies.Cookies[0].Findings[0].Severity)
+}
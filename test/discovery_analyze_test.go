@@ -0,0 +1,174 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+const rssFeed = `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0"><channel><title> Acme News </title><lastBuildDate>Tue, 10 Jun 2025 04:00:00 GMT</lastBuildDate>
+<item><title>First</title><pubDate>Mon, 09 Jun 2025 09:30:00 +0200</pubDate></item>
+<item><title>Second</title><pubDate>Wed, 11 Jun 2025 08:00:00 GMT</pubDate></item>
+</channel></rss>`
+
+const atomFeed = `<?xml version="1.0" encoding="utf-8"?>
+<feed xmlns="http://www.w3.org/2005/Atom"><title>Acme Blog</title><updated>2025-06-01T10:00:00Z</updated>
+<entry><title>Post</title><updated>2025-05-30T12:00:00+02:00</updated></entry></feed>`
+
+const webManifestJson = `{"name": "Acme", "short_name": "Acme", "start_url": "/?source=pwa", "display": "standalone",
+ "theme_color": "#336699", "icons": [{"src": "icons/192.png", "sizes": "192x192", "type": "image/png"},
+ {"src": "icons/512.png", "sizes": "512x512", "type": "image/png", "purpose": "any maskable"}]}`
+
+func TestDiscoveryAnalyzer_Analyze_FetchesFeedsAndManifest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/feed.xml":
+			w.Header().Set("Content-Type", "application/rss+xml")
+			w.Write([]byte(rssFeed))
+		case "/atom.xml":
+			w.Header().Set("Content-Type", "application/atom+xml")
+			w.Write([]byte(atomFeed))
+		case "/broken.xml":
+			w.Write([]byte("<rss><channel><title>Unclosed</channel></rss>"))
+		case "/app/manifest.webmanifest":
+			w.Header().Set("Content-Type", "application/manifest+json")
+			w.Write([]byte(webManifestJson))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	htmlContent := `<html><head>
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<link rel="alternate" type="application/rss+xml" title="News" href="/feed.xml">
+		<link rel="alternate" type="application/rss+xml" href="/atom.xml">
+		<link rel="alternate" type="application/rss+xml" href="/broken.xml">
+		<link rel="alternate" type="application/atom+xml" href="/missing.xml">
+		<link rel="manifest" href="/app/manifest.webmanifest">
+		<link rel="icon" href="/favicon.svg" type="image/svg+xml">
+		<link rel="apple-touch-icon" sizes="180x180" href="/apple.png">
+		<link rel="search" type="application/opensearchdescription+xml" title="Acme" href="/opensearch.xml">
+		<link rel="amphtml" href="/amp/">
+		<link rel="me" href="https://mastodon.social/@acme">
+		<script>if ("serviceWorker" in navigator) { navigator.serviceWorker.register("/sw.js"); }</script>
+	</head><body><a rel="me noopener" href="https://github.com/acme">GitHub</a></body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: server.URL + "/"}
+	res := &response.SuccessResponse{}
+
+	err := analyze.NewDiscoveryAnalyzer().Analyze(wc, res)
+
+	assert.Nil(t, err)
+	report := res.Discovery
+	assert.Len(t, report.Feeds, 4)
+
+	feed := report.Feeds[0]
+	assert.Equal(t, "News", feed.Title)
+	assert.True(t, feed.Valid)
+	assert.Equal(t, constant.FEED_RSS, feed.Format)
+	assert.Equal(t, "Acme News", feed.FeedTitle)
+	assert.Equal(t, 2, feed.Items)
+	assert.Equal(t, time.Date(2025, 6, 11, 8, 0, 0, 0, time.UTC), *feed.LastUpdated)
+
+	atom := report.Feeds[1]
+	assert.Equal(t, constant.FEED_ATOM, atom.Format)
+	assert.Equal(t, time.Date(2025, 6, 1, 10, 0, 0, 0, time.UTC), *atom.LastUpdated)
+	assert.False(t, report.Feeds[2].Valid)
+	assert.NotEmpty(t, report.Feeds[2].Error)
+	assert.Equal(t, http.StatusNotFound, report.Feeds[3].Status)
+	assert.True(t, hasRule(report.Findings, "feed-type-mismatch"))
+	assert.True(t, hasRule(report.Findings, "feed-invalid"))
+	assert.True(t, hasRule(report.Findings, "feed-broken"))
+	assert.False(t, hasRule(report.Findings, "favicon-missing"))
+
+	manifest := report.Manifest
+	assert.True(t, manifest.Valid)
+	assert.Equal(t, "standalone", manifest.Display)
+	assert.Equal(t, server.URL+"/?source=pwa", manifest.StartUrl)
+	assert.Equal(t, server.URL+"/app/icons/512.png", manifest.Icons[1].Src)
+
+	assert.Equal(t, []response.IconLink{
+		{Url: server.URL + "/favicon.svg", Rel: "icon", Type: "image/svg+xml"},
+		{Url: server.URL + "/apple.png", Rel: "apple-touch-icon", Sizes: "180x180"},
+	}, report.Icons)
+	assert.Equal(t, []string{"https://mastodon.social/@acme", "https://github.com/acme"}, report.Me)
+	assert.Equal(t, &response.OpenSearchLink{Url: server.URL + "/opensearch.xml", Title: "Acme"}, report.Search)
+	assert.Equal(t, server.URL+"/amp/", report.AmpUrl)
+	assert.True(t, report.ServiceWorker.Registered)
+	assert.Equal(t, server.URL+"/sw.js", report.ServiceWorker.ScriptUrl)
+
+	// the test server runs on the loopback address, which counts as a secure origin
+	assert.True(t, report.Pwa.Ready)
+	for _, check := range report.Pwa.Checks {
+		assert.True(t, check.Passed, check.Name)
+	}
+}
+
+func TestDiscoveryAnalyzer_Analyze_WithoutProbing(t *testing.T) {
+	configs.GetConfig().LinkProbe = false
+	defer func() { configs.GetConfig().LinkProbe = true }()
+
+	htmlContent := `<html amp><head><link rel="manifest" href="/manifest.json"></head><body></body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://example.com/"}
+	res := &response.SuccessResponse{}
+
+	analyze.NewDiscoveryAnalyzer().Analyze(wc, res)
+
+	report := res.Discovery
+	assert.True(t, report.IsAmp)
+	assert.False(t, report.Manifest.Valid)
+	assert.True(t, hasRule(report.Findings, "favicon-missing"))
+	assert.False(t, hasRule(report.Findings, "manifest-broken"))
+	assert.False(t, report.Pwa.Ready)
+	assert.True(t, report.Pwa.Checks[0].Passed)
+	assert.Equal(t, "manifest", report.Pwa.Checks[1].Name)
+	assert.Equal(t, "The manifest was not fetched, link probing is disabled", report.Pwa.Checks[1].Detail)
+}
+
+func TestDiscoveryAnalyzer_ParseFeed(t *testing.T) {
+	rdf := `<rdf:RDF xmlns:rdf="http://www.w3.org/1999/02/22-rdf-syntax-ns#" xmlns="http://purl.org/rss/1.0/"
+		xmlns:dc="http://purl.org/dc/elements/1.1/"><channel><title>Old</title></channel>
+		<item><dc:date>2024-01-02T03:04:05Z</dc:date></item><item></item></rdf:RDF>`
+	jsonFeed := `{"version": "https://jsonfeed.org/version/1.1", "title": "Json",
+		"items": [{"date_published": "2025-02-01T00:00:00Z", "date_modified": "2025-03-01T00:00:00Z"}]}`
+
+	feed := response.FeedInfo{}
+	assert.Nil(t, analyze.ParseFeed([]byte(rdf), "application/rdf+xml", &feed))
+	assert.Equal(t, constant.FEED_RDF, feed.Format)
+	assert.Equal(t, 2, feed.Items)
+	assert.Equal(t, time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC), *feed.LastUpdated)
+
+	feed = response.FeedInfo{}
+	assert.Nil(t, analyze.ParseFeed([]byte(jsonFeed), "application/feed+json", &feed))
+	assert.Equal(t, constant.FEED_JSON, feed.Format)
+	assert.Equal(t, time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), *feed.LastUpdated)
+
+	assert.NotNil(t, analyze.ParseFeed([]byte(`{"items": []}`), "application/json", &response.FeedInfo{}))
+	assert.NotNil(t, analyze.ParseFeed([]byte(`<html><body></body></html>`), "text/html", &response.FeedInfo{}))
+}
+
+func TestDiscoveryAnalyzer_ExtractDiscoveryLinks_ServiceWorkerHints(t *testing.T) {
+	htmlContent := `<html><head><script src="https://cdn.example.com/workbox-sw.js"></script>
+		<script>if (navigator.serviceWorker) { register(); }</script></head></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+
+	report := analyze.ExtractDiscoveryLinks(doc, "https://example.com/")
+
+	assert.False(t, report.ServiceWorker.Registered)
+	assert.Equal(t, []string{"Workbox script https://cdn.example.com/workbox-sw.js",
+		"navigator.serviceWorker feature detection"}, report.ServiceWorker.Hints)
+}
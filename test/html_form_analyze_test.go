@@ -0,0 +1,90 @@
+package test
+
+import (
+	"api/analyze"
+	"api/response"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHtmlFormAnalyzer_Analyze_Inventory(t *testing.T) {
+	htmlContent := `<html><body>
+		<form action="/checkout" method="post" enctype="multipart/form-data">
+			<input type="hidden" name="csrf_token" value="abc">
+			<label for="email">Email address</label>
+			<input id="email" name="email" type="email" required autocomplete="email">
+			<label>Comment <textarea name="comment"></textarea></label>
+			<span id="country-label">Country</span>
+			<select name="country" aria-labelledby="country-label"><option>NL</option></select>
+			<input type="file" name="attachment" aria-label="Attachment">
+			<div class="g-recaptcha" data-sitekey="key"></div>
+			<button type="submit">Pay</button>
+		</form>
+		<form action="http://newsletter.other.com/subscribe" method="post">
+			<input name="address">
+		</form>
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://shop.example.com/cart"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewHtmlFormAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Forms, 2)
+
+	checkout := res.Forms[0]
+	assert.Equal(t, "https://shop.example.com/checkout", checkout.Action)
+	assert.Equal(t, "POST", checkout.Method)
+	assert.Equal(t, "multipart/form-data", checkout.Enctype)
+	assert.Equal(t, "csrf_token", checkout.CsrfToken)
+	assert.Equal(t, []string{"reCAPTCHA"}, checkout.Captchas)
+	assert.Equal(t, []string{"attachment"}, checkout.FileUploads)
+	assert.False(t, checkout.ThirdPartyAction)
+	assert.Empty(t, checkout.Findings)
+	assert.Len(t, checkout.Fields, 5)
+	assert.Equal(t, response.FormField{Name: "email", Tag: "input", Type: "email", Required: true, Autocomplete: "email", Label: "Email address"}, checkout.Fields[1])
+	assert.Equal(t, "Comment", checkout.Fields[2].Label)
+	assert.Equal(t, "Country", checkout.Fields[3].Label)
+	assert.Equal(t, "Attachment", checkout.Fields[4].Label)
+
+	newsletter := res.Forms[1]
+	assert.True(t, newsletter.ThirdPartyAction)
+	assert.True(t, newsletter.InsecureAction)
+	assert.Equal(t, "text", newsletter.Fields[0].Type)
+	assert.True(t, hasRule(newsletter.Findings, "form-third-party-action"))
+	assert.True(t, hasRule(newsletter.Findings, "form-insecure-action"))
+	assert.True(t, hasRule(newsletter.Findings, "form-no-csrf-token"))
+}
+
+func TestHtmlFormAnalyzer_Analyze_DefaultsAndUploadEnctype(t *testing.T) {
+	htmlContent := `<html><body><form><input type="file" name="cv"></form></body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://example.com/jobs"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewHtmlFormAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Forms, 1)
+	assert.Equal(t, "https://example.com/jobs", res.Forms[0].Action)
+	assert.Equal(t, "GET", res.Forms[0].Method)
+	assert.Equal(t, "application/x-www-form-urlencoded", res.Forms[0].Enctype)
+	assert.True(t, hasRule(res.Forms[0].Findings, "form-file-upload-enctype"))
+}
+
+func TestHtmlFormAnalyzer_Analyze_DecodesCharset(t *testing.T) {
+	// windows-1252 bytes, 0xE9 is é
+	htmlContent := "<html><body><form><label for=\"name\">Pr\xe9nom</label><input id=\"name\" name=\"pr\xe9nom\"></form></body></html>"
+	headers := http.Header{"Content-Type": []string{"text/html; charset=windows-1252"}}
+	wc := &response.WebContent{Content: htmlContent, Headers: headers, FinalUrl: "https://example.fr/"}
+	res := &response.SuccessResponse{}
+
+	err := analyze.NewHtmlFormAnalyzer().Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Prénom", res.Forms[0].Fields[0].Label)
+	assert.Equal(t, "prénom", res.Forms[0].Fields[0].Name)
+}
@@ -77,7 +77,7 @@ func TestHtmlHeadingAnalyzer_Analyze_NestedContent(t *testing.T) {
 	assert.Len(t, res.Headings, 1)
 	if len(res.Headings) == 1 {
 		assert.Equal(t, "h1", res.Headings[0].Tag)
-		assert.Equal(t, "Heading with", strings.TrimSpace(res.Headings[0].Text))
+		assert.Equal(t, "Heading with bold text", res.Headings[0].Text)
 	}
 }
 
@@ -93,7 +93,7 @@ func TestHtmlHeadingAnalyzer_Analyze_DeeplyNestedText(t *testing.T) {
 	assert.Len(t, res.Headings, 1)
 	if len(res.Headings) == 1 {
 		assert.Equal(t, "h1", res.Headings[0].Tag)
-		assert.Equal(t, "span", strings.TrimSpace(res.Headings[0].Text))
+		assert.Equal(t, "Deep Text", res.Headings[0].Text)
 	}
 }
 
@@ -149,3 +149,80 @@ func TestSetHeadingDataToResponse(t *testing.T) {
 	assert.Equal(t, "h3", res.Headings[1].Tag)
 	assert.Equal(t, "Another Heading", res.Headings[1].Text)
 }
+
+func TestHtmlHeadingAnalyzer_Analyze_HeadingsAfterNestedContent(t *testing.T) {
+	htmlContent := `<html><body><h2><span>Foo</span> bar</h2><h1>Main <img src="logo.png" alt="Logo"></h1><h3>Third</h3></body></html>`
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewHtmlHeadingAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Headings, 3)
+	assert.Equal(t, response.Heading{Tag: "h2", Level: 2, Text: "Foo bar"}, res.Headings[0])
+	assert.Equal(t, response.Heading{Tag: "h1", Level: 1, Text: "Main Logo"}, res.Headings[1])
+	assert.Equal(t, response.Heading{Tag: "h3", Level: 3, Text: "Third"}, res.Headings[2])
+}
+
+func TestHtmlHeadingAnalyzer_Analyze_MismatchedEndTag(t *testing.T) {
+	htmlContent := `<html><body><h2>Foo</h3><p>` + strings.Repeat("Long paragraph ", 10) + `</p><h3>Next</h3></body></html>`
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewHtmlHeadingAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Headings, 2)
+	assert.Equal(t, response.Heading{Tag: "h2", Level: 2, Text: "Foo"}, res.Headings[0])
+	assert.Equal(t, response.Heading{Tag: "h3", Level: 3, Text: "Next"}, res.Headings[1])
+	assert.False(t, hasRule(res.HeadingFindings, "heading-too-long"))
+}
+
+func TestHtmlHeadingAnalyzer_Analyze_CountsOutlineAndFindings(t *testing.T) {
+	htmlContent := `<html><body>
+		<h1>Guide</h1>
+		<h2>Install</h2><h4>Linux</h4><h3>Windows</h3>
+		<h2>Usage</h2><h2></h2>
+		<h1>` + strings.Repeat("Long ", 20) + `</h1>
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewHtmlHeadingAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, map[string]int{"h1": 2, "h2": 3, "h3": 1, "h4": 1, "h5": 0, "h6": 0}, res.HeadingCounts)
+
+	assert.Len(t, res.HeadingOutline, 2)
+	guide := res.HeadingOutline[0]
+	assert.Equal(t, "Guide", guide.Text)
+	assert.Len(t, guide.Children, 3)
+	install := guide.Children[0]
+	assert.Equal(t, "Install", install.Text)
+	assert.Len(t, install.Children, 2)
+	assert.Equal(t, "Linux", install.Children[0].Text)
+	assert.Equal(t, "Windows", install.Children[1].Text)
+
+	rules := make(map[string]int)
+	for _, finding := range res.HeadingFindings {
+		rules[finding.Rule]++
+	}
+	assert.Equal(t, map[string]int{
+		"heading-multiple-h1":   1,
+		"heading-skipped-level": 1,
+		"heading-empty":         1,
+		"heading-too-long":      1,
+	}, rules)
+}
+
+func TestAuditHeadings_MissingH1(t *testing.T) {
+	headings := []response.Heading{{Tag: "h2", Level: 2, Text: "Only h2"}}
+
+	findings := analyze.AuditHeadings(headings, analyze.CountHeadings(headings))
+
+	assert.Len(t, findings, 1)
+	assert.Equal(t, "heading-missing-h1", findings[0].Rule)
+}
@@ -2,7 +2,9 @@ package test
 
 import (
 	"api/analyze"
+	"api/constant"
 	"api/response"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -23,3 +25,115 @@ func TestAnalyzeHtmlLoginForm(t *testing.T) {
 	analyzer.Analyze(&wc, &res)
 	assert.Equal(t, true, res.HasLogin, "Web Page Login from analyze testing...")
 }
+
+func analyzeAuthForms(htmlContent, pageURL string) *response.SuccessResponse {
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: pageURL}
+	res := &response.SuccessResponse{}
+	analyze.NewHtmlLoginFormAnalyzer().Analyze(wc, res)
+	return res
+}
+
+func TestAnalyzeHtmlLoginForm_BareButtonAndDetails(t *testing.T) {
+	htmlContent := `<html><body><div>
+		<form action="http://example.com/session" method="post">
+			<input type="email" name="email" autocomplete="username">
+			<input type="password" name="pwd" autocomplete="current-password">
+			<input type="hidden" name="csrf" value="x">
+			<button>Sign in</button>
+			<a href="/forgot">Forgot password?</a>
+		</form>
+		<a href="https://accounts.google.com/o/oauth2/auth">Continue</a>
+		<button>Sign in with GitHub</button>
+	</div></body></html>`
+
+	res := analyzeAuthForms(htmlContent, "https://example.com/login")
+
+	assert.True(t, res.HasLogin)
+	assert.Len(t, res.AuthForms, 1)
+	form := res.AuthForms[0]
+	assert.Equal(t, constant.AUTH_LOGIN, form.Type)
+	assert.Equal(t, 100, form.Confidence)
+	assert.Equal(t, "POST", form.Method)
+	assert.Equal(t, "http://example.com/session", form.Action)
+	assert.Equal(t, []string{"email", "pwd"}, form.Fields)
+	assert.True(t, form.InForm)
+	assert.True(t, form.InsecureSubmit)
+	assert.True(t, form.HasSso)
+	assert.Equal(t, []string{"GitHub", "Google"}, form.SsoProviders)
+}
+
+func TestAnalyzeHtmlLoginForm_FacebookDialog(t *testing.T) {
+	htmlContent := `<html><body><div>
+		<form action="/login" method="post"><input type="text" name="user"><input type="password" name="pass">
+			<button type="submit">Log in</button></form>
+		<a href="https://www.facebook.com/v19.0/dialog/oauth?client_id=1">Continue</a>
+	</div><div>
+		<form action="/login" method="post"><input type="text" name="user"><input type="password" name="pass">
+			<button type="submit">Log in</button></form>
+		<a href="https://www.facebook.com/videos/">Watch</a>
+	</div></body></html>`
+
+	res := analyzeAuthForms(htmlContent, "https://example.com/")
+
+	assert.Len(t, res.AuthForms, 2)
+	assert.Equal(t, []string{"Facebook"}, res.AuthForms[0].SsoProviders)
+	assert.False(t, res.AuthForms[1].HasSso, "a facebook.com page is not the OAuth dialog")
+}
+
+func TestAnalyzeHtmlLoginForm_SignUpAndReset(t *testing.T) {
+	htmlContent := `<html><body>
+		<form action="/register"><input type="text" name="username"><input type="password" name="password" autocomplete="new-password">
+			<input type="password" name="confirm"><button type="submit">Create account</button></form>
+		<form action="/reset"><input type="email" name="email"><button>Reset password</button></form>
+	</body></html>`
+
+	res := analyzeAuthForms(htmlContent, "https://example.com/")
+
+	assert.False(t, res.HasLogin)
+	assert.Len(t, res.AuthForms, 2)
+	assert.Equal(t, constant.AUTH_SIGNUP, res.AuthForms[0].Type)
+	assert.Equal(t, "https://example.com/register", res.AuthForms[0].Action)
+	assert.Equal(t, "GET", res.AuthForms[0].Method)
+	assert.False(t, res.AuthForms[0].InsecureSubmit)
+	assert.Equal(t, constant.AUTH_PASSWORD_RESET, res.AuthForms[1].Type)
+}
+
+func TestAnalyzeHtmlLoginForm_MultiStepAndOrphanPassword(t *testing.T) {
+	stepContent := `<html><body><form action="/identifier"><input type="text" name="identifier" autocomplete="username"><button>Next</button></form></body></html>`
+	stepRes := analyzeAuthForms(stepContent, "https://example.com/")
+
+	assert.True(t, stepRes.HasLogin)
+	assert.Len(t, stepRes.AuthForms, 1)
+	assert.Equal(t, constant.AUTH_LOGIN_STEP, stepRes.AuthForms[0].Type)
+
+	orphanContent := `<html><body><div id="login"><input type="text" name="user"><input type="password" name="pass"></div></body></html>`
+	orphanRes := analyzeAuthForms(orphanContent, "https://example.com/")
+
+	assert.True(t, orphanRes.HasLogin)
+	assert.Len(t, orphanRes.AuthForms, 1)
+	assert.False(t, orphanRes.AuthForms[0].InForm)
+	assert.Equal(t, []string{"user", "pass"}, orphanRes.AuthForms[0].Fields)
+}
+
+func TestAnalyzeHtmlLoginForm_SearchFormIsIgnored(t *testing.T) {
+	htmlContent := `<html><body><form action="/search"><input type="text" name="q"><button>Search</button></form></body></html>`
+
+	res := analyzeAuthForms(htmlContent, "https://example.com/")
+
+	assert.False(t, res.HasLogin)
+	assert.Empty(t, res.AuthForms)
+}
+
+func TestAnalyzeHtmlLoginForm_DecodesCharset(t *testing.T) {
+	// windows-1252 bytes, 0xE9 is é
+	htmlContent := "<html><body><form action=\"/connexion\" method=\"post\"><input type=\"text\" name=\"utilisateur\">" +
+		"<input type=\"password\" name=\"mot_de_passe_\xe9\"><button type=\"submit\">Se connecter</button></form></body></html>"
+	headers := http.Header{"Content-Type": []string{"text/html; charset=windows-1252"}}
+	wc := &response.WebContent{Content: htmlContent, Headers: headers, FinalUrl: "https://example.fr/"}
+	res := &response.SuccessResponse{}
+
+	analyze.NewHtmlLoginFormAnalyzer().Analyze(wc, res)
+
+	assert.Len(t, res.AuthForms, 1)
+	assert.Equal(t, []string{"utilisateur", "mot_de_passe_é"}, res.AuthForms[0].Fields)
+}
@@ -153,3 +153,20 @@ func TestHtmlUrlLinkAnalyzer_Analyze_Integration(t *testing.T) {
 	assert.Nil(t, errEmptyParse, "Expected no error for empty HTML content as html.Parse is tolerant")
 	assert.Empty(t, resEmpty.Urls, "Expected no URLs to be found in an empty document")
 }
+
+func TestHtmlUrlLinkAnalyzer_Analyze_LinkProbeDisabled(t *testing.T) {
+	configs.GetConfig().LinkProbe = false
+	defer func() { configs.GetConfig().LinkProbe = true }()
+
+	wc := &response.WebContent{Content: `<html><body><a href="/page1">Internal</a><a href="http://external.invalid/">External</a></body></html>`}
+	res := &response.SuccessResponse{BasePath: "http://example.com"}
+	analyzer := analyze.NewHtmlUrlLinkAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Urls, 2)
+	assert.Equal(t, constant.INTERNAL, res.Urls[0].Type)
+	assert.Equal(t, 0, res.Urls[0].Status)
+	assert.Equal(t, constant.EXTERNAL, res.Urls[1].Type)
+}
@@ -2,7 +2,9 @@ package test
 
 import (
 	"api/analyze"
+	"api/constant"
 	"api/response"
+	"net/http"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -18,3 +20,113 @@ func TestAnalyzeHtmlVersion(t *testing.T) {
 	analyzer.Analyze(&wc, &res)
 	assert.Equal(t, "HTML 5", res.HtmlVersion, "Web Page Version Testing...")
 }
+
+func TestAnalyzeHtmlVersionLegacyDoctypes(t *testing.T) {
+	tests := []struct {
+		doctype string
+		version string
+		mode    string
+	}{
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01//EN" "http://www.w3.org/TR/html4/strict.dtd">`, "HTML 4.01 Strict", constant.MODE_NO_QUIRKS},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN" "http://www.w3.org/TR/html4/loose.dtd">`, "HTML 4.01 Transitional", constant.MODE_LIMITED_QUIRKS},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 4.01 Transitional//EN">`, "HTML 4.01 Transitional", constant.MODE_QUIRKS},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML 1.0 Transitional//EN" "http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd">`, "XHTML 1.0 Transitional", constant.MODE_LIMITED_QUIRKS},
+		{`<!DOCTYPE html PUBLIC "-//W3C//DTD XHTML Basic 1.1//EN" "http://www.w3.org/TR/xhtml-basic/xhtml-basic11.dtd">`, "XHTML Basic 1.1", constant.MODE_NO_QUIRKS},
+		{`<!DOCTYPE HTML PUBLIC "-//W3C//DTD HTML 3.2 Final//EN">`, "HTML 3.2", constant.MODE_QUIRKS},
+		{`<!DOCTYPE html PUBLIC "-//IETF//DTD HTML 2.0//EN">`, "HTML 2.0", constant.MODE_QUIRKS},
+		{`<!doctype html system "about:legacy-compat">`, "HTML 5", constant.MODE_NO_QUIRKS},
+	}
+
+	for _, test := range tests {
+		wc := response.WebContent{Content: test.doctype + "\n<html><head><title>Legacy</title></head><body></body></html>"}
+		res := response.SuccessResponse{}
+		analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+		assert.Equal(t, test.version, res.HtmlVersion, test.doctype)
+		assert.Equal(t, test.mode, res.Doctype.Mode, test.doctype)
+		assert.True(t, res.Doctype.Present)
+	}
+}
+
+func TestAnalyzeHtmlVersionIdentifiers(t *testing.T) {
+	wc := response.WebContent{Content: "<!-- generated -->\n<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML 1.1//EN\" \"http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd\">\n<html xmlns=\"http://www.w3.org/1999/xhtml\"><body></body></html>"}
+	res := response.SuccessResponse{}
+	analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+
+	assert.Equal(t, "XHTML 1.1", res.HtmlVersion)
+	assert.Equal(t, "html", res.Doctype.Name)
+	assert.Equal(t, "-//W3C//DTD XHTML 1.1//EN", res.Doctype.PublicId)
+	assert.Equal(t, "http://www.w3.org/TR/xhtml11/DTD/xhtml11.dtd", res.Doctype.SystemId)
+	assert.Equal(t, 2, res.Doctype.Line)
+	assert.True(t, res.Doctype.XhtmlXmlns)
+	assert.False(t, res.Doctype.ServedAsXml)
+}
+
+func TestAnalyzeHtmlVersionServedAsXhtml(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Content-Type", "application/xhtml+xml; charset=utf-8")
+	wc := response.WebContent{
+		Content: "<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n<!DOCTYPE html PUBLIC \"-//W3C//DTD XHTML 1.0 Transitional//EN\" \"http://www.w3.org/TR/xhtml1/DTD/xhtml1-transitional.dtd\">\n<html xmlns=\"http://www.w3.org/1999/xhtml\"><body></body></html>",
+		Headers: headers,
+	}
+	res := response.SuccessResponse{}
+	analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+
+	assert.Equal(t, "XHTML 1.0 Transitional", res.HtmlVersion)
+	assert.Equal(t, "application/xhtml+xml", res.Doctype.ContentType)
+	assert.True(t, res.Doctype.ServedAsXml)
+	assert.Equal(t, constant.MODE_NO_QUIRKS, res.Doctype.Mode)
+	assert.Empty(t, res.Doctype.Findings)
+
+	headers.Set("Content-Type", "text/html")
+	res = response.SuccessResponse{}
+	analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+	assert.Equal(t, constant.MODE_LIMITED_QUIRKS, res.Doctype.Mode)
+	assert.True(t, hasRule(res.Doctype.Findings, "xhtml-served-as-html"))
+}
+
+func TestAnalyzeHtmlVersionMissingDoctype(t *testing.T) {
+	wc := response.WebContent{Content: "<html><body><!-- <!DOCTYPE html> --><p>Text</p><code>&lt;!DOCTYPE html&gt;</code></body></html>"}
+	res := response.SuccessResponse{}
+	analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+
+	assert.Empty(t, res.HtmlVersion)
+	assert.False(t, res.Doctype.Present)
+	assert.Equal(t, constant.MODE_QUIRKS, res.Doctype.Mode)
+	assert.True(t, hasRule(res.Doctype.Findings, "doctype-missing"))
+
+	wc = response.WebContent{Content: "<svg xmlns=\"http://www.w3.org/2000/svg\"><circle r=\"4\"/></svg>"}
+	res = response.SuccessResponse{}
+	analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+	assert.Equal(t, "SVG", res.HtmlVersion)
+	assert.False(t, hasRule(res.Doctype.Findings, "doctype-missing"))
+}
+
+func TestAnalyzeHtmlVersionMalformedDoctype(t *testing.T) {
+	tests := map[string]string{
+		"<!DOCTYPE>": constant.SEVERITY_ERROR,
+		"<!DOCTYPE html PUBLIC \"-//W3C//DTD HTML 4.01//EN>": constant.SEVERITY_ERROR,
+		"<!DOCTYPE html BOGUS>":                              constant.SEVERITY_ERROR,
+		"<!DOCTYPEhtml>":                                     constant.SEVERITY_WARNING,
+	}
+	for doctype, severity := range tests {
+		wc := response.WebContent{Content: doctype + "<html><body></body></html>"}
+		res := response.SuccessResponse{}
+		analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+
+		var found bool
+		for _, finding := range res.Doctype.Findings {
+			if finding.Rule == "doctype-malformed" {
+				found = true
+				assert.Equal(t, severity, finding.Severity, doctype)
+			}
+		}
+		assert.True(t, found, doctype)
+	}
+
+	wc := response.WebContent{Content: "<p>Intro</p><!DOCTYPE html><html><body></body></html>"}
+	res := response.SuccessResponse{}
+	analyze.NewHtmlVersionAnalyzer().Analyze(&wc, &res)
+	assert.False(t, res.Doctype.Present)
+	assert.True(t, hasRule(res.Doctype.Findings, "doctype-misplaced"))
+	assert.True(t, hasRule(res.Doctype.Findings, "doctype-missing"))
+}
@@ -0,0 +1,160 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"bytes"
+	"fmt"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestImageAnalyzer_Analyze_ProbesAndAudits(t *testing.T) {
+	var small, large bytes.Buffer
+	png.Encode(&small, image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	png.Encode(&large, image.NewRGBA(image.Rect(0, 0, 10, 10)))
+	large.Write(make([]byte, 300*1024))
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body []byte
+		switch r.URL.Path {
+		case "/hero.png", "/bg.png":
+			body = small.Bytes()
+		case "/large.png":
+			body = large.Bytes()
+		case "/hero.webp":
+			body = []byte("RIFF")
+		default:
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	htmlContent := `<html><head><style>.banner { background-image: url("/bg.png"); }</style></head><body>
+		<picture>
+			<source type="image/webp" srcset="/hero.webp 1x">
+			<img src="/hero.png" width="10" height="10" alt="Hero">
+		</picture>
+		<img src="/large.png" alt="Large">
+		<img src="/missing.png" width="1" height="1" loading="lazy">
+		<img src="/below.png" srcset="/below-2x.png 2x" width="1" height="1">
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: server.URL + "/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewImageAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	report := res.Images
+	assert.Equal(t, 7, report.Count)
+	assert.Equal(t, constant.IMAGE_SOURCE_BACKGROUND, report.Images[0].Source)
+	assert.Equal(t, constant.IMAGE_SOURCE_PICTURE, report.Images[1].Source)
+	assert.Equal(t, constant.IMAGE_SOURCE_SRCSET, report.Images[6].Source)
+
+	hero := report.Images[2]
+	assert.Equal(t, "png", hero.Format)
+	assert.Empty(t, hero.Findings)
+
+	largeImage := report.Images[3]
+	assert.Equal(t, int64(large.Len()), largeImage.Probe.ContentLength)
+	assert.True(t, hasRule(largeImage.Findings, "image-oversized"))
+	assert.True(t, hasRule(largeImage.Findings, "image-missing-dimensions"))
+	assert.True(t, hasRule(largeImage.Findings, "image-legacy-format"))
+
+	missing := report.Images[4]
+	assert.True(t, hasRule(missing.Findings, "image-broken"))
+	assert.True(t, hasRule(missing.Findings, "image-lazy-above-fold"))
+
+	below := report.Images[5]
+	assert.True(t, hasRule(below.Findings, "image-not-lazy"))
+	assert.Equal(t, int64(2*small.Len()+large.Len()+4), report.TotalBytes)
+}
+
+func TestImageAnalyzer_Analyze_ConcurrentProbes(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		requests.Add(1)
+		w.Header().Set("Content-Type", "image/png")
+		w.Write([]byte("png"))
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	// enough images for the probes to overlap, each referenced twice
+	var body strings.Builder
+	for i := 0; i < 100; i++ {
+		fmt.Fprintf(&body, `<img src="/%[1]d.png" width="1" height="1"><img src="/%[1]d.png" width="1" height="1">`, i)
+	}
+	wc := &response.WebContent{Content: "<html><body>" + body.String() + "</body></html>", FinalUrl: server.URL + "/"}
+	res := &response.SuccessResponse{}
+
+	err := analyze.NewImageAnalyzer().Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(100), requests.Load(), "each distinct image is probed once")
+	assert.Len(t, res.Images.Images, 200)
+	for _, img := range res.Images.Images {
+		assert.NotNil(t, img.Probe, img.Url)
+	}
+}
+
+func TestImageAnalyzer_Analyze_WithoutProbe(t *testing.T) {
+	configs.GetConfig().LinkProbe = false
+	defer func() { configs.GetConfig().LinkProbe = true }()
+
+	htmlContent := `<html><body>
+		<div style="background: #fff url('hero.jpg') no-repeat"></div>
+		<img src="data:image/svg+xml;base64,PHN2Zz4=" width="1" height="1">
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://example.com/page/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewImageAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Len(t, res.Images.Images, 2)
+	assert.Equal(t, "https://example.com/page/hero.jpg", res.Images.Images[0].Url)
+	assert.Equal(t, "jpeg", res.Images.Images[0].Format)
+	assert.Nil(t, res.Images.Images[0].Probe)
+	assert.Equal(t, "svg", res.Images.Images[1].Format)
+	assert.Empty(t, res.Images.Findings)
+}
+
+func TestAuditImages_EagerLimit(t *testing.T) {
+	htmlContent := `<img src="/a.webp" width="1" height="1"><img src="/b.webp" width="1" height="1">`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+	report := &response.ImageReport{Images: analyze.ExtractImages(doc, "https://example.com/")}
+
+	analyze.AuditImages(doc, "https://example.com/", report, 0, 1)
+
+	assert.Empty(t, report.Images[0].Findings)
+	assert.True(t, hasRule(report.Images[1].Findings, "image-not-lazy"))
+}
@@ -0,0 +1,74 @@
+package test
+
+import (
+	"api/analyze"
+	"api/response"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestMixedContentAnalyzer_Analyze_HttpsPage(t *testing.T) {
+	htmlContent := "<html><head>\n" +
+		"<script src=\"http://cdn.example.com/app.js\"></script>\n" +
+		"<link rel=\"stylesheet\" href=\"http://cdn.example.com/site.css\">\n" +
+		"<link rel=\"icon\" href=\"http://cdn.example.com/favicon.ico\">\n" +
+		"</head><body>\n" +
+		"  <img src=\"http://img.example.com/a.png\" srcset=\"http://img.example.com/a2.png 2x, /a3.png 3x\">\n" +
+		"  <img src=\"/local.png\"><iframe src=\"//frames.example.com/embed\"></iframe>\n" +
+		"  <form action=\"http://example.com/login\"></form>\n" +
+		"  <a href=\"http://example.com/plain-link\">not a subresource</a>\n" +
+		"</body></html>"
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://example.com/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewMixedContentAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.True(t, res.MixedContent.HttpsPage)
+	assert.Equal(t, 3, res.MixedContent.ActiveCount)
+	assert.Equal(t, 3, res.MixedContent.PassiveCount)
+
+	script := res.MixedContent.Active[0]
+	assert.Equal(t, "script", script.Tag)
+	assert.Equal(t, "src", script.Attribute)
+	assert.Equal(t, "http://cdn.example.com/app.js", script.Url)
+	assert.Equal(t, 2, script.Line)
+	assert.Equal(t, 1, script.Column)
+
+	form := res.MixedContent.Active[2]
+	assert.Equal(t, "form", form.Tag)
+	assert.Equal(t, "action", form.Attribute)
+	assert.Equal(t, 8, form.Line)
+	assert.Equal(t, 3, form.Column)
+
+	assert.Equal(t, "link", res.MixedContent.Passive[0].Tag)
+	assert.Equal(t, "http://img.example.com/a2.png", res.MixedContent.Passive[2].Url)
+	assert.Equal(t, "srcset", res.MixedContent.Passive[2].Attribute)
+}
+
+func TestMixedContentAnalyzer_Analyze_HttpPage(t *testing.T) {
+	wc := &response.WebContent{Content: `<script src="http://cdn.example.com/app.js"></script>`, FinalUrl: "http://example.com/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewMixedContentAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.False(t, res.MixedContent.HttpsPage)
+	assert.Empty(t, res.MixedContent.Active)
+}
+
+func TestFindMixedContent_BaseHref(t *testing.T) {
+	htmlContent := `<html><head><base href="http://static.example.com/"></head><body><script src="app.js"></script><img src="https://secure.example.com/a.png"></body></html>`
+	report := &response.MixedContentReport{HttpsPage: true}
+
+	analyze.FindMixedContent(html.NewTokenizer(strings.NewReader(htmlContent)), "https://example.com/", report)
+
+	assert.Equal(t, 1, report.ActiveCount)
+	assert.Equal(t, "http://static.example.com/app.js", report.Active[0].Url)
+	assert.Equal(t, 0, report.PassiveCount)
+}
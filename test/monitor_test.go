@@ -0,0 +1,364 @@
+package test
+
+import (
+	"api/app/handler"
+	"api/configs"
+	"api/constant"
+	"api/monitor"
+	"api/response"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const MONITORS_PATH = "/api/v1/monitors"
+
+func TestParseSchedule(t *testing.T) {
+	base := time.Date(2025, time.January, 31, 10, 7, 30, 0, time.UTC) // a Friday
+
+	tests := []struct {
+		spec string
+		next time.Time
+	}{
+		{"@every 90m", base.Add(90 * time.Minute)},
+		{"*/15 * * * *", time.Date(2025, time.January, 31, 10, 15, 0, 0, time.UTC)},
+		{"0 9-17 * * 1-5", time.Date(2025, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"30 6 * * 0", time.Date(2025, time.February, 2, 6, 30, 0, 0, time.UTC)},
+		{"30 6 * * 7", time.Date(2025, time.February, 2, 6, 30, 0, 0, time.UTC)},
+		{"0 0 29 2 *", time.Date(2028, time.February, 29, 0, 0, 0, 0, time.UTC)},
+		{"0 12 1,15 * *", time.Date(2025, time.February, 1, 12, 0, 0, 0, time.UTC)},
+		// a restricted day of month and day of week match either
+		{"0 0 15 * 1", time.Date(2025, time.February, 3, 0, 0, 0, 0, time.UTC)},
+		{"@daily", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+		{"@hourly", time.Date(2025, time.January, 31, 11, 0, 0, 0, time.UTC)},
+		{"@monthly", time.Date(2025, time.February, 1, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		schedule, err := monitor.ParseSchedule(tt.spec)
+		assert.NoError(t, err, tt.spec)
+		assert.Equal(t, tt.next, schedule.Next(base), tt.spec)
+	}
+
+	schedule, _ := monitor.ParseSchedule("0 0 31 2 *")
+	assert.True(t, schedule.Next(base).IsZero(), "February 31st never comes")
+
+	for _, spec := range []string{"", "@every 10s", "@every soon", "* * * *", "60 * * * *", "* * 0 * *",
+		"5-1 * * * *", "*/0 * * * *", "a * * * *", "* * * 13 *", "@yearly"} {
+		_, err := monitor.ParseSchedule(spec)
+		assert.Error(t, err, spec)
+	}
+}
+
+func TestEvaluateRun(t *testing.T) {
+	days := func(n int) *int { return &n }
+	healthy := &response.MonitorRun{
+		Reachable: true, Title: "Shop", HasLogin: true, BrokenLinks: []string{"https://shop.example.com/old"},
+		CertDaysLeft: days(60),
+	}
+	down := &response.MonitorRun{Reachable: false, Error: "page returned status 503"}
+
+	// the first run only alerts on reachability and the certificate
+	assert.Empty(t, monitor.EvaluateRun(healthy, nil, nil, 14))
+	alerts := monitor.EvaluateRun(down, nil, nil, 14)
+	assert.Equal(t, constant.ALERT_UNREACHABLE, alerts[0].Rule)
+	assert.Equal(t, "Page is unreachable: page returned status 503", alerts[0].Message)
+
+	// an outage is alerted once, and its end
+	assert.Len(t, monitor.EvaluateRun(down, healthy, healthy, 14), 1)
+	assert.Empty(t, monitor.EvaluateRun(down, down, healthy, 14))
+	alerts = monitor.EvaluateRun(healthy, down, healthy, 14)
+	assert.Len(t, alerts, 1)
+	assert.Equal(t, constant.ALERT_RECOVERED, alerts[0].Rule)
+
+	degraded := &response.MonitorRun{
+		Reachable: true, Title: "Shop - Maintenance", HasLogin: false, CertDaysLeft: days(10),
+		BrokenLinks: []string{"https://shop.example.com/old", "https://shop.example.com/cart", "https://shop.example.com/help"},
+	}
+	alerts = monitor.EvaluateRun(degraded, healthy, healthy, 14)
+	rules := make(map[string]response.Finding)
+	for _, alert := range alerts {
+		rules[alert.Rule] = alert
+	}
+	assert.Len(t, alerts, 4)
+	assert.Equal(t, "2 new broken links: https://shop.example.com/cart, https://shop.example.com/help",
+		rules[constant.ALERT_BROKEN_LINKS].Message)
+	assert.Equal(t, `Title changed from "Shop" to "Shop - Maintenance"`, rules[constant.ALERT_TITLE_CHANGED].Message)
+	assert.Equal(t, constant.SEVERITY_ERROR, rules[constant.ALERT_LOGIN_LOST].Severity)
+	assert.Equal(t, "Certificate expires in 10 days", rules[constant.ALERT_CERT_EXPIRING].Message)
+
+	// the same state is not alerted again
+	assert.Empty(t, monitor.EvaluateRun(degraded, degraded, degraded, 14))
+	expired := *degraded
+	expired.CertDaysLeft = days(-1)
+	assert.Empty(t, monitor.EvaluateRun(&expired, degraded, degraded, 14))
+	alerts = monitor.EvaluateRun(&expired, nil, nil, 14)
+	assert.Equal(t, "Certificate has expired", alerts[0].Message)
+}
+
+// fakeAnalyzer returns the queued results in turn, and an unreachable page when they run out.
+type fakeAnalyzer struct {
+	mu      sync.Mutex
+	results []*response.SuccessResponse
+}
+
+func (f *fakeAnalyzer) analyze(string) (*response.SuccessResponse, *response.ErrorResponse) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if len(f.results) == 0 {
+		errResp := response.ErrorResponseMsg("Error occurred while call web page url", "connection refused", http.StatusBadRequest)
+		return nil, &errResp
+	}
+	res := f.results[0]
+	f.results = f.results[1:]
+	return res, nil
+}
+
+func TestMonitorSchedulerRuns(t *testing.T) {
+	var mu sync.Mutex
+	var delivered []response.MonitorAlert
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var alert response.MonitorAlert
+		json.NewDecoder(r.Body).Decode(&alert)
+		mu.Lock()
+		delivered = append(delivered, alert)
+		mu.Unlock()
+	}))
+	defer webhook.Close()
+
+	statePath := filepath.Join(t.TempDir(), "monitors.json")
+	fake := &fakeAnalyzer{results: []*response.SuccessResponse{
+		{Title: "Shop", HasLogin: true, PageStatus: 200, ReportId: "first",
+			Urls: []response.Url{{Url: "https://shop.example.com/cart", Status: 200}}},
+		{Title: "Shop", HasLogin: false, PageStatus: 200,
+			Urls: []response.Url{{Url: "https://shop.example.com/cart", Status: 404}, {Url: "https://shop.example.com/cart", Status: 404}}},
+	}}
+	scheduler, err := monitor.NewScheduler(statePath, 2, 14, fake.analyze)
+	assert.NoError(t, err)
+
+	created, err := scheduler.Create(monitor.MonitorInput{
+		Url: "https://shop.example.com/", Schedule: "@every 5m", Webhook: webhook.URL,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 14, created.CertExpiryDays, "the configured default applies")
+	assert.NotNil(t, created.NextRun)
+	assert.WithinDuration(t, time.Now().Add(5*time.Minute), *created.NextRun, time.Minute)
+
+	run, err := scheduler.Run(created.Id)
+	assert.NoError(t, err)
+	assert.True(t, run.Reachable)
+	assert.Equal(t, "first", run.ReportId)
+	assert.Empty(t, run.Alerts)
+	assert.Empty(t, run.AlertDelivery)
+
+	run, err = scheduler.Run(created.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"https://shop.example.com/cart"}, run.BrokenLinks)
+	assert.Len(t, run.Alerts, 2)
+	assert.Equal(t, "webhook", run.AlertDelivery)
+
+	run, err = scheduler.Run(created.Id)
+	assert.NoError(t, err)
+	assert.False(t, run.Reachable)
+	assert.Equal(t, "Error occurred while call web page url: connection refused", run.Error)
+	assert.Equal(t, constant.ALERT_UNREACHABLE, run.Alerts[0].Rule)
+
+	mu.Lock()
+	assert.Len(t, delivered, 2)
+	assert.Equal(t, created.Id, delivered[0].MonitorId)
+	assert.Equal(t, "https://shop.example.com/", delivered[0].Url)
+	mu.Unlock()
+
+	// the runs are kept newest first, up to the history limit, and survive a restart
+	reloaded, err := monitor.NewScheduler(statePath, 2, 14, fake.analyze)
+	assert.NoError(t, err)
+	loaded, err := reloaded.Get(created.Id)
+	assert.NoError(t, err)
+	assert.Len(t, loaded.Runs, 2)
+	assert.False(t, loaded.Runs[0].Reachable)
+	assert.True(t, loaded.Runs[1].Reachable)
+	assert.Len(t, reloaded.List()[0].Runs, 1)
+
+	_, err = reloaded.Delete(created.Id)
+	assert.NoError(t, err)
+	_, err = reloaded.Run(created.Id)
+	assert.ErrorIs(t, err, monitor.ErrMonitorNotFound)
+	assert.Empty(t, reloaded.List())
+}
+
+func TestMonitorSchedulerWebhookFailure(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+
+	scheduler, err := monitor.NewScheduler(constant.EMPTY, 5, 14, (&fakeAnalyzer{}).analyze)
+	assert.NoError(t, err)
+	withWebhook, _ := scheduler.Create(monitor.MonitorInput{Url: "https://a.example.com/", Schedule: "@hourly", Webhook: webhook.URL})
+	withLog, _ := scheduler.Create(monitor.MonitorInput{Url: "https://b.example.com/", Schedule: "@hourly"})
+
+	run, err := scheduler.Run(withWebhook.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "log", run.AlertDelivery, "alerts fall back to the log")
+	assert.Equal(t, "webhook returned status 502", run.AlertError)
+
+	run, err = scheduler.Run(withLog.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "log", run.AlertDelivery)
+	assert.Empty(t, run.AlertError)
+}
+
+func TestMonitorSchedulerValidation(t *testing.T) {
+	scheduler, err := monitor.NewScheduler(constant.EMPTY, 5, 14, (&fakeAnalyzer{}).analyze)
+	assert.NoError(t, err)
+
+	for _, input := range []monitor.MonitorInput{
+		{Url: "ftp://example.com/", Schedule: "@hourly"},
+		{Url: "https://example.com/", Schedule: "every hour"},
+		{Url: "https://example.com/", Schedule: "@hourly", Webhook: "hooks.example.com"},
+		{Url: "https://example.com/", Schedule: "@hourly", CertExpiryDays: -1},
+	} {
+		_, err := scheduler.Create(input)
+		assert.ErrorIs(t, err, monitor.ErrInvalidMonitor, input)
+	}
+
+	paused, err := scheduler.Create(monitor.MonitorInput{Url: "https://example.com/", Schedule: "@hourly", Paused: true, CertExpiryDays: 30})
+	assert.NoError(t, err)
+	assert.Nil(t, paused.NextRun, "a paused monitor is not scheduled")
+	assert.Equal(t, 30, paused.CertExpiryDays)
+
+	_, err = scheduler.Update("missing", monitor.MonitorInput{Url: "https://example.com/", Schedule: "@hourly"})
+	assert.ErrorIs(t, err, monitor.ErrMonitorNotFound)
+	resumed, err := scheduler.Update(paused.Id, monitor.MonitorInput{Url: "https://example.com/", Schedule: "@daily"})
+	assert.NoError(t, err)
+	assert.NotNil(t, resumed.NextRun)
+	assert.Equal(t, "@daily", resumed.Schedule)
+
+	scheduler.Start()
+	scheduler.Start()
+	scheduler.Stop()
+	scheduler.Stop()
+}
+
+// monitorRequest calls a monitor handler with an optional JSON body and returns the recorded response.
+func monitorRequest(handlerFunc gin.HandlerFunc, method, target, body string, params gin.Params) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	var reader io.Reader
+	if body != constant.EMPTY {
+		reader = strings.NewReader(body)
+	}
+	c.Request = httptest.NewRequest(method, target, reader)
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = params
+	handlerFunc(c)
+	return w
+}
+
+func TestMonitorHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var healthy atomic.Bool
+	healthy.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !healthy.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Maintenance</title></head><body></body></html>`)
+			return
+		}
+		fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Status</title></head><body><h1>Up</h1></body></html>`)
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	scheduler, err := monitor.NewScheduler(constant.EMPTY, 10, 14, handler.AnalyzePage)
+	assert.NoError(t, err)
+	previous := handler.SetMonitorScheduler(scheduler)
+	defer handler.SetMonitorScheduler(previous)
+
+	w := monitorRequest(handler.MonitorCreateHandler, http.MethodPost, MONITORS_PATH,
+		fmt.Sprintf(`{"url":%q,"schedule":"*/5 * * * *"}`, server.URL+"/"), nil)
+	assert.Equal(t, http.StatusCreated, w.Code, w.Body.String())
+	var created map[string]response.Monitor
+	json.Unmarshal(w.Body.Bytes(), &created)
+	id := created[constant.RESPONSE].Id
+	params := gin.Params{{Key: constant.ID, Value: id}}
+
+	// runs go through the analyze executor
+	w = monitorRequest(handler.MonitorRunHandler, http.MethodPost, MONITORS_PATH+"/"+id+"/run", constant.EMPTY, params)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var run map[string]response.MonitorRun
+	json.Unmarshal(w.Body.Bytes(), &run)
+	assert.True(t, run[constant.RESPONSE].Reachable)
+	assert.Equal(t, "Status", run[constant.RESPONSE].Title)
+	assert.Equal(t, http.StatusOK, run[constant.RESPONSE].PageStatus)
+
+	healthy.Store(false)
+	w = monitorRequest(handler.MonitorRunHandler, http.MethodPost, MONITORS_PATH+"/"+id+"/run", constant.EMPTY, params)
+	json.Unmarshal(w.Body.Bytes(), &run)
+	assert.False(t, run[constant.RESPONSE].Reachable)
+	assert.Equal(t, "page returned status 503", run[constant.RESPONSE].Error)
+	assert.Equal(t, constant.ALERT_UNREACHABLE, run[constant.RESPONSE].Alerts[0].Rule)
+	assert.Equal(t, "log", run[constant.RESPONSE].AlertDelivery)
+
+	w = monitorRequest(handler.MonitorRunsHandler, http.MethodGet, MONITORS_PATH+"/"+id+"/runs?limit=1", constant.EMPTY, params)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var runs map[string][]response.MonitorRun
+	json.Unmarshal(w.Body.Bytes(), &runs)
+	assert.Len(t, runs[constant.RESPONSE], 1)
+	assert.False(t, runs[constant.RESPONSE][0].Reachable)
+
+	w = monitorRequest(handler.MonitorRunsHandler, http.MethodGet, MONITORS_PATH+"/"+id+"/runs?limit=none", constant.EMPTY, params)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = monitorRequest(handler.MonitorHandler, http.MethodGet, MONITORS_PATH+"/"+id, constant.EMPTY, params)
+	var found map[string]response.Monitor
+	json.Unmarshal(w.Body.Bytes(), &found)
+	assert.Len(t, found[constant.RESPONSE].Runs, 2)
+
+	w = monitorRequest(handler.MonitorListHandler, http.MethodGet, MONITORS_PATH, constant.EMPTY, nil)
+	var list map[string]response.MonitorList
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, 1, list[constant.RESPONSE].Count)
+	assert.Len(t, list[constant.RESPONSE].Monitors[0].Runs, 1)
+
+	w = monitorRequest(handler.MonitorUpdateHandler, http.MethodPut, MONITORS_PATH+"/"+id,
+		fmt.Sprintf(`{"url":%q,"schedule":"@daily","paused":true}`, server.URL+"/"), params)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	json.Unmarshal(w.Body.Bytes(), &found)
+	assert.True(t, found[constant.RESPONSE].Paused)
+	assert.Nil(t, found[constant.RESPONSE].NextRun)
+
+	w = monitorRequest(handler.MonitorUpdateHandler, http.MethodPut, MONITORS_PATH+"/"+id, `{"url":"x","schedule":"@daily"}`, params)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = monitorRequest(handler.MonitorDeleteHandler, http.MethodDelete, MONITORS_PATH+"/"+id, constant.EMPTY, params)
+	assert.Equal(t, http.StatusOK, w.Code)
+	w = monitorRequest(handler.MonitorHandler, http.MethodGet, MONITORS_PATH+"/"+id, constant.EMPTY, params)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "monitor not found")
+}
+
+func TestMonitorCreateHandlerInvalidBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	scheduler, _ := monitor.NewScheduler(constant.EMPTY, 10, 14, handler.AnalyzePage)
+	previous := handler.SetMonitorScheduler(scheduler)
+	defer handler.SetMonitorScheduler(previous)
+
+	for _, body := range []string{`{"url":`, `{"url":"https://example.com/","schedule":"sometimes"}`} {
+		w := monitorRequest(handler.MonitorCreateHandler, http.MethodPost, MONITORS_PATH, body, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+	}
+}
@@ -0,0 +1,114 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func findTracker(report *response.PrivacyReport, name string) *response.TrackerInfo {
+	for i := range report.Trackers {
+		if report.Trackers[i].Name == name {
+			return &report.Trackers[i]
+		}
+	}
+	return nil
+}
+
+func TestPrivacyAnalyzer_Analyze_TrackersWithoutConsent(t *testing.T) {
+	htmlContent := `<html><head>
+		<script src="https://www.google-analytics.com/analytics.js"></script>
+		<script>(function(h,o,t,j,a,r){r.src='https://static.hotjar.com/c/hotjar-'+h._hjSettings.hjid+'.js';})(window,document);</script>
+		<link rel="preconnect" href="https://connect.facebook.net">
+	</head><body>
+		<img src="https://www.facebook.com/tr?id=1&ev=PageView" width="1" height="1">
+		<a href="https://www.facebook.com/acme">Follow us</a>
+		<iframe src="https://www.youtube.com/embed/abc"></iframe>
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://shop.example.com/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewPrivacyAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	report := res.Privacy
+	assert.Len(t, report.Trackers, 4)
+	hotjar := findTracker(report, "Hotjar")
+	if assert.NotNil(t, hotjar) {
+		assert.Equal(t, "session_replay", hotjar.Category)
+		assert.Equal(t, []string{"inline-script"}, hotjar.Tags)
+	}
+	pixel := findTracker(report, "Facebook Pixel")
+	if assert.NotNil(t, pixel) {
+		assert.Equal(t, []string{"img"}, pixel.Tags)
+		assert.False(t, pixel.Gated)
+	}
+	assert.NotNil(t, findTracker(report, "YouTube Embed"))
+	assert.Empty(t, report.ConsentPlatforms)
+
+	assert.Equal(t, 4, report.Summary.TrackerCount)
+	assert.Equal(t, 4, report.Summary.UngatedCount)
+	assert.Equal(t, 1, report.Summary.Categories["advertising"])
+	assert.Equal(t, []string{"Google", "Hotjar", "Meta"}, report.Summary.Companies)
+	assert.Equal(t, constant.PRIVACY_EXPOSED, report.Summary.Rating)
+	assert.True(t, hasRule(report.Findings, "consent-platform-missing"))
+	assert.True(t, hasRule(report.Findings, "tracker-without-consent"))
+	assert.True(t, hasRule(report.Findings, "session-replay"))
+}
+
+func TestAnalyzePrivacy_ConsentGate(t *testing.T) {
+	htmlContent := `<html><head>
+		<script src="https://consent.cookiebot.com/uc.js" data-cbid="abc"></script>
+		<script>gtag('consent', 'default', {'ad_storage': 'denied'});</script>
+		<script src="https://www.googletagmanager.com/gtag/js?id=G-1"></script>
+		<script type="text/plain" data-cookieconsent="marketing" src="https://connect.facebook.net/en_US/fbevents.js"></script>
+	</head></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+	list, _ := analyze.ParseTrackerList([]byte(`{"trackers": [
+		{"name": "Google Tag Manager", "company": "Google", "category": "analytics", "domains": ["googletagmanager.com"]},
+		{"name": "Facebook Pixel", "company": "Meta", "category": "advertising", "domains": ["connect.facebook.net"]}
+	], "consentPlatforms": [{"name": "Cookiebot", "domains": ["consent.cookiebot.com"]}]}`))
+
+	report := analyze.AnalyzePrivacy(doc, htmlContent, "https://shop.example.com/", list)
+
+	assert.Equal(t, []string{"Cookiebot"}, report.ConsentPlatforms)
+	assert.True(t, report.ConsentMode)
+	assert.Len(t, report.Trackers, 2)
+	assert.True(t, report.Trackers[0].Gated)
+	assert.True(t, report.Trackers[1].Gated)
+	assert.Equal(t, constant.PRIVACY_GATED, report.Summary.Rating)
+	assert.Empty(t, report.Findings)
+}
+
+func TestPrivacyAnalyzer_Analyze_TrackerListFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "trackers.json")
+	os.WriteFile(path, []byte(`{"trackers": [{"name": "Acme Beacon", "company": "Acme", "category": "analytics",
+		"domains": ["beacon.acme.io/collect"]}]}`), 0o600)
+	configs.GetConfig().TrackerList = path
+	defer func() { configs.GetConfig().TrackerList = "" }()
+
+	htmlContent := `<img src="https://beacon.acme.io/collect?p=1"><img src="https://beacon.acme.io/logo.png">
+		<script src="https://www.google-analytics.com/analytics.js"></script>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://example.com/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewPrivacyAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	beacon := findTracker(res.Privacy, "Acme Beacon")
+	if assert.NotNil(t, beacon) {
+		assert.Equal(t, []string{"https://beacon.acme.io/collect?p=1"}, beacon.Urls)
+	}
+	assert.NotNil(t, findTracker(res.Privacy, "Google Analytics"))
+	assert.Equal(t, 2, res.Privacy.Summary.ThirdPartyHosts)
+}
@@ -0,0 +1,239 @@
+package test
+
+import (
+	"api/analyze"
+	"api/app/handler"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const COMPARE_PATH = "/api/v1/compare"
+
+func TestCompareReports(t *testing.T) {
+	base := &response.SuccessResponse{
+		ExecutedUrl: "https://www.example.com/",
+		BasePath:    "https://www.example.com",
+		HtmlVersion: "HTML 5",
+		Title:       "Example",
+		HasLogin:    true,
+		Headings: []response.Heading{
+			{Tag: "h1", Level: 1, Text: "Welcome"},
+			{Tag: "h2", Level: 2, Text: "Pricing"},
+			{Tag: "h2", Level: 2, Text: "Contact"},
+		},
+		Urls: []response.Url{
+			{Url: "https://www.example.com/about", Status: 200, UrlExecutionTime: 12},
+			{Url: "https://www.example.com/blog", Status: 200},
+			{Url: "https://www.example.com/shop", Status: 500},
+			{Url: "https://twitter.com/example", Status: 200},
+		},
+		Seo: &response.SeoMetadata{Description: "An example"},
+		Cookies: &response.CookieReport{Cookies: []response.CookieInfo{
+			{Name: "session", Secure: true},
+		}},
+		ServiceTime: 40,
+	}
+	target := &response.SuccessResponse{
+		ExecutedUrl: "https://staging.example.com/",
+		BasePath:    "https://staging.example.com",
+		HtmlVersion: "HTML 5",
+		Title:       "Example (staging)",
+		Headings: []response.Heading{
+			{Tag: "h2", Level: 2, Text: "Plans"},
+			{Tag: "h2", Level: 2, Text: "Contact"},
+			{Tag: "h3", Level: 3, Text: "FAQ"},
+		},
+		Urls: []response.Url{
+			{Url: "https://staging.example.com/about", Status: 404, UrlExecutionTime: 30},
+			{Url: "https://staging.example.com/shop", Status: 200},
+			{Url: "https://staging.example.com/new", Status: 410},
+			{Url: "https://twitter.com/example", Status: 200},
+		},
+		Seo: &response.SeoMetadata{Description: "An example"},
+		Cookies: &response.CookieReport{Cookies: []response.CookieInfo{
+			{Name: "session", Secure: false},
+		}},
+		ServiceTime: 90,
+	}
+
+	report := analyze.CompareReports(base, target)
+
+	assert.Equal(t, "https://www.example.com/", report.BaseUrl)
+	assert.Equal(t, "https://staging.example.com/", report.TargetUrl)
+	assert.ElementsMatch(t, []response.ValueChange{
+		{Path: "title", Kind: constant.CHANGE_CHANGED, Base: "Example", Target: "Example (staging)"},
+		{Path: "hasLogin", Kind: constant.CHANGE_CHANGED, Base: true, Target: false},
+	}, report.Changes)
+
+	assert.Equal(t, []response.Heading{{Tag: "h1", Level: 1, Text: "Welcome"}}, report.Headings.Removed)
+	assert.Equal(t, []response.Heading{{Tag: "h3", Level: 3, Text: "FAQ"}}, report.Headings.Added)
+	assert.Equal(t, []response.HeadingChange{{Tag: "h2", Base: "Pricing", Target: "Plans"}}, report.Headings.Changed)
+
+	// links of both sites compare by path, external links by URL
+	assert.Equal(t, []string{"/blog"}, report.Links.Removed)
+	assert.Equal(t, []string{"/new"}, report.Links.Added)
+	assert.ElementsMatch(t, []response.LinkStatusChange{
+		{Url: "/about", BaseStatus: 200, TargetStatus: 404},
+		{Url: "/shop", BaseStatus: 500, TargetStatus: 200},
+	}, report.Links.StatusChanged)
+	assert.ElementsMatch(t, []string{"/about", "/new"}, report.Links.NewlyBroken)
+	assert.Equal(t, []string{"/shop"}, report.Links.Fixed)
+
+	// the unchanged SEO section and the timings are not reported
+	assert.Len(t, report.Sections, 1)
+	assert.Equal(t, "cookies", report.Sections[0].Section)
+	assert.ElementsMatch(t, []string{constant.CHANGE_REMOVED, constant.CHANGE_ADDED},
+		[]string{report.Sections[0].Changes[0].Kind, report.Sections[0].Changes[1].Kind})
+	assert.Equal(t, "cookies.cookies[]", report.Sections[0].Changes[0].Path)
+
+	assert.Contains(t, report.Summary, `Title changed from "Example" to "Example (staging)"`)
+	assert.Contains(t, report.Summary, "Login form disappeared")
+	assert.Contains(t, report.Summary, `Lost h1 "Welcome"`)
+	assert.Contains(t, report.Summary, "2 new broken links")
+	assert.Contains(t, report.Summary, "1 fixed broken link")
+	assert.Contains(t, report.Summary, "cookies: 2 changes")
+}
+
+func TestCompareReportsWithoutDifferences(t *testing.T) {
+	base := &response.SuccessResponse{
+		ExecutedUrl: "https://www.example.com/",
+		BasePath:    "https://www.example.com",
+		Title:       "Example",
+		Urls:        []response.Url{{Url: "https://www.example.com/about", Status: 200, UrlExecutionTime: 5}},
+		Tls:         &response.TlsInfo{Version: "TLS 1.3", DaysRemaining: 40},
+		ServiceTime: 10,
+	}
+	target := &response.SuccessResponse{
+		ExecutedUrl:    "https://www.example.com/",
+		BasePath:       "https://www.example.com",
+		Title:          "Example",
+		Urls:           []response.Url{{Url: "https://www.example.com/about", Status: 200, UrlExecutionTime: 9}},
+		Tls:            &response.TlsInfo{Version: "TLS 1.3", DaysRemaining: 39},
+		AuthForms:      []response.AuthForm{},
+		ServiceTime:    20,
+		HeadingCounts:  map[string]int{"h1": 0},
+		HeadingOutline: []*response.HeadingNode{},
+	}
+
+	report := analyze.CompareReports(base, target)
+
+	assert.Empty(t, report.Changes)
+	assert.Empty(t, report.Sections)
+	assert.Empty(t, report.Links.StatusChanged)
+	assert.Equal(t, []string{"No differences"}, report.Summary)
+}
+
+func TestCompareSectionTruncated(t *testing.T) {
+	base := &response.SuccessResponse{}
+	target := &response.SuccessResponse{Images: &response.ImageReport{}}
+	for i := range 60 {
+		target.Forms = append(target.Forms, response.FormInfo{Action: fmt.Sprintf("/form/%d", i)})
+	}
+
+	report := analyze.CompareReports(base, target)
+
+	assert.Len(t, report.Sections, 2)
+	assert.Equal(t, "forms", report.Sections[0].Section)
+	assert.Len(t, report.Sections[0].Changes, 50)
+	assert.True(t, report.Sections[0].Truncated)
+	assert.Equal(t, "images", report.Sections[1].Section)
+	assert.Equal(t, constant.CHANGE_ADDED, report.Sections[1].Changes[0].Kind)
+	assert.Contains(t, report.Summary, "forms: more than 50 changes")
+}
+
+func TestCompareHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/production":
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Shop</title></head><body><h1>Shop</h1>
+				<a href="/cart">Cart</a></body></html>`)
+		case "/staging":
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Shop</title></head><body><h2>Shop</h2>
+				<a href="/cart">Cart</a><a href="/gone">Gone</a></body></html>`)
+		case "/cart":
+			fmt.Fprint(w, `<html></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, COMPARE_PATH+"?base="+url.QueryEscape(server.URL+"/production")+
+		"&target="+url.QueryEscape(server.URL+"/staging"), nil)
+	handler.CompareHandler(c)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body map[string]response.ComparisonReport
+	json.Unmarshal(w.Body.Bytes(), &body)
+	report := body[constant.RESPONSE]
+	assert.Equal(t, server.URL+"/production", report.BaseUrl)
+	assert.Equal(t, []response.Heading{{Tag: "h1", Level: 1, Text: "Shop"}}, report.Headings.Removed)
+	assert.Equal(t, []string{server.URL + "/gone"}, report.Links.Added)
+	assert.Contains(t, report.Summary, `Lost h1 "Shop"`)
+	assert.Equal(t, []string{server.URL + "/gone"}, report.Links.NewlyBroken)
+	assert.Contains(t, report.Summary, "1 new broken link")
+}
+
+func TestCompareHandlerMissingTarget(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, COMPARE_PATH+"?base=https://www.example.com", nil)
+	handler.CompareHandler(c)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), "Both base and target URLs are required")
+}
+
+func TestCompareReportsHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	request := handler.CompareRequest{
+		Base:   &response.SuccessResponse{Title: "Before", HtmlVersion: "HTML 4.01"},
+		Target: &response.SuccessResponse{Title: "After", HtmlVersion: "HTML 5"},
+	}
+	payload, _ := json.Marshal(request)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, COMPARE_PATH, strings.NewReader(string(payload)))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handler.CompareReportsHandler(c)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body map[string]response.ComparisonReport
+	json.Unmarshal(w.Body.Bytes(), &body)
+	assert.Equal(t, []string{
+		`HTML version changed from HTML 4.01 to HTML 5`,
+		`Title changed from "Before" to "After"`,
+	}, body[constant.RESPONSE].Summary)
+}
+
+func TestCompareReportsHandlerInvalidBody(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	for _, payload := range []string{`{"base":`, `{"base":{"title":"Only"}}`} {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodPost, COMPARE_PATH, strings.NewReader(payload))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.CompareReportsHandler(c)
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+	}
+}
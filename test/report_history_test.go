@@ -0,0 +1,218 @@
+package test
+
+import (
+	"api/app/handler"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"api/store"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const REPORTS_PATH = "/api/v1/reports"
+
+// useReportStore enables report history on a temporary directory for the test.
+func useReportStore(t *testing.T, retention time.Duration, maxReports int) *store.ReportStore {
+	reports, err := store.NewReportStore(t.TempDir(), retention, maxReports)
+	assert.NoError(t, err)
+	previous := store.SetReportStore(reports)
+	t.Cleanup(func() { store.SetReportStore(previous) })
+	return reports
+}
+
+func TestReportStoreSaveAndGet(t *testing.T) {
+	dir := t.TempDir()
+	reports, err := store.NewReportStore(dir, 0, 0)
+	assert.NoError(t, err)
+
+	saved, err := reports.Save("https://www.example.com/", map[string]string{"linkProbe": "true"},
+		&response.SuccessResponse{Title: "Example", HtmlVersion: "HTML 5"})
+	assert.NoError(t, err)
+	assert.Len(t, saved.Id, 24)
+	assert.Equal(t, "www.example.com", saved.Host)
+	assert.Equal(t, "Example", saved.Title)
+
+	loaded, err := reports.Get(saved.Id)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.example.com/", loaded.Url)
+	assert.Equal(t, "HTML 5", loaded.Result.HtmlVersion)
+	assert.Equal(t, map[string]string{"linkProbe": "true"}, loaded.Options)
+
+	_, err = reports.Get("../" + saved.Id)
+	assert.ErrorIs(t, err, store.ErrReportNotFound)
+
+	// a reopened store indexes the saved reports, and skips files that are not reports
+	os.WriteFile(filepath.Join(dir, "notes.json"), []byte(`{"id":"../../etc"}`), 0o644)
+	reopened, err := store.NewReportStore(dir, 0, 0)
+	assert.NoError(t, err)
+	summaries := reopened.List(store.ReportFilter{})
+	assert.Len(t, summaries, 1)
+	assert.Equal(t, saved.Id, summaries[0].Id)
+	assert.Equal(t, "Example", summaries[0].Title)
+}
+
+func TestReportStoreList(t *testing.T) {
+	reports, err := store.NewReportStore(t.TempDir(), 0, 0)
+	assert.NoError(t, err)
+	for _, link := range []string{"https://www.example.com", "https://WWW.example.com/#top", "https://shop.example.com/cart"} {
+		_, err := reports.Save(link, nil, &response.SuccessResponse{Title: link})
+		assert.NoError(t, err)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	all := reports.List(store.ReportFilter{})
+	assert.Len(t, all, 3)
+	assert.Equal(t, "https://shop.example.com/cart", all[0].Url, "newest first")
+
+	assert.Len(t, reports.List(store.ReportFilter{Url: "https://www.example.com/"}), 2)
+	assert.Len(t, reports.List(store.ReportFilter{Host: "SHOP.example.com"}), 1)
+	assert.Len(t, reports.List(store.ReportFilter{Limit: 2}), 2)
+	assert.Len(t, reports.List(store.ReportFilter{From: time.Now().Add(time.Hour)}), 0)
+	assert.Len(t, reports.List(store.ReportFilter{To: time.Now().Add(-time.Hour)}), 0)
+	assert.Len(t, reports.List(store.ReportFilter{From: all[1].CreatedAt, To: all[0].CreatedAt}), 1)
+}
+
+func TestReportStoreRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := response.StoredReport{ReportSummary: response.ReportSummary{
+		Id: "0123456789abcdef01234567", Url: "https://www.example.com/", CreatedAt: time.Now().AddDate(0, 0, -40),
+	}}
+	data, _ := json.Marshal(old)
+	os.WriteFile(filepath.Join(dir, old.Id+".json"), data, 0o644)
+
+	reports, err := store.NewReportStore(dir, 30*24*time.Hour, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, reports.List(store.ReportFilter{}), "reports past the retention period are deleted")
+	assert.NoFileExists(t, filepath.Join(dir, old.Id+".json"))
+
+	var ids []string
+	for i := range 3 {
+		saved, err := reports.Save(fmt.Sprintf("https://www.example.com/%d", i), nil, &response.SuccessResponse{})
+		assert.NoError(t, err)
+		ids = append(ids, saved.Id)
+		time.Sleep(2 * time.Millisecond)
+	}
+	summaries := reports.List(store.ReportFilter{})
+	assert.Len(t, summaries, 2, "the oldest reports beyond the count are deleted")
+	assert.Equal(t, ids[2], summaries[0].Id)
+	assert.Equal(t, ids[1], summaries[1].Id)
+	_, err = reports.Get(ids[0])
+	assert.ErrorIs(t, err, store.ErrReportNotFound)
+}
+
+func reportRequest(t *testing.T, handlerFunc gin.HandlerFunc, target string, params gin.Params) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, target, nil)
+	c.Params = params
+	handlerFunc(c)
+	return w
+}
+
+func TestReportHistoryHandlers(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useReportStore(t, 0, 0)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v1":
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Release 1</title></head><body><h1>Home</h1></body></html>`)
+		case "/v2":
+			fmt.Fprint(w, `<!DOCTYPE html><html><head><title>Release 2</title></head><body></body></html>`)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	// every analysis is saved and its report id returned
+	var ids []string
+	for _, path := range []string{"/v1", "/v2"} {
+		w := reportRequest(t, handler.WebPageExecutorHandler, PATH+url.QueryEscape(server.URL+path), nil)
+		assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+		var body map[string]response.SuccessResponse
+		json.Unmarshal(w.Body.Bytes(), &body)
+		assert.NotEmpty(t, body[constant.RESPONSE].ReportId)
+		ids = append(ids, body[constant.RESPONSE].ReportId)
+		time.Sleep(2 * time.Millisecond)
+	}
+
+	w := reportRequest(t, handler.ReportListHandler, REPORTS_PATH+"?url="+url.QueryEscape(server.URL+"/v1"), nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var list map[string]response.ReportHistory
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, 1, list[constant.RESPONSE].Count)
+	assert.Equal(t, ids[0], list[constant.RESPONSE].Reports[0].Id)
+	assert.Equal(t, "Release 1", list[constant.RESPONSE].Reports[0].Title)
+	assert.Equal(t, "true", list[constant.RESPONSE].Reports[0].Options["linkProbe"])
+
+	today := time.Now().UTC().Format("2006-01-02")
+	w = reportRequest(t, handler.ReportListHandler, REPORTS_PATH+"?host=127.0.0.1&from="+today+"&to="+today, nil)
+	json.Unmarshal(w.Body.Bytes(), &list)
+	assert.Equal(t, 2, list[constant.RESPONSE].Count)
+	assert.Equal(t, ids[1], list[constant.RESPONSE].Reports[0].Id)
+
+	w = reportRequest(t, handler.ReportHandler, REPORTS_PATH+"/"+ids[1], gin.Params{{Key: constant.ID, Value: ids[1]}})
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var report map[string]response.StoredReport
+	json.Unmarshal(w.Body.Bytes(), &report)
+	assert.Equal(t, server.URL+"/v2", report[constant.RESPONSE].Url)
+	assert.Equal(t, "Release 2", report[constant.RESPONSE].Result.Title)
+
+	// saved reports compare by id
+	w = reportRequest(t, handler.CompareHandler, COMPARE_PATH+"?baseId="+ids[0]+"&targetId="+ids[1], nil)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	var comparison map[string]response.ComparisonReport
+	json.Unmarshal(w.Body.Bytes(), &comparison)
+	assert.Contains(t, comparison[constant.RESPONSE].Summary, `Title changed from "Release 1" to "Release 2"`)
+	assert.Contains(t, comparison[constant.RESPONSE].Summary, `Lost h1 "Home"`)
+}
+
+func TestReportHandlerNotFound(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useReportStore(t, 0, 0)
+
+	w := reportRequest(t, handler.ReportHandler, REPORTS_PATH+"/missing", gin.Params{{Key: constant.ID, Value: "missing"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Report not found")
+
+	w = reportRequest(t, handler.CompareHandler, COMPARE_PATH+"?baseId=missing&target=https://www.example.com", nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestReportListHandlerInvalidFilter(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	useReportStore(t, 0, 0)
+
+	for _, query := range []string{"?from=yesterday", "?to=2024-13-01", "?limit=0", "?limit=many"} {
+		w := reportRequest(t, handler.ReportListHandler, REPORTS_PATH+query, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestReportHistoryDisabled(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	previous := store.SetReportStore(nil)
+	defer store.SetReportStore(previous)
+
+	w := reportRequest(t, handler.ReportListHandler, REPORTS_PATH, nil)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Body.String(), "Report history is not enabled")
+
+	w = reportRequest(t, handler.ReportHandler, REPORTS_PATH+"/any", gin.Params{{Key: constant.ID, Value: "any"}})
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
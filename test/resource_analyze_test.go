@@ -0,0 +1,100 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/response"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestResourceAnalyzer_Analyze_InventoryAndTransferSize(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/app.js":
+			w.Write([]byte(strings.Repeat("a", 1200)))
+		case "/site.css":
+			w.Write([]byte(strings.Repeat("b", 300)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	// localhost and 127.0.0.1 are different sites, so the localhost resources are third-party
+	thirdParty := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+	htmlContent := `<html><head>
+		<link rel="stylesheet" href="/site.css">
+		<link rel="stylesheet" href="/print.css" media="print">
+		<style>body { margin: 0 }</style>
+		<script src="/app.js"></script>
+		<script src="` + thirdParty + `/sdk.js" async></script>
+		<script type="application/ld+json">{}</script>
+	</head><body>
+		<script>window.ready = true</script>
+		<script type="module" src="/main.mjs"></script>
+	</body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: server.URL + "/"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewResourceAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	report := res.Resources
+	assert.Len(t, report.Scripts, 4)
+	assert.Len(t, report.Stylesheets, 3)
+
+	appScript := report.Scripts[0]
+	assert.True(t, appScript.InHead)
+	assert.True(t, appScript.RenderBlocking)
+	assert.Equal(t, int64(1200), appScript.TransferSize)
+	assert.True(t, report.Scripts[1].Async)
+	assert.False(t, report.Scripts[1].RenderBlocking)
+	assert.True(t, report.Scripts[1].ThirdParty)
+	assert.True(t, report.Scripts[2].Inline)
+	assert.Equal(t, len("window.ready = true"), report.Scripts[2].InlineSize)
+	assert.True(t, report.Scripts[3].Module)
+
+	assert.True(t, report.Stylesheets[0].RenderBlocking)
+	assert.False(t, report.Stylesheets[1].RenderBlocking)
+	assert.True(t, report.Stylesheets[2].Inline)
+	assert.Equal(t, 2, report.RenderBlocking)
+	assert.Equal(t, int64(1500+19*3), report.TotalBytes)
+
+	assert.Len(t, report.ThirdParties, 1)
+	assert.Equal(t, "localhost", report.ThirdParties[0].Host)
+	assert.Equal(t, 1, report.ThirdParties[0].Scripts)
+	assert.True(t, hasRule(report.Findings, "script-missing-sri"))
+	assert.True(t, hasRule(report.Findings, "script-render-blocking"))
+	assert.True(t, hasRule(report.Findings, "resource-broken"))
+}
+
+func TestSummarizeResources_SubresourceIntegrity(t *testing.T) {
+	htmlContent := `<html><head>
+		<script src="https://cdn.example.net/lib.js" integrity="sha384-abc" crossorigin></script>
+		<script src="https://cdn.example.net/other.js" integrity="sha384-def"></script>
+		<link rel="stylesheet" href="https://fonts.example.org/font.css">
+	</head></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+	report := analyze.ExtractResources(doc, "https://www.example.com/")
+
+	analyze.SummarizeResources(report, "https://www.example.com/")
+
+	assert.Equal(t, "anonymous", report.Scripts[0].CrossOrigin)
+	assert.False(t, hasRule(report.Findings, "script-missing-sri"))
+	assert.True(t, hasRule(report.Findings, "sri-missing-crossorigin"))
+	assert.True(t, hasRule(report.Findings, "stylesheet-missing-sri"))
+	assert.Len(t, report.ThirdParties, 2)
+	assert.Equal(t, "cdn.example.net", report.ThirdParties[0].Host)
+	assert.Equal(t, 2, report.ThirdParties[0].Scripts)
+}
@@ -0,0 +1,287 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+const robotsFile = `# comments and unknown lines are skipped
+User-agent: OtherBot
+Disallow: /
+
+User-agent: *
+Allow: /private/public
+Disallow: /private
+Disallow: /*.pdf$
+Disallow: /search?
+Crawl-delay: 2
+
+User-agent: WebPageAnalyzer/2.0
+User-agent: SecondBot
+Disallow: /drafts/
+Allow: /drafts/published
+Disallow: /shared
+Allow: /shared
+
+Sitemap: https://example.com/sitemap.xml
+`
+
+func TestRobotsTxt_Allowed(t *testing.T) {
+	robots := analyze.ParseRobots(robotsFile)
+	tests := []struct {
+		agent   string
+		link    string
+		allowed bool
+		rule    string
+	}{
+		{"SomeBrowser/1.0", "https://example.com/", true, ""},
+		{"SomeBrowser/1.0", "https://example.com/private/page", false, "Disallow: /private"},
+		{"SomeBrowser/1.0", "https://example.com/private/public/page", true, "Allow: /private/public"},
+		{"SomeBrowser/1.0", "https://example.com/files/report.pdf", false, "Disallow: /*.pdf$"},
+		{"SomeBrowser/1.0", "https://example.com/files/report.pdf?download=1", true, ""},
+		{"SomeBrowser/1.0", "https://example.com/search?q=go", false, "Disallow: /search?"},
+		{"SomeBrowser/1.0", "https://example.com/search", true, ""},
+		{"otherbot", "https://example.com/anything", false, "Disallow: /"},
+		{"OtherBot", "https://example.com/robots.txt", true, ""},
+		{"WebPageAnalyzer/1.0", "https://example.com/private/page", true, ""},
+		{"WebPageAnalyzer/1.0", "https://example.com/drafts/new", false, "Disallow: /drafts/"},
+		{"WebPageAnalyzer/1.0", "https://example.com/drafts/published/post", true, "Allow: /drafts/published"},
+		{"SecondBot", "https://example.com/shared", true, "Allow: /shared"},
+	}
+	for _, test := range tests {
+		allowed, rule := robots.Allowed(test.agent, test.link)
+		assert.Equal(t, test.allowed, allowed, test.agent+" "+test.link)
+		assert.Equal(t, test.rule, rule, test.agent+" "+test.link)
+	}
+	assert.Equal(t, []string{"https://example.com/sitemap.xml"}, robots.Sitemaps)
+	assert.Equal(t, 2.0, robots.CrawlDelay("SomeBrowser"))
+	assert.Equal(t, 0.0, robots.CrawlDelay("SecondBot"))
+}
+
+func TestRobotsAnalyzer_Analyze_Sitemaps(t *testing.T) {
+	var robotsRequests atomic.Int32
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			robotsRequests.Add(1)
+			fmt.Fprintf(w, "User-agent: *\nDisallow: /admin\nSitemap: %s/sitemap_index.xml\nSitemap: %s/missing.xml\n",
+				server.URL, server.URL)
+		case "/sitemap_index.xml":
+			fmt.Fprintf(w, `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<sitemap><loc>%s/posts.xml</loc></sitemap><sitemap><loc>%s/pages.xml.gz</loc></sitemap></sitemapindex>`,
+				server.URL, server.URL)
+		case "/posts.xml":
+			fmt.Fprintf(w, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>%s/posts/1</loc></url><url><loc>%s/posts/2</loc></url></urlset>`, server.URL, server.URL)
+		case "/pages.xml.gz":
+			var compressed bytes.Buffer
+			writer := gzip.NewWriter(&compressed)
+			fmt.Fprintf(writer, `<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+<url><loc>%s/about#team</loc></url></urlset>`, server.URL)
+			writer.Close()
+			w.Write(compressed.Bytes())
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	wc := &response.WebContent{Content: "<html></html>", FinalUrl: server.URL + "/about"}
+	res := &response.SuccessResponse{}
+	err := analyze.NewRobotsAnalyzer().Analyze(wc, res)
+	analyze.NewRobotsAnalyzer().Analyze(wc, &response.SuccessResponse{})
+
+	assert.Nil(t, err)
+	assert.Equal(t, int32(1), robotsRequests.Load())
+	report := res.Robots
+	assert.True(t, report.Found)
+	assert.True(t, report.Honored)
+	assert.Equal(t, "WebPageAnalyzer/1.0", report.UserAgent)
+	assert.False(t, report.Disallowed)
+	assert.True(t, report.PageInSitemap)
+	assert.Len(t, report.Sitemaps, 2)
+
+	index := report.Sitemaps[0]
+	assert.True(t, index.Valid)
+	assert.Equal(t, constant.SITEMAP_INDEX, index.Type)
+	assert.Equal(t, 3, index.Urls)
+	assert.Len(t, index.Sitemaps, 2)
+	assert.False(t, index.Sitemaps[0].ListsPage)
+	assert.True(t, index.Sitemaps[1].ListsPage)
+	assert.Equal(t, constant.SITEMAP_URLSET, index.Sitemaps[1].Type)
+
+	assert.Equal(t, http.StatusNotFound, report.Sitemaps[1].Status)
+	assert.True(t, hasRule(report.Findings, "sitemap-broken"))
+	assert.False(t, hasRule(report.Findings, "page-not-in-sitemap"))
+	assert.False(t, hasRule(report.Findings, "robots-disallowed"))
+}
+
+func TestRobotsAnalyzer_Analyze_DisallowedPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "User-agent: webpageanalyzer\nDisallow: /admin\n")
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	wc := &response.WebContent{Content: "<html></html>", FinalUrl: server.URL + "/admin/users"}
+	res := &response.SuccessResponse{}
+	analyze.NewRobotsAnalyzer().Analyze(wc, res)
+
+	assert.True(t, res.Robots.Disallowed)
+	assert.Equal(t, "Disallow: /admin", res.Robots.MatchedRule)
+	assert.True(t, hasRule(res.Robots.Findings, "robots-disallowed"))
+	assert.True(t, hasRule(res.Robots.Findings, "sitemap-not-declared"))
+}
+
+func TestRobotsAnalyzer_Analyze_UnreachableRobots(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "unavailable", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	wc := &response.WebContent{Content: "<html></html>", FinalUrl: server.URL + "/"}
+	res := &response.SuccessResponse{}
+	analyze.NewRobotsAnalyzer().Analyze(wc, res)
+
+	assert.False(t, res.Robots.Found)
+	assert.True(t, res.Robots.Disallowed)
+	assert.True(t, hasRule(res.Robots.Findings, "robots-unreachable"))
+	assert.False(t, hasRule(res.Robots.Findings, "sitemap-not-declared"))
+}
+
+func TestLoadRobots_RetriesUnreachable(t *testing.T) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if requests.Add(1) == 1 {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	// a failure is reused until the retry time has passed
+	allowed, _ := analyze.CrawlAllowed(server.URL + "/")
+	assert.False(t, allowed)
+	allowed, _ = analyze.CrawlAllowed(server.URL + "/")
+	assert.False(t, allowed)
+	assert.Equal(t, int32(1), requests.Load())
+
+	configs.GetConfig().RobotsRetry = 0
+	defer func() { configs.GetConfig().RobotsRetry = 5 * time.Minute }()
+	allowed, _ = analyze.CrawlAllowed(server.URL + "/")
+	assert.True(t, allowed)
+	allowed, _ = analyze.CrawlAllowed(server.URL + "/private")
+	assert.False(t, allowed)
+	assert.Equal(t, int32(2), requests.Load(), "a fetched file is cached")
+}
+
+func TestHtmlUrlLinkAnalyzer_Analyze_HonorsRobots(t *testing.T) {
+	var privateRequests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprint(w, "User-agent: *\nDisallow: /private\n")
+		case "/private":
+			privateRequests.Add(1)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	wc := &response.WebContent{Content: `<html><body><a href="/public">Public</a><a href="/private">Private</a></body></html>`}
+	res := &response.SuccessResponse{BasePath: server.URL}
+	analyze.NewHtmlUrlLinkAnalyzer().Analyze(wc, res)
+
+	assert.Len(t, res.Urls, 2)
+	for _, link := range res.Urls {
+		assert.Equal(t, link.Url == server.URL+"/private", link.Disallowed, link.Url)
+	}
+	assert.Equal(t, int32(0), privateRequests.Load())
+
+	configs.GetConfig().HonorRobots = false
+	defer func() { configs.GetConfig().HonorRobots = true }()
+	res = &response.SuccessResponse{BasePath: server.URL}
+	analyze.NewHtmlUrlLinkAnalyzer().Analyze(wc, res)
+
+	for _, link := range res.Urls {
+		assert.False(t, link.Disallowed, link.Url)
+	}
+	assert.Equal(t, int32(1), privateRequests.Load())
+}
+
+func TestAnalyzers_HonorRobotsForProbes(t *testing.T) {
+	var probes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			fmt.Fprint(w, "User-agent: *\nDisallow: /\n")
+			return
+		}
+		probes.Add(1)
+	}))
+	defer server.Close()
+	thirdPartyURL := strings.Replace(server.URL, "127.0.0.1", "localhost", 1)
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	htmlContent := fmt.Sprintf(`<html><head>
+		<meta property="og:image" content="%[1]s/share.png">
+		<link rel="stylesheet" href="%[1]s/style.css">
+		<link rel="alternate" type="application/rss+xml" href="%[1]s/feed.xml">
+		<link rel="manifest" href="%[1]s/manifest.json">
+		<script src="%[2]s/tracker.js"></script>
+	</head><body><img src="%[1]s/logo.png" alt="Logo"></body></html>`, server.URL, thirdPartyURL)
+	analyzers := []analyze.Analyzer{
+		analyze.NewImageAnalyzer(),
+		analyze.NewResourceAnalyzer(),
+		analyze.NewDiscoveryAnalyzer(),
+		analyze.NewCookieAnalyzer(),
+		analyze.NewSocialMetadataAnalyzer(),
+	}
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: server.URL + "/"}
+	res := &response.SuccessResponse{BasePath: server.URL}
+
+	for _, analyzer := range analyzers {
+		assert.Nil(t, analyzer.Analyze(wc, res))
+	}
+
+	assert.Equal(t, int32(0), probes.Load(), "no image, resource, feed or cookie probe is sent")
+	assert.Len(t, res.Discovery.Feeds, 1)
+	assert.Equal(t, "disallowed by robots.txt", res.Discovery.Feeds[0].Error)
+	assert.False(t, hasRule(res.Social.Findings, "share-image-unreachable"))
+}
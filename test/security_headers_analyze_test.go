@@ -0,0 +1,89 @@
+package test
+
+import (
+	"api/analyze"
+	"api/constant"
+	"api/response"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func findHeader(headers []response.HeaderFinding, name string) *response.HeaderFinding {
+	for i := range headers {
+		if headers[i].Header == name {
+			return &headers[i]
+		}
+	}
+	return nil
+}
+
+func TestSecurityHeadersAnalyzer_Analyze_StrongHeaders(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Strict-Transport-Security", "max-age=31536000; includeSubDomains")
+	headers.Set("Content-Security-Policy", "default-src 'self'; frame-ancestors 'none'")
+	headers.Set("X-Content-Type-Options", "nosniff")
+	headers.Set("Referrer-Policy", "strict-origin-when-cross-origin")
+	headers.Set("Permissions-Policy", "camera=()")
+	headers.Set("Cross-Origin-Opener-Policy", "same-origin")
+	headers.Set("Cross-Origin-Embedder-Policy", "require-corp")
+	headers.Set("Cross-Origin-Resource-Policy", "same-origin")
+
+	wc := &response.WebContent{FinalUrl: "https://example.com", Headers: headers}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewSecurityHeadersAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "A", res.SecurityHeaders.Grade)
+	assert.Equal(t, res.SecurityHeaders.MaxScore, res.SecurityHeaders.Score)
+	frame := findHeader(res.SecurityHeaders.Headers, "X-Frame-Options")
+	assert.NotNil(t, frame)
+	assert.Equal(t, frame.MaxScore, frame.Score, "frame-ancestors should satisfy framing protection")
+}
+
+func TestSecurityHeadersAnalyzer_Analyze_NoHeaders(t *testing.T) {
+	wc := &response.WebContent{FinalUrl: "https://example.com"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewSecurityHeadersAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "F", res.SecurityHeaders.Grade)
+	assert.Equal(t, 0, res.SecurityHeaders.Score)
+	assert.Nil(t, res.SecurityHeaders.Csp)
+	hsts := findHeader(res.SecurityHeaders.Headers, "Strict-Transport-Security")
+	assert.False(t, hsts.Present)
+	assert.Equal(t, constant.SEVERITY_ERROR, hsts.Findings[0].Severity)
+}
+
+func TestGradeSecurityHeaders_WeakCsp(t *testing.T) {
+	headers := http.Header{}
+	headers.Set("Content-Security-Policy", "default-src * 'unsafe-inline' 'unsafe-eval'; img-src https:")
+	headers.Set("Strict-Transport-Security", "max-age=3600")
+
+	result := analyze.GradeSecurityHeaders(headers, true)
+
+	assert.True(t, result.Csp.UnsafeInline)
+	assert.True(t, result.Csp.UnsafeEval)
+	assert.ElementsMatch(t, []string{"default-src *", "img-src https:"}, result.Csp.WildcardSources)
+	csp := findHeader(result.Headers, "Content-Security-Policy")
+	assert.Equal(t, 0, csp.Score)
+	assert.Len(t, csp.Findings, 3)
+	hsts := findHeader(result.Headers, "Strict-Transport-Security")
+	assert.Equal(t, hsts.MaxScore/2, hsts.Score)
+}
+
+func TestParseContentSecurityPolicy(t *testing.T) {
+	directives := analyze.ParseContentSecurityPolicy("default-src 'self'; script-src 'self' cdn.example.com;; upgrade-insecure-requests")
+
+	assert.Len(t, directives, 3)
+	assert.Equal(t, "default-src", directives[0].Name)
+	assert.Equal(t, []string{"'self'"}, directives[0].Sources)
+	assert.Equal(t, []string{"'self'", "cdn.example.com"}, directives[1].Sources)
+	assert.Equal(t, "upgrade-insecure-requests", directives[2].Name)
+	assert.Empty(t, directives[2].Sources)
+}
@@ -0,0 +1,159 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/constant"
+	"api/response"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestSeoMetadataAnalyzer_Analyze_WellFormedPage(t *testing.T) {
+	htmlContent := `<html><head>
+		<title>Handmade leather bags and wallets | Example Shop</title>
+		<meta name="description" content="Browse our collection of handmade leather bags, wallets and belts, crafted in small batches and shipped worldwide.">
+		<meta name="robots" content="index, follow">
+		<meta name="viewport" content="width=device-width, initial-scale=1">
+		<link rel="canonical" href="https://EXAMPLE.com/bags#top">
+		<link rel="alternate" hreflang="en" href="https://example.com/bags">
+		<link rel="alternate" hreflang="x-default" href="https://example.com/bags">
+		<link rel="next" href="/bags?page=2">
+	</head><body></body></html>`
+	configs.GetConfig().LinkProbe = false
+	defer func() { configs.GetConfig().LinkProbe = true }()
+
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://example.com/bags"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewSeoMetadataAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Handmade leather bags and wallets | Example Shop", res.Seo.Title)
+	assert.Equal(t, 48, res.Seo.TitleLength)
+	assert.Greater(t, res.Seo.TitlePixelWidth, 0)
+	assert.Equal(t, []string{"index", "follow"}, res.Seo.Robots)
+	assert.True(t, res.Seo.Indexable)
+	assert.True(t, res.Seo.Followable)
+	assert.Equal(t, constant.CANONICAL_SELF, res.Seo.CanonicalStatus)
+	assert.Len(t, res.Seo.Hreflang, 2)
+	assert.Equal(t, "https://example.com/bags?page=2", res.Seo.Next)
+	assert.Empty(t, res.Seo.Findings)
+}
+
+func TestExtractSeoMetadata_Issues(t *testing.T) {
+	htmlContent := `<html><head>
+		<title>Home</title>
+		<meta name="googlebot" content="noindex">
+		<meta name="viewport" content="width=1024, user-scalable=no">
+		<link rel="canonical" href="https://example.com/a">
+		<link rel="alternate" hreflang="english" href="https://example.com/en">
+	</head><body></body></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+	headers := http.Header{}
+	headers.Set("X-Robots-Tag", "nofollow")
+	headers.Set("Link", `<https://other.example.org/a>; rel="canonical"`)
+
+	seo := analyze.ExtractSeoMetadata(doc, headers, "https://example.com/a")
+
+	assert.False(t, seo.Indexable)
+	assert.False(t, seo.Followable)
+	assert.Equal(t, []string{"nofollow"}, seo.XRobotsTag)
+	assert.Equal(t, constant.CANONICAL_CONFLICTING, seo.CanonicalStatus)
+
+	rules := make(map[string]string)
+	for _, finding := range seo.Findings {
+		rules[finding.Rule] = finding.Severity
+	}
+	assert.Equal(t, constant.SEVERITY_WARNING, rules["seo-title-short"])
+	assert.Equal(t, constant.SEVERITY_ERROR, rules["seo-description-missing"])
+	assert.Equal(t, constant.SEVERITY_WARNING, rules["seo-noindex"])
+	assert.Equal(t, constant.SEVERITY_ERROR, rules["seo-canonical-conflicting"])
+	assert.Equal(t, constant.SEVERITY_WARNING, rules["seo-hreflang-invalid"])
+	assert.Equal(t, constant.SEVERITY_WARNING, rules["seo-hreflang-no-self"])
+	assert.Equal(t, constant.SEVERITY_WARNING, rules["seo-viewport-width"])
+	assert.Equal(t, constant.SEVERITY_INFO, rules["seo-viewport-zoom"])
+}
+
+func TestExtractSeoMetadata_CanonicalTrailingSlash(t *testing.T) {
+	doc, _ := html.Parse(strings.NewReader(`<html><head><link rel="canonical" href="https://example.com/bags/"></head></html>`))
+
+	seo := analyze.ExtractSeoMetadata(doc, http.Header{}, "https://example.com/bags")
+
+	// pages are compared the way sitemap entries and crawled pages are, a trailing slash makes another page
+	assert.Equal(t, constant.CANONICAL_SAME_DOMAIN, seo.CanonicalStatus)
+	assert.NotEqual(t, analyze.NormalizePageURL("https://example.com/bags/"), analyze.NormalizePageURL("https://example.com/bags"))
+}
+
+func TestSeoMetadataAnalyzer_Analyze_HreflangReciprocity(t *testing.T) {
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/de":
+			fmt.Fprintf(w, `<html><head><link rel="alternate" hreflang="en" href="%s/en"></head></html>`, server.URL)
+		case "/it":
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `<html><head><title>Not found</title></head></html>`)
+		case "/es":
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{}`)
+		default:
+			fmt.Fprint(w, `<html><head></head></html>`)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	htmlContent := fmt.Sprintf(`<html><head>
+		<link rel="alternate" hreflang="en" href="%[1]s/en">
+		<link rel="alternate" hreflang="de" href="%[1]s/de">
+		<link rel="alternate" hreflang="fr" href="%[1]s/fr">
+		<link rel="alternate" hreflang="it" href="%[1]s/it">
+		<link rel="alternate" hreflang="es" href="%[1]s/es">
+	</head></html>`, server.URL)
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: server.URL + "/en"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewSeoMetadataAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Nil(t, res.Seo.Hreflang[0].Reciprocal)
+	assert.True(t, *res.Seo.Hreflang[1].Reciprocal)
+	assert.False(t, *res.Seo.Hreflang[2].Reciprocal)
+	assert.Nil(t, res.Seo.Hreflang[3].Reciprocal, "an error page is not checked")
+	assert.Nil(t, res.Seo.Hreflang[4].Reciprocal, "a page that is not HTML is not checked")
+	assert.True(t, hasRule(res.Seo.Findings, "seo-hreflang-not-reciprocal"))
+}
+
+func TestSeoMetadataAnalyzer_Analyze_HreflangDecodesCharset(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the link back is written in windows-1252, where 0xE9 is é
+		w.Header().Set("Content-Type", "text/html; charset=windows-1252")
+		fmt.Fprint(w, "<html><head><link rel=\"alternate\" hreflang=\"fr\" href=\"/caf\xe9\"></head></html>")
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	htmlContent := fmt.Sprintf(`<html><head><link rel="alternate" hreflang="de" href="%s/de"></head></html>`, server.URL)
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: server.URL + "/caf%C3%A9"}
+	res := &response.SuccessResponse{}
+
+	err := analyze.NewSeoMetadataAnalyzer().Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.True(t, *res.Seo.Hreflang[0].Reciprocal)
+}
@@ -0,0 +1,213 @@
+package test
+
+import (
+	"api/app/handler"
+	"api/configs"
+	"api/constant"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+const CRAWL_PATH = "/api/v1/crawl?url="
+
+// newCrawlSite serves a small site with duplicate titles, a redirect chain, a broken link, a disallowed page and a
+// sitemap listing an orphan page.
+func newCrawlSite() *httptest.Server {
+	var server *httptest.Server
+	page := func(title, description, body string) string {
+		return fmt.Sprintf(`<html><head><title>%s</title><meta name="description" content="%s"></head><body>%s</body></html>`,
+			title, description, body)
+	}
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/robots.txt":
+			fmt.Fprintf(w, "User-agent: *\nDisallow: /private\nSitemap: %s/sitemap.xml\n", server.URL)
+		case "/sitemap.xml":
+			fmt.Fprintf(w, `<urlset><url><loc>%[1]s/</loc></url><url><loc>%[1]s/a</loc></url><url><loc>%[1]s/orphan</loc></url></urlset>`,
+				server.URL)
+		case "/":
+			fmt.Fprint(w, page("Home", "Welcome", `<a href="/a">A</a><a href="/b#top">B</a><a href="/old">Old</a>
+				<a href="/missing">Missing</a><a href="/private">Private</a><a href="/docs/skip">Docs</a>
+				<a href="https://external.invalid/">External</a><img src="/logo.png">`))
+		case "/a":
+			fmt.Fprint(w, page("Same", "Shared", `<a href="/a/deep">Deep</a><a href="/">Home</a>`))
+		case "/b":
+			fmt.Fprint(w, page("Same", "Shared", `<a href="/a">A</a>`))
+		case "/orphan":
+			fmt.Fprint(w, page("Orphan", "Alone", ``))
+		case "/old":
+			http.Redirect(w, r, "/older", http.StatusMovedPermanently)
+		case "/older":
+			http.Redirect(w, r, "/c", http.StatusFound)
+		case "/c":
+			fmt.Fprint(w, page("C", "Target", ``))
+		case "/logo.png":
+			w.Header().Set("Content-Type", "image/png")
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	return server
+}
+
+func crawl(t *testing.T, query string) map[string]any {
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, CRAWL_PATH+query, nil)
+	handler.SiteCrawlHandler(c)
+	assert.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+	var body map[string]map[string]any
+	json.Unmarshal(w.Body.Bytes(), &body)
+	return body[constant.RESPONSE]
+}
+
+func TestSiteCrawlHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := newCrawlSite()
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	report := crawl(t, url.QueryEscape(server.URL)+"&maxDepth=1&exclude="+url.QueryEscape("/docs/"))
+
+	var pages []string
+	for _, page := range report["pages"].([]any) {
+		crawled := page.(map[string]any)
+		pages = append(pages, fmt.Sprintf("%s %v", crawled["url"], crawled["depth"]))
+	}
+	assert.Equal(t, []string{
+		server.URL + "/ 0",
+		server.URL + "/a 1",
+		server.URL + "/orphan 1",
+		server.URL + "/b 1",
+		server.URL + "/old 1",
+		server.URL + "/missing 1",
+	}, pages)
+	home := report["pages"].([]any)[0].(map[string]any)["result"].(map[string]any)
+	assert.Equal(t, float64(http.StatusOK), home["pageStatus"], "pages are analyzed the way the analyze endpoint does")
+	assert.Equal(t, false, report["truncated"])
+	assert.Equal(t, []any{server.URL + "/private"}, report["disallowed"])
+	assert.Equal(t, []any{server.URL + "/orphan"}, report["orphanPages"])
+
+	assert.Equal(t, []any{
+		map[string]any{"value": "Same", "urls": []any{server.URL + "/a", server.URL + "/b"}},
+	}, report["duplicateTitles"])
+	assert.Equal(t, []any{
+		map[string]any{"value": "Shared", "urls": []any{server.URL + "/a", server.URL + "/b"}},
+	}, report["duplicateDescriptions"])
+
+	// links past the crawl depth or excluded from it are still probed from the pages linking to them
+	assert.Equal(t, []any{
+		map[string]any{"url": server.URL + "/a/deep", "status": float64(404), "referrers": []any{server.URL + "/a"}},
+		map[string]any{"url": server.URL + "/docs/skip", "status": float64(404), "referrers": []any{server.URL + "/"}},
+		map[string]any{"url": server.URL + "/missing", "status": float64(404), "referrers": []any{server.URL + "/"}},
+	}, report["brokenLinks"])
+
+	assert.Equal(t, []any{map[string]any{
+		"url":      server.URL + "/old",
+		"finalUrl": server.URL + "/c",
+		"hops":     float64(2),
+		"chain":    []any{server.URL + "/old", server.URL + "/older", server.URL + "/c"},
+	}}, report["redirectChains"])
+
+	var rules []string
+	for _, finding := range report["findings"].([]any) {
+		rules = append(rules, finding.(map[string]any)["rule"].(string))
+	}
+	assert.Equal(t, []string{"duplicate-title", "duplicate-description", "orphan-pages", "broken-internal-link",
+		"broken-internal-link", "broken-internal-link", "redirect-chain"}, rules)
+}
+
+func TestSiteCrawlHandler_MaxPages(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	server := newCrawlSite()
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	report := crawl(t, url.QueryEscape(server.URL)+"&maxPages=2&sitemap=false&include="+url.QueryEscape("/(a|b)?$"))
+
+	assert.Equal(t, float64(2), report["pageCount"])
+	assert.Equal(t, true, report["truncated"])
+	pages := report["pages"].([]any)
+	assert.Equal(t, server.URL+"/a", pages[1].(map[string]any)["url"])
+	assert.Equal(t, server.URL+"/", pages[1].(map[string]any)["referrer"])
+	assert.Nil(t, report["orphanPages"])
+}
+
+func TestSiteCrawlHandler_ThrottlesProbes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var mu sync.Mutex
+	var requested []time.Time
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/robots.txt" {
+			http.NotFound(w, r)
+			return
+		}
+		mu.Lock()
+		requested = append(requested, time.Now())
+		mu.Unlock()
+		if r.URL.Path == "/" {
+			fmt.Fprint(w, `<html><head><title>Home</title></head><body><img src="/1.png"><img src="/2.png"><script src="/app.js"></script></body></html>`)
+			return
+		}
+		w.Header().Set("Content-Type", "image/png")
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+	delay := 30 * time.Millisecond
+	configs.GetConfig().CrawlDelay = delay
+	defer func() { configs.GetConfig().CrawlDelay = 0 }()
+
+	crawl(t, url.QueryEscape(server.URL)+"&maxDepth=0&sitemap=false")
+
+	// the probes of the analyzers wait for the politeness delay like the page request
+	mu.Lock()
+	defer mu.Unlock()
+	assert.Greater(t, len(requested), 3)
+	slices.SortFunc(requested, func(a, b time.Time) int { return a.Compare(b) })
+	span := requested[len(requested)-1].Sub(requested[0])
+	assert.GreaterOrEqual(t, span, time.Duration(len(requested)-2)*delay)
+}
+
+func TestSiteCrawlHandler_InvalidOptions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		query   string
+		message string
+	}{
+		{"&maxDepth=deep", "maxDepth must be a number from 0 to 10"},
+		{"&maxDepth=11", "maxDepth must be a number from 0 to 10"},
+		{"&maxPages=0", "maxPages must be a positive number"},
+		{"&sitemap=maybe", "sitemap must be true or false"},
+		{"&include=" + url.QueryEscape("(["), "Invalid include pattern"},
+	}
+	for _, test := range tests {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(http.MethodGet, CRAWL_PATH+"http://example.com"+test.query, nil)
+
+		handler.SiteCrawlHandler(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, test.query)
+		assert.Contains(t, w.Body.String(), test.message, test.query)
+	}
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/response"
+	"image"
+	"image/png"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestSocialMetadataAnalyzer_Analyze_PreviewWithImageProbe(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/share.png":
+			w.Header().Set("Content-Type", "image/png")
+			png.Encode(w, image.NewRGBA(image.Rect(0, 0, 1200, 630)))
+		case "/small.png":
+			w.Header().Set("Content-Type", "image/png")
+			png.Encode(w, image.NewRGBA(image.Rect(0, 0, 100, 80)))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	htmlContent := `<html><head>
+		<title>Fallback title</title>
+		<meta property="og:title" content="Spring collection">
+		<meta property="og:type" content="website">
+		<meta property="og:url" content="https://shop.example.com/spring">
+		<meta property="og:image" content="` + server.URL + `/share.png">
+		<meta property="og:description" content="New arrivals">
+		<meta name="twitter:card" content="summary_large_image">
+		<link rel="alternate" type="application/json+oembed" href="/oembed?format=json" title="Spring">
+	</head><body></body></html>`
+	wc := &response.WebContent{Content: htmlContent, FinalUrl: "https://shop.example.com/spring"}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewSocialMetadataAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "summary_large_image", res.Social.CardType)
+	assert.Equal(t, "website", res.Social.OpenGraph["og:type"])
+	assert.Len(t, res.Social.OEmbed, 1)
+	assert.Equal(t, "https://shop.example.com/oembed?format=json", res.Social.OEmbed[0].Url)
+	assert.Equal(t, http.StatusOK, res.Social.Image.Status)
+	assert.Equal(t, "image/png", res.Social.Image.ContentType)
+	assert.Equal(t, "png", res.Social.Image.Format)
+	assert.Equal(t, response.SharePreview{
+		Title:       "Spring collection",
+		Description: "New arrivals",
+		Image:       server.URL + "/share.png",
+		ImageWidth:  1200,
+		ImageHeight: 630,
+		SiteName:    "shop.example.com",
+		Url:         "https://shop.example.com/spring",
+		Domain:      "shop.example.com",
+		CardType:    "summary_large_image",
+	}, res.Social.Preview)
+	assert.False(t, hasRule(res.Social.Findings, "share-image-small"))
+
+	smallContent := strings.Replace(htmlContent, "/share.png", "/small.png", 1)
+	smallRes := &response.SuccessResponse{}
+	analyzer.Analyze(&response.WebContent{Content: smallContent, FinalUrl: wc.FinalUrl}, smallRes)
+	assert.Equal(t, 100, smallRes.Social.Image.Width)
+	assert.True(t, hasRule(smallRes.Social.Findings, "share-image-small"))
+	assert.True(t, hasRule(smallRes.Social.Findings, "share-image-large-card"))
+
+	missingContent := strings.Replace(htmlContent, "/share.png", "/missing.png", 1)
+	missingRes := &response.SuccessResponse{}
+	analyzer.Analyze(&response.WebContent{Content: missingContent, FinalUrl: wc.FinalUrl}, missingRes)
+	assert.Equal(t, http.StatusNotFound, missingRes.Social.Image.Status)
+	assert.True(t, hasRule(missingRes.Social.Findings, "share-image-unreachable"))
+}
+
+func TestExtractSocialMetadata_FallbacksAndValidation(t *testing.T) {
+	htmlContent := `<html><head>
+		<title>Page title</title>
+		<meta name="description" content="Meta description">
+		<link rel="canonical" href="/canonical">
+		<meta name="twitter:card" content="player">
+		<meta name="twitter:image" content="/card.jpg">
+	</head></html>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+
+	social := analyze.ExtractSocialMetadata(doc, "https://example.com/page")
+
+	assert.Equal(t, "Page title", social.Preview.Title)
+	assert.Equal(t, "Meta description", social.Preview.Description)
+	assert.Equal(t, "https://example.com/card.jpg", social.Preview.Image)
+	assert.Equal(t, "https://example.com/canonical", social.Preview.Url)
+	assert.True(t, hasRule(social.Findings, "og-missing"))
+
+	var missing []string
+	for _, finding := range social.Findings {
+		if finding.Rule == "twitter-missing-property" {
+			missing = append(missing, finding.Message)
+		}
+	}
+	assert.Equal(t, []string{
+		"twitter:title is required for the player card",
+		"twitter:site is required for the player card",
+		"twitter:player is required for the player card",
+		"twitter:player:width is required for the player card",
+		"twitter:player:height is required for the player card",
+	}, missing)
+}
@@ -0,0 +1,134 @@
+package test
+
+import (
+	"api/analyze"
+	"api/constant"
+	"api/response"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func TestStructuredDataAnalyzer_Analyze_JsonLdGraphAndArray(t *testing.T) {
+	htmlContent := `<html><head>
+		<script type="application/ld+json">
+		{"@context": "https://schema.org", "@graph": [
+			{"@type": "Organization", "name": "Example", "url": "https://example.com"},
+			{"@type": "BreadcrumbList", "itemListElement": [
+				{"@type": "ListItem", "position": 1, "name": "Home", "item": "https://example.com/"},
+				{"@type": "ListItem", "position": 2, "name": "Shoes"}
+			]}
+		]}
+		</script>
+		<script type="application/ld+json">
+		[{"@type": "http://schema.org/Product", "name": "Runner", "offers": {"@type": "Offer", "price": "99"}}]
+		</script>
+	</head><body></body></html>`
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewStructuredDataAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 3, res.StructuredData.EntityCount)
+	assert.Len(t, res.StructuredData.Entities["Organization"], 1)
+	assert.Len(t, res.StructuredData.Entities["BreadcrumbList"], 1)
+	assert.Len(t, res.StructuredData.Entities["Product"], 1)
+	assert.Equal(t, constant.SOURCE_JSON_LD, res.StructuredData.Entities["Product"][0].Source)
+	assert.Empty(t, res.StructuredData.JsonLdErrors)
+	assert.Empty(t, res.StructuredData.Findings)
+}
+
+func TestStructuredDataAnalyzer_Analyze_JsonLdSyntaxErrorPosition(t *testing.T) {
+	htmlContent := "<html><head>\n<script type=\"application/ld+json\">\n{\n  \"@type\": \"Article\",\n  \"headline\": \"Hi\" \"author\": \"Jo\"\n}\n</script>\n</head></html>"
+	wc := &response.WebContent{Content: htmlContent}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewStructuredDataAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, 0, res.StructuredData.EntityCount)
+	assert.Len(t, res.StructuredData.JsonLdErrors, 1)
+	jsonLdError := res.StructuredData.JsonLdErrors[0]
+	assert.Equal(t, 1, jsonLdError.Script)
+	assert.Equal(t, 5, jsonLdError.Line)
+	assert.Equal(t, 20, jsonLdError.Column)
+	assert.True(t, hasRule(res.StructuredData.Findings, "json-ld-syntax"))
+}
+
+func TestStructuredDataAnalyzer_Analyze_JsonLdDecodesCharset(t *testing.T) {
+	// windows-1252 bytes, 0xE9 is é
+	htmlContent := "<html><head><script type=\"application/ld+json\">{\"@type\": \"Organization\", \"name\": \"Caf\xe9 Central\"}</script></head></html>"
+	wc := &response.WebContent{Content: htmlContent, Headers: http.Header{"Content-Type": []string{"text/html; charset=windows-1252"}}}
+	res := &response.SuccessResponse{}
+
+	err := analyze.NewStructuredDataAnalyzer().Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.Equal(t, "Café Central", res.StructuredData.Entities["Organization"][0].Properties["name"])
+}
+
+func TestExtractMicrodata_NestedAndRepeatedProperties(t *testing.T) {
+	htmlContent := `<div itemscope itemtype="https://schema.org/Product">
+		<h1 itemprop="name">Runner  shoe</h1>
+		<img itemprop="image" src="/a.png"><img itemprop="image" src="/b.png">
+		<div itemprop="offers" itemscope itemtype="https://schema.org/Offer">
+			<meta itemprop="price" content="99">
+			<span itemprop="priceCurrency">EUR</span>
+		</div>
+	</div>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+
+	entities := analyze.ExtractMicrodata(doc)
+
+	assert.Len(t, entities, 1)
+	assert.Equal(t, "Product", entities[0].Type)
+	assert.Equal(t, "Runner shoe", entities[0].Properties["name"])
+	assert.Equal(t, []any{"/a.png", "/b.png"}, entities[0].Properties["image"])
+	offer := entities[0].Properties["offers"].(map[string]any)
+	assert.Equal(t, "99", offer["price"])
+	assert.Equal(t, "EUR", offer["priceCurrency"])
+	assert.NotContains(t, entities[0].Properties, "price")
+}
+
+func TestExtractRdfa_VocabAndTypeof(t *testing.T) {
+	htmlContent := `<div vocab="https://schema.org/" typeof="Organization">
+		<span property="name">Example</span>
+		<a property="url" href="https://example.com">Home</a>
+	</div>`
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+
+	entities := analyze.ExtractRdfa(doc)
+
+	assert.Len(t, entities, 1)
+	assert.Equal(t, "Organization", entities[0].Type)
+	assert.Equal(t, constant.SOURCE_RDFA, entities[0].Source)
+	assert.Equal(t, "https://example.com", entities[0].Properties["url"])
+}
+
+func TestValidateSchemaEntity_MissingProperties(t *testing.T) {
+	article := response.StructuredEntity{Type: "Article", Source: constant.SOURCE_JSON_LD,
+		Properties: map[string]any{"headline": "Hi", "image": ""}}
+	faq := response.StructuredEntity{Type: "FAQPage", Source: constant.SOURCE_JSON_LD,
+		Properties: map[string]any{"mainEntity": []any{
+			map[string]any{"@type": "Question", "name": "Why?"},
+		}}}
+	product := response.StructuredEntity{Type: "Product", Source: constant.SOURCE_MICRODATA,
+		Properties: map[string]any{"name": "Runner"}}
+
+	articleFindings := analyze.ValidateSchemaEntity(article)
+	faqFindings := analyze.ValidateSchemaEntity(faq)
+	productFindings := analyze.ValidateSchemaEntity(product)
+
+	assert.Len(t, articleFindings, 3)
+	assert.Contains(t, articleFindings[0].Message, "author")
+	assert.Len(t, faqFindings, 1)
+	assert.Contains(t, faqFindings[0].Message, "mainEntity[0].acceptedAnswer.text")
+	assert.Len(t, productFindings, 1)
+	assert.Contains(t, productFindings[0].Message, "offers or review or aggregateRating")
+}
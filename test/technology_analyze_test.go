@@ -0,0 +1,124 @@
+package test
+
+import (
+	"api/analyze"
+	"api/configs"
+	"api/response"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/net/html"
+)
+
+func findTechnology(report *response.TechnologyReport, name string) *response.Technology {
+	for i := range report.Technologies {
+		if report.Technologies[i].Name == name {
+			return &report.Technologies[i]
+		}
+	}
+	return nil
+}
+
+func TestTechnologyAnalyzer_Analyze_BundledSignatures(t *testing.T) {
+	htmlContent := `<html><head>
+		<meta name="generator" content="WordPress 6.4.2">
+		<script src="https://code.jquery.com/jquery-3.7.1.min.js"></script>
+		<script src="https://www.googletagmanager.com/gtm.js?id=GTM-XXXX"></script>
+		<script>window.dataLayer = []; gtag('js', new Date());</script>
+	</head><body></body></html>`
+	headers := http.Header{}
+	headers.Set("Server", "nginx/1.25.3")
+	headers.Set("CF-RAY", "8a1b2c3d4e5f-AMS")
+	headers.Add("Set-Cookie", "PHPSESSID=abc; Path=/")
+	wc := &response.WebContent{Content: htmlContent, Headers: headers}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewTechnologyAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	report := res.Technologies
+	wordpress := findTechnology(report, "WordPress")
+	if assert.NotNil(t, wordpress) {
+		assert.Equal(t, "6.4.2", wordpress.Version)
+		assert.Equal(t, []string{"CMS", "Blogs"}, wordpress.Categories)
+		assert.Contains(t, wordpress.Evidence, "meta generator")
+	}
+	jquery := findTechnology(report, "jQuery")
+	if assert.NotNil(t, jquery) {
+		assert.Equal(t, "3.7.1", jquery.Version)
+	}
+	nginx := findTechnology(report, "Nginx")
+	if assert.NotNil(t, nginx) {
+		assert.Equal(t, "1.25.3", nginx.Version)
+	}
+	php := findTechnology(report, "PHP")
+	if assert.NotNil(t, php) {
+		assert.Contains(t, php.Evidence, "cookie PHPSESSID")
+		assert.Contains(t, php.Evidence, "implied by WordPress")
+	}
+	assert.NotNil(t, findTechnology(report, "MySQL"))
+	assert.NotNil(t, findTechnology(report, "Google Tag Manager"))
+	assert.NotNil(t, findTechnology(report, "Google Analytics"))
+	assert.Contains(t, report.ByCategory["CDN"], "Cloudflare")
+	assert.Nil(t, findTechnology(report, "Drupal"))
+}
+
+func TestDetectTechnologies_CustomSignatures(t *testing.T) {
+	signatures, err := analyze.ParseTechnologySignatures([]byte(`{
+		"categories": {"100": {"name": "Internal"}},
+		"technologies": {
+			"Acme Portal": {
+				"cats": [100],
+				"headers": {"X-Acme-Build": "^build-(\\d+)\\;version:\\1"},
+				"html": "<div id=\"acme-root\"\\;confidence:50",
+				"js": {"AcmeConfig": ""},
+				"implies": "Acme Runtime\\;confidence:50"
+			},
+			"Acme Runtime": {"cats": [100]},
+			"Legacy Portal": {"cats": [100], "js": {"AcmeConfig": ""}, "excludes": "Acme Portal"},
+			"Broken": {"cats": [100], "html": "(?<=lookbehind)"}
+		}
+	}`))
+	assert.Nil(t, err)
+
+	htmlContent := `<div id="acme-root"></div><script>var AcmeConfig = {};</script>`
+	headers := http.Header{}
+	headers.Set("X-Acme-Build", "build-42")
+	doc, _ := html.Parse(strings.NewReader(htmlContent))
+
+	report := analyze.DetectTechnologies(&response.WebContent{Content: htmlContent, Headers: headers}, doc, signatures)
+
+	assert.Nil(t, findTechnology(report, "Acme Portal"))
+	assert.NotNil(t, findTechnology(report, "Legacy Portal"))
+	runtime := findTechnology(report, "Acme Runtime")
+	if assert.NotNil(t, runtime) {
+		assert.Equal(t, 100, runtime.Confidence)
+	}
+	assert.Nil(t, findTechnology(report, "Broken"))
+}
+
+func TestTechnologyAnalyzer_Analyze_SignatureFileFromConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "signatures.json")
+	os.WriteFile(path, []byte(`{"categories": {"1": {"name": "CMS"}},
+		"technologies": {"InHouse CMS": {"cats": [1], "meta": {"generator": "^InHouse ([\\d.]+)\\;version:\\1"}}}}`), 0o600)
+	configs.GetConfig().TechSignatures = path
+	defer func() { configs.GetConfig().TechSignatures = "" }()
+
+	wc := &response.WebContent{Content: `<meta name="Generator" content="InHouse 2.1"><script src="/js/jquery.min.js"></script>`}
+	res := &response.SuccessResponse{}
+	analyzer := analyze.NewTechnologyAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	inHouse := findTechnology(res.Technologies, "InHouse CMS")
+	if assert.NotNil(t, inHouse) {
+		assert.Equal(t, "2.1", inHouse.Version)
+	}
+	assert.NotNil(t, findTechnology(res.Technologies, "jQuery"))
+}
@@ -0,0 +1,119 @@
+package test
+
+import (
+	"api/analyze"
+	"api/app/handler"
+	"api/configs"
+	"api/response"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTlsSecurityAnalyzer_Analyze_TLSServer(t *testing.T) {
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "<html><body>secure</body></html>")
+	}))
+	defer server.Close()
+
+	originalClient := configs.GetConfig().Client
+	configs.GetConfig().Client = server.Client()
+	defer func() { configs.GetConfig().Client = originalClient }()
+
+	resp, err := server.Client().Get(server.URL)
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	wc := handler.BuildWebContent(body, resp)
+	res := &response.SuccessResponse{ExecutedUrl: server.URL}
+	analyzer := analyze.NewTlsSecurityAnalyzer()
+
+	analysisErr := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, analysisErr)
+	assert.NotNil(t, res.Tls)
+	assert.True(t, res.Tls.Enabled)
+	assert.Equal(t, "127.0.0.1", res.Tls.Host)
+	assert.NotEmpty(t, res.Tls.Version)
+	assert.NotEmpty(t, res.Tls.CipherSuite)
+	assert.Contains(t, res.Tls.SANs, "example.com")
+	assert.Contains(t, res.Tls.SANs, "127.0.0.1")
+	assert.NotEmpty(t, res.Tls.IssuerChain)
+	assert.True(t, res.Tls.HostnameMatch)
+	assert.False(t, res.Tls.Expired)
+	assert.Greater(t, res.Tls.DaysRemaining, 0)
+	assert.False(t, res.Tls.OcspStapled)
+	assert.Equal(t, "https", res.Tls.HttpsRedirect.RequestedScheme)
+	assert.False(t, res.Tls.HttpsRedirect.RedirectsToHttps)
+}
+
+func TestTlsSecurityAnalyzer_Analyze_PlainHttp(t *testing.T) {
+	wc := &response.WebContent{FinalUrl: "http://example.com/page"}
+	res := &response.SuccessResponse{ExecutedUrl: "http://example.com/page"}
+	analyzer := analyze.NewTlsSecurityAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.False(t, res.Tls.Enabled)
+	assert.Equal(t, "example.com", res.Tls.Host)
+	assert.False(t, res.Tls.HttpsRedirect.UpgradedToHttps)
+}
+
+func TestTlsSecurityAnalyzer_Analyze_UpgradedToHttps(t *testing.T) {
+	wc := &response.WebContent{FinalUrl: "https://example.com/page"}
+	res := &response.SuccessResponse{ExecutedUrl: "http://example.com/page"}
+	analyzer := analyze.NewTlsSecurityAnalyzer()
+
+	err := analyzer.Analyze(wc, res)
+
+	assert.Nil(t, err)
+	assert.True(t, res.Tls.HttpsRedirect.UpgradedToHttps)
+	assert.True(t, res.Tls.HttpsRedirect.RedirectsToHttps)
+}
+
+func TestExtractTlsInfo_NoConnectionState(t *testing.T) {
+	info := analyze.ExtractTlsInfo("example.com", nil, time.Now())
+
+	assert.False(t, info.Enabled)
+	assert.Equal(t, "example.com", info.Host)
+	assert.Empty(t, info.SANs)
+}
+
+func TestBuildWebContent_CollectsRedirects(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/start":
+			http.Redirect(w, r, "/middle", http.StatusMovedPermanently)
+		case "/middle":
+			http.Redirect(w, r, "/final", http.StatusFound)
+		default:
+			fmt.Fprint(w, "done")
+		}
+	}))
+	defer server.Close()
+
+	resp, err := server.Client().Get(server.URL + "/start")
+	assert.Nil(t, err)
+	body, _ := io.ReadAll(resp.Body)
+	resp.Body.Close()
+
+	wc := handler.BuildWebContent(body, resp)
+
+	assert.Equal(t, "done", wc.Content)
+	assert.Equal(t, server.URL+"/final", wc.FinalUrl)
+	assert.Len(t, wc.Redirects, 2)
+	if len(wc.Redirects) == 2 {
+		assert.Equal(t, server.URL+"/start", wc.Redirects[0].Url)
+		assert.Equal(t, http.StatusMovedPermanently, wc.Redirects[0].Status)
+		assert.Equal(t, "/middle", wc.Redirects[0].Location)
+		assert.Equal(t, server.URL+"/middle", wc.Redirects[1].Url)
+		assert.Equal(t, http.StatusFound, wc.Redirects[1].Status)
+	}
+}